@@ -0,0 +1,351 @@
+// Package changefeed polls databases and pages for changes,
+// since the API has no way to subscribe to them.
+package changefeed
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// SearchSource is the source of standalone pages, which are found via search.
+const SearchSource = "search"
+
+var _ Client = (*notion.Client)(nil)
+
+// Client is any client that can query databases and search pages.
+type Client interface {
+	// GetDatabaseEntries returns the filtered and sorted entries of a database.
+	GetDatabaseEntries(ctx context.Context, id notion.Id, filter *notion.Filter, sorts *notion.Sorts) (
+		notion.Pages, error)
+	// GetNotionPagesEditedSince returns all pages that were last edited at or after the time.
+	GetNotionPagesEditedSince(ctx context.Context, since time.Time) (notion.Pages, error)
+}
+
+// EventType defines the kind of change.
+type EventType string
+
+// Defines values for EventType.
+const (
+	EventCreated  EventType = "created"
+	EventUpdated  EventType = "updated"
+	EventArchived EventType = "archived"
+)
+
+// Event is a change of a page.
+type Event struct {
+	Type EventType
+	// Source is the ID of the database of the page or SearchSource for standalone pages.
+	Source string
+	// Page is the page after the change. Pages that disappeared only have their ID set.
+	Page notion.Page
+}
+
+// Options define what is polled and how.
+type Options struct {
+	// Databases are the databases whose entries are polled.
+	Databases []notion.Id
+
+	// Pages polls standalone pages, i.e. pages that are not entries of a database.
+	Pages bool
+
+	// Interval is the time between polls. Defaults to a minute.
+	Interval time.Duration
+
+	// MaxBackoff is the longest time to wait after failed polls. Defaults to ten times the interval.
+	MaxBackoff time.Duration
+
+	// Store keeps the high-water marks. Defaults to a memory store.
+	Store Store
+
+	// Since is the time from which changes are reported for sources without a high-water mark.
+	// Defaults to the minute the feed is created, as edit times only have a precision of minutes.
+	Since time.Time
+
+	// FullScanEvery lists all entries of the databases every so many polls to find entries
+	// that disappeared, since the API does not return archived entries.
+	// Entries are only known to disappear if they were seen by an earlier scan or event.
+	// Zero disables full scans.
+	FullScanEvery int
+
+	// OnError is called with the errors of failed polls, which are retried after a backoff.
+	OnError func(error)
+}
+
+// Feed polls for changes and emits events.
+type Feed struct {
+	cli    Client
+	opts   Options
+	events chan Event
+
+	// seen are the edit times of the pages that were already emitted, by source
+	seen map[string]map[notion.UUID]time.Time
+	// known are the IDs of the entries of the databases, by source
+	known map[string]map[notion.UUID]bool
+	polls int
+}
+
+// New returns a new change feed.
+func New(cli Client, opts Options) *Feed {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 10 * opts.Interval
+	}
+
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore()
+	}
+
+	if opts.Since.IsZero() {
+		opts.Since = time.Now().Truncate(time.Minute)
+	}
+
+	if opts.OnError == nil {
+		opts.OnError = func(error) {}
+	}
+
+	return &Feed{
+		cli:    cli,
+		opts:   opts,
+		events: make(chan Event),
+		seen:   map[string]map[notion.UUID]time.Time{},
+		known:  map[string]map[notion.UUID]bool{},
+	}
+}
+
+// Events returns the channel of events. It is closed when Run returns.
+func (f *Feed) Events() <-chan Event { return f.events }
+
+// Run polls until the context is done. Failed polls are retried with exponential backoff.
+// A high-water mark is only saved after all events of the poll were received.
+func (f *Feed) Run(ctx context.Context) error {
+	defer close(f.events)
+
+	wait := time.Duration(0)
+	failures := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+
+		err := f.poll(ctx, func(e Event) bool {
+			select {
+			case f.events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			f.opts.OnError(err)
+
+			failures++
+			wait = backoff(f.opts.Interval, f.opts.MaxBackoff, failures)
+		default:
+			failures = 0
+			wait = f.opts.Interval
+		}
+	}
+}
+
+// backoff returns the interval doubled for every failure, but at most the maximum.
+func backoff(interval, maxBackoff time.Duration, failures int) time.Duration {
+	wait := interval
+	for i := 0; i < failures && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	return min(wait, maxBackoff)
+}
+
+// Poll polls all sources once and returns the events.
+func (f *Feed) Poll(ctx context.Context) ([]Event, error) {
+	events := []Event{}
+
+	err := f.poll(ctx, func(e Event) bool {
+		events = append(events, e)
+		return true
+	})
+
+	return events, err
+}
+
+// poll polls all sources and emits their events.
+// Emitting returns false if the event could not be delivered.
+func (f *Feed) poll(ctx context.Context, emit func(Event) bool) error {
+	f.polls++
+	fullScan := f.opts.FullScanEvery > 0 && (f.polls-1)%f.opts.FullScanEvery == 0
+
+	for _, id := range f.opts.Databases {
+		if err := f.pollSource(ctx, string(id), fullScan, emit); err != nil {
+			return fmt.Errorf("polling database %s: %w", id, err)
+		}
+	}
+
+	if f.opts.Pages {
+		if err := f.pollSource(ctx, SearchSource, false, emit); err != nil {
+			return fmt.Errorf("polling pages: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (f *Feed) pollSource(ctx context.Context, source string, fullScan bool, emit func(Event) bool) error {
+	mark, err := f.opts.Store.Load(ctx, source)
+	if err != nil {
+		return fmt.Errorf("loading high-water mark: %w", err)
+	}
+
+	if mark.IsZero() {
+		mark = f.opts.Since
+	}
+
+	pages, all, err := f.fetch(ctx, source, mark, fullScan)
+	if err != nil {
+		return err
+	}
+
+	seen, known := f.state(source)
+	latest := mark
+
+	for _, p := range pages {
+		// the edit time only has a precision of minutes, so pages edited
+		// at the time of the high-water mark are fetched again
+		if edited, ok := seen[p.Id]; ok && !p.LastEditedTime.After(edited) {
+			continue
+		}
+
+		if !emit(Event{Type: eventType(p, mark, known), Source: source, Page: p}) {
+			return ctx.Err()
+		}
+
+		seen[p.Id] = p.LastEditedTime
+		known[p.Id] = !p.Archived
+
+		if p.LastEditedTime.After(latest) {
+			latest = p.LastEditedTime
+		}
+	}
+
+	if all != nil {
+		for id, exists := range known {
+			if !exists || all[id] {
+				continue
+			}
+
+			if !emit(Event{Type: EventArchived, Source: source, Page: notion.Page{Id: id, Archived: true}}) {
+				return ctx.Err()
+			}
+
+			known[id] = false
+		}
+
+		for id := range all {
+			known[id] = true
+		}
+	}
+
+	// pages edited before the high-water mark will not be fetched again
+	for id, edited := range seen {
+		if edited.Before(latest) {
+			delete(seen, id)
+		}
+	}
+
+	if err := f.opts.Store.Save(ctx, source, latest); err != nil {
+		return fmt.Errorf("saving high-water mark: %w", err)
+	}
+
+	return nil
+}
+
+// fetch returns the pages of the source edited at or after the high-water mark.
+// In a full scan, it also returns the IDs of all entries of the database.
+func (f *Feed) fetch(ctx context.Context, source string, mark time.Time, fullScan bool) (
+	notion.Pages, map[notion.UUID]bool, error,
+) {
+	if source == SearchSource {
+		pages, err := f.cli.GetNotionPagesEditedSince(ctx, mark)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		standalone := notion.Pages{}
+
+		for _, p := range pages {
+			if p.Parent == nil || p.Parent.DatabaseId == nil {
+				standalone = append(standalone, p)
+			}
+		}
+
+		return sortByEditTime(standalone), nil, nil
+	}
+
+	if !fullScan {
+		pages, err := f.cli.GetDatabaseEntries(ctx, notion.Id(source), notion.LastEditedSince(mark), nil)
+		return sortByEditTime(pages), nil, err
+	}
+
+	entries, err := f.cli.GetDatabaseEntries(ctx, notion.Id(source), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	all := make(map[notion.UUID]bool, len(entries))
+	pages := notion.Pages{}
+
+	for _, p := range entries {
+		all[p.Id] = true
+
+		if !p.LastEditedTime.Before(mark) {
+			pages = append(pages, p)
+		}
+	}
+
+	return sortByEditTime(pages), all, nil
+}
+
+func (f *Feed) state(source string) (map[notion.UUID]time.Time, map[notion.UUID]bool) {
+	if f.seen[source] == nil {
+		f.seen[source] = map[notion.UUID]time.Time{}
+		f.known[source] = map[notion.UUID]bool{}
+	}
+
+	return f.seen[source], f.known[source]
+}
+
+// eventType returns the type of the change of a page edited after the high-water mark.
+func eventType(p notion.Page, mark time.Time, known map[notion.UUID]bool) EventType {
+	switch {
+	case p.Archived:
+		return EventArchived
+	case p.CreatedTime != nil && !p.CreatedTime.Before(mark) && !known[p.Id]:
+		return EventCreated
+	default:
+		return EventUpdated
+	}
+}
+
+// sortByEditTime sorts the pages by the time they were last edited, the oldest first,
+// so that events are emitted in order.
+func sortByEditTime(pages notion.Pages) notion.Pages {
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].LastEditedTime.Before(pages[j].LastEditedTime)
+	})
+
+	return pages
+}
@@ -0,0 +1,188 @@
+package changefeed_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/changefeed"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const db notion.UUID = "00000000-0000-4000-8000-000000000001"
+
+var t0 = time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+func at(minutes int) *time.Time {
+	t := t0.Add(time.Duration(minutes) * time.Minute)
+	return &t
+}
+
+func page(id notion.UUID, created, edited int, inDatabase bool) notion.Page {
+	p := notion.Page{Id: id, CreatedTime: at(created), LastEditedTime: *at(edited), Parent: &notion.Parent{}}
+	if inDatabase {
+		dbID := db
+		p.Parent.DatabaseId = &dbID
+	}
+
+	return p
+}
+
+// memClient returns the pages it has, filtering by edit time.
+type memClient struct {
+	mu      sync.Mutex
+	entries notion.Pages
+	pages   notion.Pages
+	err     error
+}
+
+func (c *memClient) GetDatabaseEntries(
+	_ context.Context, _ notion.Id, filter *notion.Filter, _ *notion.Sorts,
+) (notion.Pages, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.err; err != nil {
+		c.err = nil
+		return nil, err
+	}
+
+	if filter == nil {
+		return c.entries, nil
+	}
+
+	return editedSince(c.entries, *filter.Timestamp.LastEditedTime.OnOrAfter), nil
+}
+
+func (c *memClient) GetNotionPagesEditedSince(_ context.Context, since time.Time) (notion.Pages, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return editedSince(append(c.pages, c.entries...), since), nil
+}
+
+func editedSince(pages notion.Pages, since time.Time) notion.Pages {
+	res := notion.Pages{}
+
+	for _, p := range pages {
+		if !p.LastEditedTime.Before(since) {
+			res = append(res, p)
+		}
+	}
+
+	return res
+}
+
+type change struct {
+	Type changefeed.EventType
+	ID   notion.UUID
+}
+
+func changes(events []changefeed.Event) []change {
+	res := []change{}
+	for _, e := range events {
+		res = append(res, change{e.Type, e.Page.Id})
+	}
+
+	return res
+}
+
+func TestFeed_Poll(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cli := &memClient{
+		entries: notion.Pages{
+			page("old", -60, -30, true),
+			page("b", -60, 2, true),
+			page("a", 1, 1, true),
+		},
+		pages: notion.Pages{page("standalone", -60, 3, false)},
+	}
+
+	store := changefeed.NewFileStore(filepath.Join(t.TempDir(), "marks.json"))
+
+	feed := changefeed.New(cli, changefeed.Options{
+		Databases:     []notion.Id{notion.Id(db)},
+		Pages:         true,
+		Store:         store,
+		Since:         t0,
+		FullScanEvery: 2,
+	})
+
+	events, err := feed.Poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []change{
+		{changefeed.EventCreated, "a"},
+		{changefeed.EventUpdated, "b"},
+		{changefeed.EventUpdated, "standalone"},
+	}, changes(events))
+	assert.Equal(t, string(db), events[0].Source)
+	assert.Equal(t, changefeed.SearchSource, events[2].Source)
+
+	mark, err := store.Load(ctx, string(db))
+	require.NoError(t, err)
+	assert.Equal(t, *at(2), mark)
+
+	// nothing changed
+	events, err = feed.Poll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// "a" was edited and "old" was archived, which the full scan notices
+	cli.entries = notion.Pages{page("b", -60, 2, true), page("a", 1, 5, true)}
+
+	events, err = feed.Poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []change{
+		{changefeed.EventUpdated, "a"},
+		{changefeed.EventArchived, "old"},
+	}, changes(events))
+	assert.True(t, events[1].Page.Archived)
+
+	// a new feed continues from the stored high-water marks
+	events, err = changefeed.New(cli, changefeed.Options{
+		Databases: []notion.Id{notion.Id(db)},
+		Store:     store,
+		Since:     t0,
+	}).Poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []change{{changefeed.EventUpdated, "a"}}, changes(events))
+}
+
+func TestFeed_Run(t *testing.T) {
+	t.Parallel()
+
+	cli := &memClient{
+		entries: notion.Pages{page("a", 1, 1, true)},
+		err:     errors.New("bad gateway"),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+
+	feed := changefeed.New(cli, changefeed.Options{
+		Databases: []notion.Id{notion.Id(db)},
+		Since:     t0,
+		Interval:  time.Millisecond,
+		OnError:   func(err error) { errs <- err },
+	})
+
+	done := make(chan error)
+	go func() { done <- feed.Run(ctx) }()
+
+	e := <-feed.Events()
+	assert.Equal(t, change{changefeed.EventCreated, "a"}, change{e.Type, e.Page.Id})
+	assert.EqualError(t, <-errs, "polling database "+string(db)+": bad gateway")
+
+	cancel()
+	require.NoError(t, <-done)
+
+	_, open := <-feed.Events()
+	assert.False(t, open)
+}
@@ -0,0 +1,126 @@
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store keeps the high-water marks of the sources, i.e. the time the last seen change was made.
+type Store interface {
+	// Load returns the high-water mark of the source or the zero time if there is none.
+	Load(ctx context.Context, source string) (time.Time, error)
+	// Save stores the high-water mark of the source.
+	Save(ctx context.Context, source string, t time.Time) error
+}
+
+// MemoryStore keeps the high-water marks in memory.
+type MemoryStore struct {
+	mu    sync.Mutex
+	marks map[string]time.Time
+}
+
+// NewMemoryStore returns a new, empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{marks: map[string]time.Time{}}
+}
+
+// Load fulfils Store.
+func (s *MemoryStore) Load(_ context.Context, source string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.marks[source], nil
+}
+
+// Save fulfils Store.
+func (s *MemoryStore) Save(_ context.Context, source string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.marks[source] = t
+
+	return nil
+}
+
+// FileStore keeps the high-water marks in a JSON file.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore returns a store that keeps the high-water marks in the file,
+// which is created on the first save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) read() (map[string]time.Time, error) {
+	marks := map[string]time.Time{}
+
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return marks, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return marks, json.Unmarshal(b, &marks)
+}
+
+// Load fulfils Store.
+func (s *FileStore) Load(_ context.Context, source string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	marks, err := s.read()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return marks[source], nil
+}
+
+// Save fulfils Store. The file is replaced atomically.
+func (s *FileStore) Save(_ context.Context, source string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	marks, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	marks[source] = t
+
+	b, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"encoding/json"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Blocks returns the differences between two snapshots of the same tree of blocks.
+//
+// Blocks are matched by their ID. Blocks that were moved to a different parent
+// are reported as a change of the parent, changes in the order of blocks are not reported.
+func Blocks(old, new *notion.BlockTree) Changes {
+	cs := Changes{}
+
+	oldNodes := nodesByID(old)
+	seen := map[notion.UUID]bool{}
+
+	_ = new.Walk(func(n *notion.BlockNode) error {
+		if seen[n.Id] {
+			// synced blocks can appear several times
+			return nil
+		}
+
+		seen[n.Id] = true
+		path := "blocks." + string(n.Id)
+
+		o, ok := oldNodes[n.Id]
+		if !ok {
+			cs = append(cs, Change{Kind: KindAdded, Path: path, New: blockValue(n.Block)})
+			return nil
+		}
+
+		if !o.EqualContent(n.Block) {
+			var oldVal, newVal any = blockValue(o.Block), blockValue(n.Block)
+			if oldVal == newVal {
+				// the text is the same, so something else changed
+				oldVal, newVal = blockContent(o.Block), blockContent(n.Block)
+			}
+
+			cs = append(cs, Change{Kind: KindChanged, Path: path, Old: oldVal, New: newVal})
+		}
+
+		cs.compareValues(path+".parent", parentID(old, o), parentID(new, n))
+
+		return nil
+	})
+
+	_ = old.Walk(func(n *notion.BlockNode) error {
+		if !seen[n.Id] {
+			seen[n.Id] = true
+
+			cs = append(cs, Change{Kind: KindRemoved, Path: "blocks." + string(n.Id), Old: blockValue(n.Block)})
+		}
+
+		return nil
+	})
+
+	return cs
+}
+
+func nodesByID(t *notion.BlockTree) map[notion.UUID]*notion.BlockNode {
+	nodes := map[notion.UUID]*notion.BlockNode{}
+
+	_ = t.Walk(func(n *notion.BlockNode) error {
+		if _, ok := nodes[n.Id]; !ok {
+			nodes[n.Id] = n
+		}
+
+		return nil
+	})
+
+	return nodes
+}
+
+func parentID(t *notion.BlockTree, n *notion.BlockNode) string {
+	if p := n.Parent(); p != nil {
+		return string(p.Id)
+	}
+
+	return string(t.ID)
+}
+
+// blockValue returns the type and text of the block.
+func blockValue(b notion.Block) string {
+	if s := b.Content(); s != "" {
+		return string(b.Type) + ": " + s
+	}
+
+	return string(b.Type)
+}
+
+// blockContent returns the type specific content of the block.
+func blockContent(b notion.Block) any {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return nil
+	}
+
+	all := map[string]any{}
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil
+	}
+
+	content, _ := all[string(b.Type)].(map[string]any)
+
+	return content
+}
@@ -0,0 +1,125 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Databases returns the differences between two snapshots of a database, including its schema.
+func Databases(old, new notion.Database) Changes {
+	cs := Changes{}
+
+	cs.compareValues("title", nonEmpty(old.Title.Content()), nonEmpty(new.Title.Content()))
+	cs.compareValues("description", nonEmpty(old.Description.Content()), nonEmpty(new.Description.Content()))
+	cs.compareValues("archived", old.Archived, new.Archived)
+	cs.compareValues("is_inline", old.IsInline, new.IsInline)
+	cs.compareValues("icon", iconValue(old.Icon), iconValue(new.Icon))
+	cs.compareValues("cover", fileValue(old.Cover), fileValue(new.Cover))
+
+	return append(cs, Schemas(old.Properties, new.Properties)...)
+}
+
+// Schemas returns the differences between two snapshots of the properties of a database.
+//
+// Properties are matched by their ID so that renamed properties are reported as such.
+// Options of select, multi-select and status properties are reported as added and removed elements.
+func Schemas(old, new notion.PropertyMetaMap) Changes {
+	cs := Changes{}
+
+	// the name of each property in the old schema by ID
+	oldNames := make(map[string]string, len(old))
+	for name, p := range old {
+		oldNames[p.Id] = name
+	}
+
+	matched := map[string]bool{}
+
+	for _, name := range sortedKeys(new) {
+		p := new[name]
+		path := "schema." + name
+
+		oldName, ok := oldNames[p.Id]
+		if !ok || p.Id == "" {
+			// properties of desired schemas might not have an ID yet
+			oldName = name
+		}
+
+		oldProp, ok := old[oldName]
+		if !ok {
+			cs = append(cs, Change{Kind: KindAdded, Path: path, New: string(p.Type)})
+			continue
+		}
+
+		matched[oldName] = true
+
+		cs.compareValues(path+".name", oldName, name)
+		cs.compareSchema(path, oldProp, p)
+	}
+
+	for _, name := range sortedKeys(old) {
+		if !matched[name] {
+			cs = append(cs, Change{Kind: KindRemoved, Path: "schema." + name, Old: string(old[name].Type)})
+		}
+	}
+
+	return cs
+}
+
+func (cs *Changes) compareSchema(path string, old, new notion.PropertyMeta) {
+	if old.Type != new.Type {
+		cs.compareValues(path+".type", string(old.Type), string(new.Type))
+		return
+	}
+
+	oldConf, newConf := propertyConfig(old), propertyConfig(new)
+
+	cs.compareSets(path+".options", optionNames(oldConf), optionNames(newConf))
+
+	// the options (and the groups of status properties) are compared above
+	delete(oldConf, "options")
+	delete(oldConf, "groups")
+	delete(newConf, "options")
+	delete(newConf, "groups")
+
+	if len(oldConf) == 0 && len(newConf) == 0 {
+		return
+	}
+
+	if !reflect.DeepEqual(oldConf, newConf) {
+		*cs = append(*cs, Change{Kind: KindChanged, Path: path + ".config", Old: oldConf, New: newConf})
+	}
+}
+
+// propertyConfig returns the type specific configuration of the property.
+func propertyConfig(p notion.PropertyMeta) map[string]any {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+
+	all := map[string]any{}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil
+	}
+
+	conf, _ := all[string(p.Type)].(map[string]any)
+
+	return conf
+}
+
+func optionNames(conf map[string]any) []string {
+	opts, _ := conf["options"].([]any)
+
+	names := make([]string, 0, len(opts))
+	for _, opt := range opts {
+		if o, ok := opt.(map[string]any); ok {
+			if name, ok := o["name"].(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
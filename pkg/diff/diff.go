@@ -0,0 +1,161 @@
+// Package diff computes the semantic differences between two snapshots
+// of the same page, database or tree of blocks.
+//
+// Fields that change without the content changing, like the signatures
+// and expiry times of files hosted by notion, are ignored.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Kind defines the kind of change.
+type Kind string
+
+// Defines values for Kind.
+const (
+	KindAdded   Kind = "added"
+	KindRemoved Kind = "removed"
+	KindChanged Kind = "changed"
+)
+
+// Change is a single difference between two snapshots.
+type Change struct {
+	Kind Kind `json:"kind"`
+
+	// Path identifies what has changed, e.g. "properties.Status", "schema.Tags.options" or "blocks.<id>".
+	Path string `json:"path"`
+
+	// Old is the previous value, if any.
+	Old any `json:"old,omitempty"`
+	// New is the current value, if any.
+	New any `json:"new,omitempty"`
+
+	// Added are the elements added to a list, e.g. relations, multi-select values or select options.
+	Added []string `json:"added,omitempty"`
+	// Removed are the elements removed from a list.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Changes is a list of changes.
+type Changes []Change
+
+// String returns a human readable description of the change.
+func (c Change) String() string {
+	switch c.Kind {
+	case KindAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, format(c.New))
+	case KindRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, format(c.Old))
+	}
+
+	if len(c.Added) == 0 && len(c.Removed) == 0 {
+		return fmt.Sprintf("~ %s: %s → %s", c.Path, format(c.Old), format(c.New))
+	}
+
+	parts := []string{}
+	if len(c.Added) > 0 {
+		parts = append(parts, "added "+format(c.Added))
+	}
+
+	if len(c.Removed) > 0 {
+		parts = append(parts, "removed "+format(c.Removed))
+	}
+
+	return fmt.Sprintf("~ %s: %s", c.Path, strings.Join(parts, ", "))
+}
+
+// String returns a human readable description of all changes, one per line.
+func (cs Changes) String() string {
+	if len(cs) == 0 {
+		return "no changes"
+	}
+
+	lines := make([]string, len(cs))
+	for i, c := range cs {
+		lines[i] = c.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// JSON returns the changes as indented JSON.
+func (cs Changes) JSON() ([]byte, error) {
+	if cs == nil {
+		cs = Changes{}
+	}
+
+	return json.MarshalIndent(cs, "", "  ")
+}
+
+func format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "<empty>"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = fmt.Sprintf("%q", s)
+		}
+
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case map[string]any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareSets adds a change if elements were added to or removed from a list.
+func (cs *Changes) compareSets(path string, old, new []string) {
+	added, removed := setDiff(old, new)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	*cs = append(*cs, Change{Kind: KindChanged, Path: path, Added: added, Removed: removed})
+}
+
+// compareValues adds a change if the values differ.
+// The values must be comparable, i.e. no slices or maps.
+func (cs *Changes) compareValues(path string, old, new any) {
+	if old == new {
+		return
+	}
+
+	*cs = append(*cs, Change{Kind: KindChanged, Path: path, Old: old, New: new})
+}
+
+// setDiff returns the elements that are only in new and the ones only in old, keeping their order.
+func setDiff(old, new []string) (added, removed []string) {
+	inOld := make(map[string]bool, len(old))
+	for _, s := range old {
+		inOld[s] = true
+	}
+
+	inNew := make(map[string]bool, len(new))
+	for _, s := range new {
+		inNew[s] = true
+
+		if !inOld[s] {
+			added = append(added, s)
+		}
+	}
+
+	for _, s := range old {
+		if !inNew[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	return added, removed
+}
@@ -0,0 +1,194 @@
+package diff_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/diff"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selectValues(names ...string) *notion.SelectValues {
+	vals := notion.SelectValues{}
+	for _, name := range names {
+		vals = append(vals, notion.SelectValue{Name: name})
+	}
+
+	return &vals
+}
+
+func notionFile(url string, expiry time.Time) *notion.Files {
+	return &notion.Files{{
+		Type: notion.FileTypeFile,
+		File: &notion.NotionFile{Url: url, ExpiryTime: expiry},
+	}}
+}
+
+func TestPages(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	old := notion.Page{Properties: notion.PropertyValueMap{
+		"Name":   {Type: notion.PropertyTypeTitle, Title: notion.NewRichTextsP("Report")},
+		"Status": {Type: notion.PropertyTypeStatus, Status: &notion.SelectValue{Name: "Todo"}},
+		"Tags":   {Type: notion.PropertyTypeMultiSelect, MultiSelect: selectValues("a", "b")},
+		"Files": {
+			Type:  notion.PropertyTypeFiles,
+			Files: notionFile("https://s3.example.com/report.pdf?X-Amz-Signature=1", now),
+		},
+		"Edited": {Type: notion.PropertyTypeLastEditedTime},
+		"Notes":  {Type: notion.PropertyTypeRichText, RichText: notion.NewRichTextsP("draft")},
+	}}
+
+	new := notion.Page{Properties: notion.PropertyValueMap{
+		"Name":   {Type: notion.PropertyTypeTitle, Title: notion.NewRichTextsP("Report")},
+		"Status": {Type: notion.PropertyTypeStatus, Status: &notion.SelectValue{Name: "Done"}},
+		"Tags":   {Type: notion.PropertyTypeMultiSelect, MultiSelect: selectValues("b", "c")},
+		"Files": {
+			Type:  notion.PropertyTypeFiles,
+			Files: notionFile("https://s3.example.com/report.pdf?X-Amz-Signature=2", now.Add(time.Hour)),
+		},
+		"Edited": {Type: notion.PropertyTypeLastEditedTime},
+		"Score":  {Type: notion.PropertyTypeNumber, Number: func(f float64) *float64 { return &f }(3)},
+	}, Archived: true}
+
+	cs := diff.Pages(old, new)
+	assert.Equal(t, diff.Changes{
+		{Kind: diff.KindChanged, Path: "archived", Old: false, New: true},
+		{Kind: diff.KindRemoved, Path: "properties.Notes", Old: "draft"},
+		{Kind: diff.KindAdded, Path: "properties.Score", New: 3.0},
+		{Kind: diff.KindChanged, Path: "properties.Status", Old: "Todo", New: "Done"},
+		{Kind: diff.KindChanged, Path: "properties.Tags", Added: []string{"c"}, Removed: []string{"a"}},
+	}, cs)
+
+	assert.Equal(t, `~ archived: false → true
+- properties.Notes: "draft"
++ properties.Score: 3
+~ properties.Status: "Todo" → "Done"
+~ properties.Tags: added ["c"], removed ["a"]`, cs.String())
+
+	b, err := cs.JSON()
+	require.NoError(t, err)
+
+	var decoded []map[string]any
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Len(t, decoded, 5)
+	assert.Equal(t, "properties.Status", decoded[3]["path"])
+
+	assert.Empty(t, diff.Pages(old, old))
+	assert.Equal(t, "no changes", diff.Pages(old, old).String())
+}
+
+func TestProperties_Rollup(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rollup := func(sig string, expiry time.Time, names ...string) notion.PropertyValue {
+		return notion.PropertyValue{Type: notion.PropertyTypeRollup, Rollup: &notion.Rollup{
+			Type: notion.RollupTypeArray,
+			Array: &notion.RollupArray{
+				{
+					Type:  notion.RollupArrayItemTypeFiles,
+					Files: notionFile("https://s3.example.com/a.pdf?X-Amz-Signature="+sig, expiry),
+				},
+				{Type: notion.RollupArrayItemTypeMultiSelect, MultiSelect: selectValues(names...)},
+			},
+		}}
+	}
+
+	// file signatures change with every request
+	old := notion.PropertyValueMap{"Files": rollup("1", now, "a")}
+	assert.Empty(t, diff.Properties(old, notion.PropertyValueMap{"Files": rollup("2", now.Add(time.Hour), "a")}))
+
+	assert.Equal(t, diff.Changes{{
+		Kind: diff.KindChanged, Path: "properties.Files",
+		Old: `[["https://s3.example.com/a.pdf"],["a"]]`,
+		New: `[["https://s3.example.com/a.pdf"],["b"]]`,
+	}}, diff.Properties(old, notion.PropertyValueMap{"Files": rollup("1", now, "b")}))
+}
+
+func TestProperties_UniqueID(t *testing.T) {
+	t.Parallel()
+
+	prefix := "TASK"
+
+	id := func(n int) notion.PropertyValue {
+		return notion.PropertyValue{Type: notion.PropertyTypeUniqueId, UniqueId: &notion.UniqueId{Number: n, Prefix: &prefix}}
+	}
+
+	assert.Empty(t, diff.Properties(notion.PropertyValueMap{"ID": id(1)}, notion.PropertyValueMap{"ID": id(1)}))
+	assert.Equal(t, diff.Changes{{Kind: diff.KindChanged, Path: "properties.ID", Old: "TASK-1", New: "TASK-2"}},
+		diff.Properties(notion.PropertyValueMap{"ID": id(1)}, notion.PropertyValueMap{"ID": id(2)}))
+}
+
+func TestSchemas(t *testing.T) {
+	t.Parallel()
+
+	old := notion.PropertyMetaMap{
+		"Name":     {Id: "title", Type: notion.PropertyTypeTitle},
+		"Priority": {Id: "a", Type: notion.PropertyTypeSelect, Select: &notion.SelectValuesWrapper{Options: *selectValues("Low", "High")}},
+		"Estimate": {Id: "b", Type: notion.PropertyTypeNumber, Number: &notion.NumberConfig{Format: "number"}},
+		"Obsolete": {Id: "c", Type: notion.PropertyTypeCheckbox},
+	}
+
+	new := notion.PropertyMetaMap{
+		"Name":       {Id: "title", Type: notion.PropertyTypeTitle},
+		"Importance": {Id: "a", Type: notion.PropertyTypeSelect, Select: &notion.SelectValuesWrapper{Options: *selectValues("Low", "Medium", "High")}},
+		"Estimate":   {Id: "b", Type: notion.PropertyTypeNumber, Number: &notion.NumberConfig{Format: "dollar"}},
+		"Due":        {Type: notion.PropertyTypeDate},
+	}
+
+	cs := diff.Schemas(old, new)
+	assert.Equal(t, `+ schema.Due: "date"
+~ schema.Estimate.config: {"format":"number"} → {"format":"dollar"}
+~ schema.Importance.name: "Priority" → "Importance"
+~ schema.Importance.options: added ["Medium"]
+- schema.Obsolete: "checkbox"`, cs.String())
+}
+
+func TestBlocks(t *testing.T) {
+	t.Parallel()
+
+	node := func(id notion.UUID, b notion.Block, children ...*notion.BlockNode) *notion.BlockNode {
+		b.Id = id
+		return notion.NewBlockNode(b, children...)
+	}
+
+	old := notion.NewBlockTree("page",
+		node("p1", notion.NewParagraphBlock("hello")),
+		node("t", notion.NewToggle("details"),
+			node("todo", notion.NewToDo("write report", false)),
+		),
+		node("d", notion.NewDivider()),
+	)
+
+	new := notion.NewBlockTree("page",
+		node("p1", notion.NewParagraphBlock("hello world")),
+		node("t", notion.NewToggle("details")),
+		node("todo", notion.NewToDo("write report", true)),
+		node("p2", notion.NewParagraphBlock("new")),
+	)
+
+	cs := diff.Blocks(old, new)
+	require.Len(t, cs, 5)
+
+	assert.Equal(t, diff.Change{
+		Kind: diff.KindChanged, Path: "blocks.p1",
+		Old: "paragraph: hello", New: "paragraph: hello world",
+	}, cs[0])
+
+	// the text is the same, so the content is shown
+	assert.Equal(t, "blocks.todo", cs[1].Path)
+	assert.Equal(t, true, cs[1].New.(map[string]any)["checked"])
+
+	assert.Equal(t, diff.Change{Kind: diff.KindChanged, Path: "blocks.todo.parent", Old: "t", New: "page"}, cs[2])
+	assert.Equal(t, diff.Change{Kind: diff.KindAdded, Path: "blocks.p2", New: "paragraph: new"}, cs[3])
+	assert.Equal(t, diff.Change{Kind: diff.KindRemoved, Path: "blocks.d", Old: "divider"}, cs[4])
+
+	assert.Empty(t, diff.Blocks(old, old))
+}
@@ -0,0 +1,300 @@
+package diff
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Pages returns the differences between two snapshots of a page.
+// The content of the page is not compared, use Blocks for that.
+func Pages(old, new notion.Page) Changes {
+	cs := Changes{}
+
+	cs.compareValues("archived", old.Archived, new.Archived)
+	cs.compareValues("icon", iconValue(old.Icon), iconValue(new.Icon))
+	cs.compareValues("cover", fileValue(old.Cover), fileValue(new.Cover))
+
+	return append(cs, Properties(old.Properties, new.Properties)...)
+}
+
+// Properties returns the differences between two snapshots of the property values of a page.
+//
+// Relations, multi-select values, people and files are reported as added and removed elements.
+// Properties that change with every edit, like the last edited time, are ignored.
+func Properties(old, new notion.PropertyValueMap) Changes {
+	cs := Changes{}
+
+	for _, name := range sortedKeys(old, new) {
+		path := "properties." + name
+
+		oldVal, inOld := old[name]
+		newVal, inNew := new[name]
+
+		switch {
+		case volatileProperty(oldVal.Type) || volatileProperty(newVal.Type):
+		case !inNew:
+			cs = append(cs, Change{Kind: KindRemoved, Path: path, Old: propertyValue(oldVal)})
+		case !inOld:
+			cs = append(cs, Change{Kind: KindAdded, Path: path, New: propertyValue(newVal)})
+		case oldVal.Type != newVal.Type:
+			cs = append(cs, Change{
+				Kind: KindChanged, Path: path,
+				Old: propertyValue(oldVal), New: propertyValue(newVal),
+			})
+		default:
+			oldElems, isList := listValue(oldVal)
+			if isList {
+				newElems, _ := listValue(newVal)
+				cs.compareSets(path, oldElems, newElems)
+
+				continue
+			}
+
+			cs.compareValues(path, scalarValue(oldVal), scalarValue(newVal))
+		}
+	}
+
+	return cs
+}
+
+// volatileProperty returns true for properties that change whenever the page is edited.
+func volatileProperty(tp notion.PropertyType) bool {
+	return tp == notion.PropertyTypeLastEditedTime || tp == notion.PropertyTypeLastEditedBy
+}
+
+// propertyValue returns the value of the property in a form that can be compared and displayed.
+func propertyValue(v notion.PropertyValue) any {
+	if elems, ok := listValue(v); ok {
+		return elems
+	}
+
+	return scalarValue(v)
+}
+
+// listValue returns the elements of properties that contain a list of values.
+func listValue(v notion.PropertyValue) ([]string, bool) {
+	switch v.Type {
+	case notion.PropertyTypeMultiSelect:
+		names := []string{}
+		for _, s := range v.GetMultiSelect() {
+			names = append(names, s.Name)
+		}
+
+		return names, true
+	case notion.PropertyTypeRelation:
+		return v.GetRelation().GetUUIDs().Strings(), true
+	case notion.PropertyTypePeople:
+		ids := []string{}
+		for _, u := range v.GetPeople() {
+			ids = append(ids, string(u.Id))
+		}
+
+		return ids, true
+	case notion.PropertyTypeFiles:
+		urls := []string{}
+		for _, f := range v.GetFiles() {
+			urls = append(urls, fileURL(f))
+		}
+
+		return urls, true
+	default:
+		return nil, false
+	}
+}
+
+// scalarValue returns a comparable representation of properties with a single value.
+// Empty values are returned as nil.
+func scalarValue(v notion.PropertyValue) any {
+	switch v.Type {
+	case notion.PropertyTypeTitle:
+		return nonEmpty(v.GetTitle().Content())
+	case notion.PropertyTypeRichText:
+		return nonEmpty(v.GetRichText().Content())
+	case notion.PropertyTypeNumber:
+		if v.Number == nil {
+			return nil
+		}
+
+		return *v.Number
+	case notion.PropertyTypeCheckbox:
+		return v.GetCheckbox()
+	case notion.PropertyTypeSelect:
+		if v.Select == nil {
+			return nil
+		}
+
+		return v.Select.Name
+	case notion.PropertyTypeStatus:
+		if v.Status == nil {
+			return nil
+		}
+
+		return v.Status.Name
+	case notion.PropertyTypeDate:
+		if v.Date == nil {
+			return nil
+		}
+
+		return v.Date.String()
+	case notion.PropertyTypeUrl:
+		return nonEmpty(v.GetURL())
+	case notion.PropertyTypeEmail:
+		return nonEmpty(v.GetEmail())
+	case notion.PropertyTypePhoneNumber:
+		return nonEmpty(v.GetPhoneNumber())
+	case notion.PropertyTypeCreatedTime:
+		return v.GetCreatedTime().String()
+	case notion.PropertyTypeLastEditedTime:
+		return v.GetLastEditedTime().String()
+	case notion.PropertyTypeCreatedBy:
+		return string(v.GetCreatedBy().Id)
+	case notion.PropertyTypeLastEditedBy:
+		return string(v.GetLastEditedBy().Id)
+	case notion.PropertyTypeUniqueId:
+		if v.UniqueId == nil {
+			return nil
+		}
+
+		return v.UniqueId.String()
+	case notion.PropertyTypeFormula:
+		return formulaValue(v.GetFormula())
+	case notion.PropertyTypeRollup:
+		return rollupValue(v.GetRollup())
+	default:
+		return nil
+	}
+}
+
+func formulaValue(f notion.Formula) any {
+	switch {
+	case f.String != nil:
+		return nonEmpty(*f.String)
+	case f.Number != nil:
+		return *f.Number
+	case f.Boolean != nil:
+		return *f.Boolean
+	case f.Date != nil:
+		return f.Date.String()
+	default:
+		return nil
+	}
+}
+
+func rollupValue(r notion.Rollup) any {
+	switch {
+	case r.String != nil:
+		return nonEmpty(*r.String)
+	case r.Number != nil:
+		return *r.Number
+	case r.Date != nil:
+		return r.Date.String()
+	case r.Array != nil:
+		// the elements are compared like top-level values,
+		// and encoded since the values must be comparable
+		elems := make([]any, len(*r.Array))
+		for i, item := range *r.Array {
+			elems[i] = rollupArrayItemValue(item)
+		}
+
+		b, err := json.Marshal(elems)
+		if err != nil {
+			return nil
+		}
+
+		return string(b)
+	default:
+		return nil
+	}
+}
+
+// rollupArrayItemValue returns the value of an element of a rollup array like propertyValue.
+func rollupArrayItemValue(item notion.RollupArrayItem) any {
+	if item.Type == notion.RollupArrayItemTypeString {
+		if item.String == nil {
+			return nil
+		}
+
+		return nonEmpty(*item.String)
+	}
+
+	return propertyValue(notion.PropertyValue{
+		Type:        notion.PropertyType(item.Type),
+		Checkbox:    item.Checkbox,
+		Date:        item.Date,
+		Email:       item.Email,
+		Files:       item.Files,
+		Formula:     item.Formula,
+		MultiSelect: item.MultiSelect,
+		Number:      item.Number,
+		People:      item.People,
+		PhoneNumber: item.PhoneNumber,
+		Relation:    item.Relation,
+		RichText:    item.RichText,
+		Select:      item.Select,
+		Status:      item.Status,
+		Title:       item.Title,
+		Url:         item.Url,
+	})
+}
+
+// fileURL returns the URL of the file without the signature of files hosted by notion.
+func fileURL(f notion.File) string {
+	if f.Type == notion.FileTypeFile {
+		return notion.WithoutQuery(f.URL())
+	}
+
+	return f.URL()
+}
+
+func fileValue(f *notion.File) any {
+	if f == nil {
+		return nil
+	}
+
+	return fileURL(*f)
+}
+
+func iconValue(ic *notion.Icon) any {
+	switch {
+	case ic == nil:
+		return nil
+	case ic.Type == notion.IconTypeEmoji:
+		if ic.Emoji == nil {
+			return nil
+		}
+
+		return *ic.Emoji
+	case ic.Type == notion.IconTypeFile:
+		return notion.WithoutQuery(ic.URL())
+	default:
+		return ic.URL()
+	}
+}
+
+func nonEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
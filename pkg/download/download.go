@@ -0,0 +1,236 @@
+// Package download downloads files of blocks and pages, e.g. images and attachments for an export.
+//
+// The URLs of files hosted by Notion expire after an hour. If a URL expired
+// or the download is forbidden, the block or page the file belongs to is fetched
+// again to get a fresh URL. Files are stored by the hash of their content,
+// so the same content is only stored once.
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/faetools/client"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/spf13/afero"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// defaultConcurrency is the default number of concurrent downloads.
+	defaultConcurrency = 4
+
+	// expiryMargin is the time before the expiry of a URL at which it is already refreshed.
+	expiryMargin = time.Minute
+)
+
+// errForbidden is returned when a download is forbidden, usually because the URL expired.
+var errForbidden = errors.New("forbidden")
+
+var _ Client = (*notion.Client)(nil)
+
+// Client is any client that can get blocks and pages to refresh the URLs of files.
+type Client interface {
+	// GetNotionBlock returns the block.
+	GetNotionBlock(ctx context.Context, id notion.Id) (*notion.Block, error)
+	// GetNotionPage returns the page.
+	GetNotionPage(ctx context.Context, id notion.Id) (*notion.Page, error)
+}
+
+// Options define how files are downloaded.
+type Options struct {
+	// Dir is the directory the files are written to. Defaults to the working directory.
+	Dir string
+
+	// Concurrency is the maximum number of concurrent downloads. Defaults to 4.
+	Concurrency int
+
+	// HTTPClient downloads the files. Defaults to http.DefaultClient.
+	HTTPClient client.HTTPRequestDoer
+}
+
+// Result is the result of downloading a file.
+type Result struct {
+	Ref Ref
+	// Path is the path of the downloaded file.
+	Path string
+	// Err is the error if the file could not be downloaded.
+	Err error
+}
+
+// Downloader downloads files into a filesystem.
+type Downloader struct {
+	cli  Client
+	fs   afero.Fs
+	opts Options
+}
+
+// New returns a new downloader writing into the filesystem.
+func New(cli Client, fs afero.Fs, opts Options) *Downloader {
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+
+	return &Downloader{cli: cli, fs: fs, opts: opts}
+}
+
+// Download downloads the files and returns a result for each reference.
+// The same file referenced more than once is only downloaded once.
+// The error joins the errors of all files that could not be downloaded.
+func (d *Downloader) Download(ctx context.Context, refs ...Ref) ([]Result, error) {
+	if err := d.fs.MkdirAll(d.opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(refs))
+
+	// the index of the first result of each file
+	first := map[string]int{}
+
+	eg := &errgroup.Group{}
+	eg.SetLimit(d.opts.Concurrency)
+
+	for i, ref := range refs {
+		results[i].Ref = ref
+
+		key := notion.WithoutQuery(ref.File.URL())
+		if _, ok := first[key]; ok {
+			continue
+		}
+
+		first[key] = i
+
+		i, ref := i, ref
+
+		eg.Go(func() error {
+			results[i].Path, results[i].Err = d.download(ctx, ref)
+			return nil
+		})
+	}
+
+	_ = eg.Wait()
+
+	errs := []error{}
+
+	for i := range results {
+		res := &results[i]
+
+		if j := first[notion.WithoutQuery(res.Ref.File.URL())]; j != i {
+			res.Path, res.Err = results[j].Path, results[j].Err
+		}
+
+		if res.Err != nil {
+			res.Err = fmt.Errorf("downloading %s of %s: %w", res.Ref.File.GetName(), res.Ref.Owner, res.Err)
+			errs = append(errs, res.Err)
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// download downloads the file, refreshing its URL if needed, and returns its path.
+func (d *Downloader) download(ctx context.Context, ref Ref) (string, error) {
+	f := ref.File
+
+	if f.Type == notion.FileTypeFileUpload {
+		return "", errors.New("file uploads can only be downloaded once they are attached")
+	}
+
+	if d.expired(f) {
+		refreshed, err := ref.refresh(ctx, d.cli)
+		if err != nil {
+			return "", fmt.Errorf("refreshing URL: %w", err)
+		}
+
+		f = refreshed
+	}
+
+	p, err := d.get(ctx, f)
+	if !errors.Is(err, errForbidden) || f.File == nil {
+		return p, err
+	}
+
+	// the URL might have expired in the meantime
+	refreshed, err := ref.refresh(ctx, d.cli)
+	if err != nil {
+		return "", fmt.Errorf("refreshing URL: %w", err)
+	}
+
+	return d.get(ctx, refreshed)
+}
+
+// expired reports whether the URL of the file expired or is about to.
+func (d *Downloader) expired(f notion.File) bool {
+	exp := f.ExpiryTime()
+	return exp != nil && !exp.IsZero() && time.Now().Add(expiryMargin).After(*exp)
+}
+
+// get downloads the file and stores it by the hash of its content.
+func (d *Downloader) get(ctx context.Context, f notion.File) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URL(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := d.opts.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		return "", errForbidden
+	default:
+		return "", fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	tmp, err := afero.TempFile(d.fs, d.opts.Dir, ".download-*")
+	if err != nil {
+		return "", err
+	}
+
+	// stream the content into the file while hashing it
+	h := sha256.New()
+
+	_, err = io.Copy(io.MultiWriter(tmp, h), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = d.fs.Remove(tmp.Name())
+		return "", err
+	}
+
+	p := filepath.Join(d.opts.Dir, hex.EncodeToString(h.Sum(nil))+extension(f))
+
+	if ok, _ := afero.Exists(d.fs, p); ok {
+		return p, d.fs.Remove(tmp.Name())
+	}
+
+	return p, d.fs.Rename(tmp.Name(), p)
+}
+
+// extension returns the extension of the file name, including the dot.
+func extension(f notion.File) string {
+	return strings.ToLower(path.Ext(f.GetName()))
+}
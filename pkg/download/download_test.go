@@ -0,0 +1,232 @@
+package download_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/download"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// server serves files, forbidding downloads without a valid signature.
+type server struct {
+	mu    sync.Mutex
+	hits  map[string]int
+	files map[string]string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hits[r.URL.Path]++
+
+	content, ok := s.files[r.URL.Path]
+	switch {
+	case !ok:
+		http.NotFound(w, r)
+	case r.URL.Query().Get("sig") == "expired":
+		http.Error(w, "expired", http.StatusForbidden)
+	default:
+		fmt.Fprint(w, content)
+	}
+}
+
+// memClient returns blocks and pages with fresh URLs.
+type memClient struct {
+	mu     sync.Mutex
+	url    string
+	blocks map[notion.Id]notion.Block
+	pages  map[notion.Id]notion.Page
+	calls  int
+}
+
+func (c *memClient) GetNotionBlock(_ context.Context, id notion.Id) (*notion.Block, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+
+	b, ok := c.blocks[id]
+	if !ok {
+		return nil, fmt.Errorf("block %s not found", id)
+	}
+
+	return &b, nil
+}
+
+func (c *memClient) GetNotionPage(_ context.Context, id notion.Id) (*notion.Page, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+
+	p, ok := c.pages[id]
+	if !ok {
+		return nil, fmt.Errorf("page %s not found", id)
+	}
+
+	return &p, nil
+}
+
+func hosted(url, sig string, expiry time.Time) notion.File {
+	return notion.File{Type: notion.FileTypeFile, File: &notion.NotionFile{Url: url + "?sig=" + sig, ExpiryTime: expiry}}
+}
+
+func hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloader(t *testing.T) {
+	t.Parallel()
+
+	srv := &server{hits: map[string]int{}, files: map[string]string{
+		"/a/image.PNG":  "image",
+		"/b/report.pdf": "report",
+		"/c/copy.png":   "image",
+		"/d/cover.jpg":  "cover",
+	}}
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	cli := &memClient{
+		blocks: map[notion.Id]notion.Block{
+			"expired-block": {Type: notion.BlockTypeImage, Image: &notion.FileWithCaption{
+				Type: notion.FileWithCaptionTypeFile, File: hosted(ts.URL+"/a/image.PNG", "fresh", future).File,
+			}},
+		},
+		pages: map[notion.Id]notion.Page{
+			"page": {Properties: notion.PropertyValueMap{"Files": {
+				Type:  notion.PropertyTypeFiles,
+				Files: &notion.Files{hosted(ts.URL+"/b/report.pdf", "fresh", future)},
+			}}},
+		},
+	}
+
+	external := notion.File{Type: notion.FileTypeExternal, External: &notion.ExternalFile{Url: ts.URL + "/d/cover.jpg"}}
+
+	image := notion.FileWithCaption{Type: notion.FileWithCaptionTypeFile, File: hosted(ts.URL+"/a/image.PNG", "expired", past).File}
+
+	refs := []download.Ref{
+		// expired before downloading
+		download.BlockFile("expired-block", image),
+		// forbidden although not expired yet
+		download.PropertyFile("page", "Files", hosted(ts.URL+"/b/report.pdf", "expired", future)),
+		// same content as the image
+		download.PageCover("page", hosted(ts.URL+"/c/copy.png", "fresh", future)),
+		download.PageCover("other", external),
+		// same file as before
+		download.PageCover("other", external),
+		download.PageCover("missing", hosted(ts.URL+"/e/missing.txt", "fresh", future)),
+	}
+
+	fs := afero.NewMemMapFs()
+
+	results, err := download.New(cli, fs, download.Options{Dir: "files", Concurrency: 2}).
+		Download(context.Background(), refs...)
+	require.Len(t, results, len(refs))
+
+	assert.EqualError(t, err, "downloading missing.txt of missing: unexpected response: 404 Not Found")
+
+	for i, want := range []string{
+		"files/" + hash("image") + ".png",
+		"files/" + hash("report") + ".pdf",
+		"files/" + hash("image") + ".png",
+		"files/" + hash("cover") + ".jpg",
+		"files/" + hash("cover") + ".jpg",
+		"",
+	} {
+		assert.Equal(t, want, results[i].Path, "result %d", i)
+		assert.Equal(t, refs[i].Owner, results[i].Ref.Owner)
+	}
+
+	assert.Error(t, results[5].Err)
+
+	b, err := afero.ReadFile(fs, results[1].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "report", string(b))
+
+	entries, err := afero.ReadDir(fs, "files")
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "temporary files should be removed and content stored once")
+
+	assert.Equal(t, 1, srv.hits["/a/image.PNG"], "expired URL should be refreshed before downloading")
+	assert.Equal(t, 2, srv.hits["/b/report.pdf"], "forbidden download should be retried")
+	assert.Equal(t, 1, srv.hits["/d/cover.jpg"], "same file should be downloaded once")
+	assert.Equal(t, 2, cli.calls)
+}
+
+func TestDownloader_Literal(t *testing.T) {
+	t.Parallel()
+
+	ref := download.Ref{
+		File:  hosted("https://example.com/a.png", "expired", time.Now().Add(-time.Hour)),
+		Owner: "page",
+	}
+
+	results, err := download.New(&memClient{}, afero.NewMemMapFs(), download.Options{}).
+		Download(context.Background(), ref)
+	require.Len(t, results, 1)
+	assert.EqualError(t, err, "downloading a.png of page: refreshing URL: reference has no owner lookup")
+}
+
+func TestPageFiles(t *testing.T) {
+	t.Parallel()
+
+	emoji := "🎉"
+	file := notion.File{Type: notion.FileTypeExternal, External: &notion.ExternalFile{Url: "https://example.com/a.png"}}
+
+	p := notion.Page{
+		Id:    "page",
+		Icon:  &notion.Icon{Type: notion.IconTypeEmoji, Emoji: &emoji},
+		Cover: &file,
+		Properties: notion.PropertyValueMap{
+			"B":    {Type: notion.PropertyTypeFiles, Files: &notion.Files{file}},
+			"A":    {Type: notion.PropertyTypeFiles, Files: &notion.Files{file, file}},
+			"Name": {Type: notion.PropertyTypeTitle, Title: notion.NewRichTextsP("page")},
+		},
+	}
+
+	refs := download.PageFiles(p)
+	assert.Len(t, refs, 4)
+
+	p.Icon = &notion.Icon{Type: notion.IconTypeExternal, External: file.External}
+	assert.Len(t, download.PageFiles(p), 5)
+
+	blocks := notion.Blocks{
+		{Id: "image", Type: notion.BlockTypeImage, Image: &notion.FileWithCaption{
+			Type: notion.FileWithCaptionTypeExternal, External: file.External,
+		}},
+		{Id: "text", Type: notion.BlockTypeParagraph},
+	}
+
+	refs = download.BlockFiles(blocks)
+	require.Len(t, refs, 1)
+	assert.Equal(t, notion.Id("image"), refs[0].Owner)
+}
+
+func TestDownloader_FileUpload(t *testing.T) {
+	t.Parallel()
+
+	ref := download.PageCover("page", notion.FileUpload{Id: "upload"}.File())
+
+	results, err := download.New(&memClient{}, afero.NewMemMapFs(), download.Options{}).
+		Download(context.Background(), ref)
+	require.Len(t, results, 1)
+	assert.EqualError(t, err, "downloading upload of page: file uploads can only be downloaded once they are attached")
+}
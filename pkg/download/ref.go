@@ -0,0 +1,173 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// errNoLookup is returned when the URL of a reference needs to be refreshed,
+// but the reference was not created by one of the functions of this package.
+var errNoLookup = errors.New("reference has no owner lookup")
+
+// Ref is a file together with the block or page it belongs to,
+// which is fetched again to get a fresh URL once the URL of a file hosted by Notion expired.
+// Only references returned by the functions of this package can be refreshed.
+type Ref struct {
+	// File is the file to download.
+	File notion.File
+	// Owner is the ID of the block or page the file belongs to.
+	Owner notion.Id
+
+	// locate finds the file in a fresh copy of its owner.
+	locate func(ctx context.Context, cli Client) (notion.File, error)
+}
+
+// refresh finds the file in a fresh copy of its owner.
+func (r Ref) refresh(ctx context.Context, cli Client) (notion.File, error) {
+	if r.locate == nil {
+		return notion.File{}, errNoLookup
+	}
+
+	return r.locate(ctx, cli)
+}
+
+// BlockFile returns a reference to the file of an image, video, audio, file or PDF block.
+func BlockFile(id notion.Id, f notion.FileWithCaption) Ref {
+	return Ref{File: f.GetFile(), Owner: id, locate: func(ctx context.Context, cli Client) (notion.File, error) {
+		b, err := cli.GetNotionBlock(ctx, id)
+		if err != nil {
+			return notion.File{}, err
+		}
+
+		f := blockFile(*b)
+		if f == nil {
+			return notion.File{}, fmt.Errorf("%s block %s has no file", b.Type, id)
+		}
+
+		return f.GetFile(), nil
+	}}
+}
+
+// BlockFiles returns references to the files of the blocks.
+func BlockFiles(blocks notion.Blocks) []Ref {
+	refs := []Ref{}
+
+	for _, b := range blocks {
+		if f := blockFile(b); f != nil {
+			refs = append(refs, BlockFile(notion.Id(b.Id), *f))
+		}
+	}
+
+	return refs
+}
+
+// blockFile returns the file of the block or nil if it has none.
+func blockFile(b notion.Block) *notion.FileWithCaption {
+	switch b.Type {
+	case notion.BlockTypeImage:
+		return b.Image
+	case notion.BlockTypeVideo:
+		return b.Video
+	case notion.BlockTypeAudio:
+		return b.Audio
+	case notion.BlockTypeFile:
+		return b.File
+	case notion.BlockTypePdf:
+		return b.Pdf
+	default:
+		return nil
+	}
+}
+
+// PageIcon returns a reference to the icon of a page, which must not be an emoji.
+func PageIcon(id notion.Id, ic notion.Icon) Ref {
+	return Ref{File: iconFile(ic), Owner: id, locate: func(ctx context.Context, cli Client) (notion.File, error) {
+		p, err := cli.GetNotionPage(ctx, id)
+		if err != nil {
+			return notion.File{}, err
+		}
+
+		if p.Icon == nil || p.Icon.Type == notion.IconTypeEmoji {
+			return notion.File{}, fmt.Errorf("page %s has no icon file", id)
+		}
+
+		return iconFile(*p.Icon), nil
+	}}
+}
+
+func iconFile(ic notion.Icon) notion.File {
+	return notion.File{External: ic.External, File: ic.File, FileUpload: ic.FileUpload, Type: notion.FileType(ic.Type)}
+}
+
+// PageCover returns a reference to the cover of a page.
+func PageCover(id notion.Id, f notion.File) Ref {
+	return Ref{File: f, Owner: id, locate: func(ctx context.Context, cli Client) (notion.File, error) {
+		p, err := cli.GetNotionPage(ctx, id)
+		if err != nil {
+			return notion.File{}, err
+		}
+
+		if p.Cover == nil {
+			return notion.File{}, fmt.Errorf("page %s has no cover", id)
+		}
+
+		return *p.Cover, nil
+	}}
+}
+
+// PropertyFile returns a reference to a file of a files property of a page.
+// The file is found again by its URL without the signature.
+func PropertyFile(id notion.Id, property string, f notion.File) Ref {
+	want := notion.WithoutQuery(f.URL())
+
+	return Ref{File: f, Owner: id, locate: func(ctx context.Context, cli Client) (notion.File, error) {
+		p, err := cli.GetNotionPage(ctx, id)
+		if err != nil {
+			return notion.File{}, err
+		}
+
+		for _, f := range p.Properties[property].GetFiles() {
+			if notion.WithoutQuery(f.URL()) == want {
+				return f, nil
+			}
+		}
+
+		return notion.File{}, fmt.Errorf("file %s not found in property %q of page %s", want, property, id)
+	}}
+}
+
+// PageFiles returns references to the icon, the cover and the files in files properties of the page.
+func PageFiles(p notion.Page) []Ref {
+	id := notion.Id(p.Id)
+	refs := []Ref{}
+
+	if p.Icon != nil && p.Icon.Type != notion.IconTypeEmoji {
+		refs = append(refs, PageIcon(id, *p.Icon))
+	}
+
+	if p.Cover != nil {
+		refs = append(refs, PageCover(id, *p.Cover))
+	}
+
+	names := []string{}
+
+	for name, prop := range p.Properties {
+		if prop.Type == notion.PropertyTypeFiles {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, f := range p.Properties[name].GetFiles() {
+			refs = append(refs, PropertyFile(id, name, f))
+		}
+	}
+
+	return refs
+}
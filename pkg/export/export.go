@@ -0,0 +1,199 @@
+// Package export writes database entries as CSV, TSV or JSON Lines.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Format is the format of an export.
+type Format string
+
+// All supported formats.
+const (
+	FormatCSV   Format = "csv"
+	FormatTSV   Format = "tsv"
+	FormatJSONL Format = "jsonl"
+)
+
+// Options define what is exported and how.
+type Options struct {
+	// Columns are the names of the properties to export, in order.
+	// Defaults to all properties as returned by Columns.
+	// Set them to export the properties in the order they are shown in Notion.
+	Columns []string
+
+	// Headers maps property names to the headers of their columns, or keys in JSON Lines.
+	// Defaults to the property names.
+	Headers map[string]string
+
+	// Titles looks up related pages to export relations by their titles.
+	// By default, relations are exported as IDs.
+	Titles notion.PageLookup
+
+	// Separator joins multiple values of a property in CSV and TSV. Defaults to ", ".
+	Separator string
+}
+
+// Writer writes database entries in one of the formats.
+type Writer struct {
+	format  Format
+	columns []string
+	headers []string
+	opts    Options
+
+	csv  *csv.Writer
+	json *json.Encoder
+
+	wroteHeader bool
+}
+
+// NewWriter returns a writer for entries of the database.
+func NewWriter(w io.Writer, format Format, db notion.Database, opts Options) (*Writer, error) {
+	if opts.Separator == "" {
+		opts.Separator = ", "
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = Columns(db.Properties)
+	}
+
+	headers := make([]string, len(columns))
+
+	for i, name := range columns {
+		if _, ok := db.Properties[name]; !ok {
+			return nil, fmt.Errorf("database %s has no property %q", db.Id, name)
+		}
+
+		headers[i] = name
+		if h, ok := opts.Headers[name]; ok {
+			headers[i] = h
+		}
+	}
+
+	ew := &Writer{format: format, columns: columns, headers: headers, opts: opts}
+
+	switch format {
+	case FormatCSV:
+		ew.csv = csv.NewWriter(w)
+	case FormatTSV:
+		ew.csv = csv.NewWriter(w)
+		ew.csv.Comma = '\t'
+	case FormatJSONL:
+		ew.json = json.NewEncoder(w)
+		ew.json.SetEscapeHTML(false)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	return ew, nil
+}
+
+// Columns returns the names of all properties, the title first and the others sorted by name.
+//
+// The order of the properties in Notion cannot be recovered: the API returns them as a JSON object
+// without a defined order and PropertyMetaMap is a map. Use Options.Columns to set the order.
+func Columns(props notion.PropertyMetaMap) []string {
+	columns := make([]string, 0, len(props))
+
+	for name, prop := range props {
+		if prop.Type != notion.PropertyTypeTitle {
+			columns = append(columns, name)
+		}
+	}
+
+	sort.Strings(columns)
+
+	for name, prop := range props {
+		if prop.Type == notion.PropertyTypeTitle {
+			columns = append([]string{name}, columns...)
+		}
+	}
+
+	return columns
+}
+
+// Write writes one entry. The header is written before the first entry.
+func (w *Writer) Write(p notion.Page) error {
+	if w.json != nil {
+		return w.writeJSON(p)
+	}
+
+	if !w.wroteHeader {
+		if err := w.csv.Write(w.headers); err != nil {
+			return err
+		}
+
+		w.wroteHeader = true
+	}
+
+	record := make([]string, len(w.columns))
+
+	for i, name := range w.columns {
+		v, err := Value(p.Properties[name], w.opts.Titles)
+		if err != nil {
+			return fmt.Errorf("property %q of page %s: %w", name, p.Id, err)
+		}
+
+		record[i] = Text(v, w.opts.Separator)
+	}
+
+	return w.csv.Write(record)
+}
+
+func (w *Writer) writeJSON(p notion.Page) error {
+	obj := make(map[string]any, len(w.columns))
+
+	for i, name := range w.columns {
+		v, err := Value(p.Properties[name], w.opts.Titles)
+		if err != nil {
+			return fmt.Errorf("property %q of page %s: %w", name, p.Id, err)
+		}
+
+		obj[w.headers[i]] = v
+	}
+
+	return w.json.Encode(obj)
+}
+
+// Flush writes any buffered data. For CSV and TSV, the header is written
+// even if there were no entries.
+func (w *Writer) Flush() error {
+	if w.csv == nil {
+		return nil
+	}
+
+	if !w.wroteHeader {
+		if err := w.csv.Write(w.headers); err != nil {
+			return err
+		}
+
+		w.wroteHeader = true
+	}
+
+	w.csv.Flush()
+
+	return w.csv.Error()
+}
+
+// Write writes all entries of the database.
+func Write(w io.Writer, format Format, db notion.Database, pages notion.Pages, opts Options) error {
+	ew, err := NewWriter(w, format, db, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pages {
+		if err := ew.Write(p); err != nil {
+			return err
+		}
+	}
+
+	return ew.Flush()
+}
@@ -0,0 +1,114 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/faetools/go-notion-example/fake"
+	"github.com/faetools/go-notion/pkg/export"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const childDatabaseID = "7a3c647e-4c1e-4c27-bf1d-cfb0105e55ce"
+
+func example(t *testing.T) (notion.Database, notion.Pages) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := fake.NotionClient.GetNotionDatabase(ctx, childDatabaseID)
+	require.NoError(t, err)
+
+	entries, err := fake.NotionClient.GetAllDatabaseEntries(ctx, childDatabaseID)
+	require.NoError(t, err)
+
+	// only entry 1 and 2
+	return *db, notion.Pages{entries[6], entries[8]}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	db, entries := example(t)
+
+	all, err := fake.NotionClient.GetAllDatabaseEntries(context.Background(), childDatabaseID)
+	require.NoError(t, err)
+
+	opts := export.Options{
+		Columns: []string{"Name", "A number", "My checkbox", "tags", "some date", "Sub", "Top", "who created it"},
+		Headers: map[string]string{"Name": "Title", "A number": "Number"},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, export.Write(buf, export.FormatCSV, db, entries, opts))
+	assert.Equal(t, `Title,Number,My checkbox,tags,some date,Sub,Top,who created it
+entry 1,31.3,false,"tag 1, tag 2, tag 3",2022-07-30,45d81ac0-bf71-4a31-b096-941dd050f3e1,,Fae Tools
+entry 2,32.7,true,tag 2,2022-08-05T16:00:00+02:00 - 2022-08-12T03:00:00+02:00,,"c85a6329-44c0-4a8f-98d4-0ddd0e756b10, 45d81ac0-bf71-4a31-b096-941dd050f3e1",Fae Tools
+`, buf.String())
+
+	// relations by title
+	opts.Columns = []string{"Name", "Top"}
+	opts.Titles = notion.LookupPages(all)
+	opts.Separator = "|"
+
+	buf.Reset()
+	require.NoError(t, export.Write(buf, export.FormatTSV, db, all[7:8], opts))
+	assert.Equal(t, "Title\tTop\nentry 3\tentry 1\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, export.Write(buf, export.FormatJSONL, db, entries, export.Options{
+		Columns: []string{"Name", "A number", "My checkbox", "tags", "select"},
+	}))
+	assert.Equal(t, `{"A number":31.3,"My checkbox":false,"Name":"entry 1","select":"foo","tags":["tag 1","tag 2","tag 3"]}
+{"A number":32.7,"My checkbox":true,"Name":"entry 2","select":"bar","tags":["tag 2"]}
+`, buf.String())
+
+	// empty exports still have a header
+	buf.Reset()
+	require.NoError(t, export.Write(buf, export.FormatCSV, db, nil, export.Options{Columns: []string{"Name"}}))
+	assert.Equal(t, "Name\n", buf.String())
+
+	_, err = export.NewWriter(buf, export.FormatCSV, db, export.Options{Columns: []string{"Missing"}})
+	assert.EqualError(t, err, `database `+childDatabaseID+` has no property "Missing"`)
+
+	_, err = export.NewWriter(buf, "xlsx", db, export.Options{})
+	assert.EqualError(t, err, `unknown format "xlsx"`)
+}
+
+func TestColumns(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"Name", "Done", "Due"}, export.Columns(notion.PropertyMetaMap{
+		"Due":  {Type: notion.PropertyTypeDate},
+		"Name": {Type: notion.PropertyTypeTitle},
+		"Done": {Type: notion.PropertyTypeCheckbox},
+	}))
+}
+
+func TestValue_Timestamps(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		json string
+		want string
+	}{
+		{`{"id":"a","type":"created_time","created_time":"2024-01-15T09:30:00.000Z"}`, "2024-01-15T09:30:00Z"},
+		{`{"id":"b","type":"last_edited_time","last_edited_time":"2024-02-01T17:00:00.000Z"}`, "2024-02-01T17:00:00Z"},
+	} {
+		var prop notion.PropertyValue
+		require.NoError(t, json.Unmarshal([]byte(tc.json), &prop))
+
+		v, err := export.Value(prop, nil)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, v)
+
+		b, err := json.Marshal(prop)
+		require.NoError(t, err)
+		assert.JSONEq(t, strings.Replace(tc.json, ".000Z", "Z", 1), string(b))
+	}
+}
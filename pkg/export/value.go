@@ -0,0 +1,182 @@
+package export
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Value returns the value of the property as nil, string, float64, bool or []string.
+// Dates are formatted with Date.String, people by name or email and files as URLs.
+// Relations are returned as IDs or, if titles is set, as titles of the related pages.
+func Value(prop notion.PropertyValue, titles notion.PageLookup) (any, error) {
+	switch prop.Type {
+	case notion.PropertyTypeTitle:
+		return prop.GetTitle().Content(), nil
+	case notion.PropertyTypeRichText:
+		return prop.GetRichText().Content(), nil
+	case notion.PropertyTypeNumber:
+		if prop.Number == nil {
+			return nil, nil
+		}
+
+		return *prop.Number, nil
+	case notion.PropertyTypeCheckbox:
+		return prop.GetCheckbox(), nil
+	case notion.PropertyTypeSelect:
+		return optional(prop.GetSelect().Name), nil
+	case notion.PropertyTypeStatus:
+		return optional(prop.GetStatus().Name), nil
+	case notion.PropertyTypeMultiSelect:
+		return prop.GetMultiSelect().GetNames(), nil
+	case notion.PropertyTypeDate:
+		if prop.Date == nil {
+			return nil, nil
+		}
+
+		return prop.Date.String(), nil
+	case notion.PropertyTypeUrl:
+		return optional(prop.GetURL()), nil
+	case notion.PropertyTypeEmail:
+		return optional(prop.GetEmail()), nil
+	case notion.PropertyTypePhoneNumber:
+		return optional(prop.GetPhoneNumber()), nil
+	case notion.PropertyTypePeople:
+		names := []string{}
+		for _, u := range prop.GetPeople() {
+			names = append(names, userName(u))
+		}
+
+		return names, nil
+	case notion.PropertyTypeCreatedBy:
+		return userName(prop.GetCreatedBy()), nil
+	case notion.PropertyTypeLastEditedBy:
+		return userName(prop.GetLastEditedBy()), nil
+	case notion.PropertyTypeCreatedTime:
+		return prop.GetCreatedTime().Format(time.RFC3339), nil
+	case notion.PropertyTypeLastEditedTime:
+		return prop.GetLastEditedTime().Format(time.RFC3339), nil
+	case notion.PropertyTypeUniqueId:
+		if prop.UniqueId == nil {
+			return nil, nil
+		}
+
+		return prop.UniqueId.String(), nil
+	case notion.PropertyTypeFiles:
+		return prop.GetFiles().GetURLs(), nil
+	case notion.PropertyTypeRelation:
+		return relation(prop.GetRelation(), titles)
+	case notion.PropertyTypeFormula:
+		return formulaValue(prop.GetFormula()), nil
+	case notion.PropertyTypeRollup:
+		return rollupValue(prop.GetRollup(), titles)
+	default:
+		return nil, nil
+	}
+}
+
+// Text returns the value as text, joining multiple values with the separator.
+func Text(v any, sep string) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, sep)
+	default:
+		return ""
+	}
+}
+
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// userName returns the name of the user, otherwise the email or the ID.
+func userName(u notion.User) string {
+	switch {
+	case u.Name != nil && *u.Name != "":
+		return *u.Name
+	case u.Person != nil && u.Person.Email != "":
+		return string(u.Person.Email)
+	default:
+		return string(u.Id)
+	}
+}
+
+func relation(refs notion.References, titles notion.PageLookup) ([]string, error) {
+	vals := make([]string, 0, len(refs))
+
+	for _, ref := range refs {
+		if titles == nil {
+			vals = append(vals, string(ref.Id))
+			continue
+		}
+
+		p, err := titles(ref.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		vals = append(vals, p.Title())
+	}
+
+	return vals, nil
+}
+
+func formulaValue(f notion.Formula) any {
+	switch {
+	case f.String != nil:
+		return *f.String
+	case f.Number != nil:
+		return *f.Number
+	case f.Boolean != nil:
+		return *f.Boolean
+	case f.Date != nil:
+		return f.Date.String()
+	default:
+		return nil
+	}
+}
+
+// rollupValue returns the value of a rollup, arrays are flattened into a list of texts.
+func rollupValue(r notion.Rollup, titles notion.PageLookup) (any, error) {
+	switch {
+	case r.String != nil:
+		return *r.String, nil
+	case r.Number != nil:
+		return *r.Number, nil
+	case r.Date != nil:
+		return r.Date.String(), nil
+	case r.Array != nil:
+		vals := []string{}
+
+		for _, item := range *r.Array {
+			v, err := Value(item.PropertyValue(), titles)
+			if err != nil {
+				return nil, err
+			}
+
+			switch v := v.(type) {
+			case []string:
+				vals = append(vals, v...)
+			case nil:
+			default:
+				vals = append(vals, Text(v, ""))
+			}
+		}
+
+		return vals, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,425 @@
+package formula
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+func now(e *env, _ []any) (any, error) {
+	return notion.Date{Start: e.now}, nil
+}
+
+func today(e *env, _ []any) (any, error) {
+	y, m, d := e.now.Date()
+	return notion.Date{Start: time.Date(y, m, d, 0, 0, 0, 0, e.now.Location()), DateOnly: true}, nil
+}
+
+// unit returns the normalized unit of time, e.g. "days" for "day".
+func unit(v any) (string, error) {
+	s, err := toString(v)
+	if err != nil {
+		return "", err
+	}
+
+	s = strings.TrimSuffix(strings.ToLower(s), "s") + "s"
+
+	switch s {
+	case "years", "quarters", "months", "weeks", "days", "hours", "minutes", "seconds", "milliseconds":
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown unit %q", v)
+	}
+}
+
+func addToTime(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "years":
+		return t.AddDate(n, 0, 0)
+	case "quarters":
+		return t.AddDate(0, 3*n, 0)
+	case "months":
+		return t.AddDate(0, n, 0)
+	case "weeks":
+		return t.AddDate(0, 0, 7*n)
+	case "days":
+		return t.AddDate(0, 0, n)
+	case "hours":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "minutes":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "seconds":
+		return t.Add(time.Duration(n) * time.Second)
+	default:
+		return t.Add(time.Duration(n) * time.Millisecond)
+	}
+}
+
+// dateAdd adds (sign 1) or subtracts (sign -1) an amount of a unit of time.
+func dateAdd(sign int) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+
+		d, err := toDate(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := toNumber(args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		u, err := unit(args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		amount := sign * int(n)
+
+		switch u {
+		case "years", "quarters", "months", "weeks", "days":
+		default:
+			// adding less than a day gives the date a time
+			d.DateOnly = false
+		}
+
+		d.Start = addToTime(d.Start, amount, u)
+		if d.End != nil {
+			end := addToTime(*d.End, amount, u)
+			d.End = &end
+		}
+
+		return d, nil
+	}
+}
+
+// dateBetween returns the amount of units between the dates, truncated towards zero.
+func dateBetween(_ *env, args []any) (any, error) {
+	if args[0] == nil || args[1] == nil {
+		return nil, nil
+	}
+
+	a, err := toDate(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := toDate(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := unit(args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	diff := a.Start.Sub(b.Start)
+
+	switch u {
+	case "years":
+		return float64(monthsBetween(a.Start, b.Start) / 12), nil
+	case "quarters":
+		return float64(monthsBetween(a.Start, b.Start) / 3), nil
+	case "months":
+		return float64(monthsBetween(a.Start, b.Start)), nil
+	case "weeks":
+		return math.Trunc(diff.Hours() / 24 / 7), nil
+	case "days":
+		return math.Trunc(diff.Hours() / 24), nil
+	case "hours":
+		return math.Trunc(diff.Hours()), nil
+	case "minutes":
+		return math.Trunc(diff.Minutes()), nil
+	case "seconds":
+		return math.Trunc(diff.Seconds()), nil
+	default:
+		return float64(diff.Milliseconds()), nil
+	}
+}
+
+// monthsBetween returns the number of whole months from b to a.
+func monthsBetween(a, b time.Time) int {
+	months := (a.Year()-b.Year())*12 + int(a.Month()-b.Month())
+
+	anchor := b.AddDate(0, months, 0)
+
+	switch {
+	case months > 0 && anchor.After(a):
+		months--
+	case months < 0 && anchor.Before(a):
+		months++
+	}
+
+	return months
+}
+
+func dateRange(_ *env, args []any) (any, error) {
+	start, err := toDate(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := toDate(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return notion.Date{
+		Start:    start.Start,
+		End:      &end.Start,
+		TimeZone: start.TimeZone,
+		DateOnly: start.DateOnly && end.DateOnly,
+	}, nil
+}
+
+func dateStart(_ *env, args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	d, err := toDate(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return notion.Date{Start: d.Start, TimeZone: d.TimeZone, DateOnly: d.DateOnly}, nil
+}
+
+func dateEnd(_ *env, args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	d, err := toDate(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if d.End == nil {
+		return notion.Date{Start: d.Start, TimeZone: d.TimeZone, DateOnly: d.DateOnly}, nil
+	}
+
+	return notion.Date{Start: *d.End, TimeZone: d.TimeZone, DateOnly: d.DateOnly}, nil
+}
+
+var parseLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+func parseDate(_ *env, args []any) (any, error) {
+	s, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layout := range parseLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return notion.Date{Start: t, DateOnly: layout == "2006-01-02"}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot parse %q as an ISO 8601 date", s)
+}
+
+func timestamp(_ *env, args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	d, err := toDate(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return float64(d.Start.UnixMilli()), nil
+}
+
+func fromTimestamp(_ *env, args []any) (any, error) {
+	ms, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return notion.Date{Start: time.UnixMilli(int64(ms)).UTC()}, nil
+}
+
+func datePart(fn func(time.Time) int) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+
+		d, err := toDate(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return float64(fn(d.Start)), nil
+	}
+}
+
+// weekday returns the day of the week from 1 (Monday) to 7 (Sunday).
+func weekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+
+	return int(t.Weekday())
+}
+
+func isoWeek(t time.Time) int {
+	_, w := t.ISOWeek()
+	return w
+}
+
+// dateTokens are the supported tokens of the moment.js format, longer tokens first.
+var dateTokens = []string{
+	"YYYY", "YY", "Q",
+	"MMMM", "MMM", "MM", "M",
+	"Do", "DD", "D",
+	"dddd", "ddd", "dd", "d",
+	"WW", "W",
+	"HH", "H", "hh", "h",
+	"mm", "m", "ss", "s",
+	"A", "a", "ZZ", "Z",
+}
+
+func formatDate(_ *env, args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	d, err := toDate(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := toString(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return formatMoment(d.Start, layout), nil
+}
+
+// formatMoment formats the time according to a moment.js format, which notion uses.
+// Text in square brackets is not interpreted.
+func formatMoment(t time.Time, layout string) string {
+	var b strings.Builder
+
+outer:
+	for i := 0; i < len(layout); {
+		if layout[i] == '[' {
+			if end := strings.IndexByte(layout[i:], ']'); end > 0 {
+				b.WriteString(layout[i+1 : i+end])
+				i += end + 1
+
+				continue
+			}
+		}
+
+		for _, tok := range dateTokens {
+			if strings.HasPrefix(layout[i:], tok) {
+				b.WriteString(formatToken(t, tok))
+				i += len(tok)
+
+				continue outer
+			}
+		}
+
+		b.WriteByte(layout[i])
+		i++
+	}
+
+	return b.String()
+}
+
+func formatToken(t time.Time, tok string) string {
+	switch tok {
+	case "YYYY":
+		return strconv.Itoa(t.Year())
+	case "YY":
+		return t.Format("06")
+	case "Q":
+		return strconv.Itoa((int(t.Month())-1)/3 + 1)
+	case "MMMM":
+		return t.Format("January")
+	case "MMM":
+		return t.Format("Jan")
+	case "MM":
+		return t.Format("01")
+	case "M":
+		return strconv.Itoa(int(t.Month()))
+	case "Do":
+		return ordinal(t.Day())
+	case "DD":
+		return t.Format("02")
+	case "D":
+		return strconv.Itoa(t.Day())
+	case "dddd":
+		return t.Format("Monday")
+	case "ddd":
+		return t.Format("Mon")
+	case "dd":
+		return t.Format("Mon")[:2]
+	case "d":
+		return strconv.Itoa(int(t.Weekday()))
+	case "WW":
+		return fmt.Sprintf("%02d", isoWeek(t))
+	case "W":
+		return strconv.Itoa(isoWeek(t))
+	case "HH":
+		return t.Format("15")
+	case "H":
+		return strconv.Itoa(t.Hour())
+	case "hh":
+		return t.Format("03")
+	case "h":
+		return t.Format("3")
+	case "mm":
+		return t.Format("04")
+	case "m":
+		return strconv.Itoa(t.Minute())
+	case "ss":
+		return t.Format("05")
+	case "s":
+		return strconv.Itoa(t.Second())
+	case "A":
+		return t.Format("PM")
+	case "a":
+		return t.Format("pm")
+	case "ZZ":
+		return t.Format("-0700")
+	default: // Z
+		return t.Format("-07:00")
+	}
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+
+	return strconv.Itoa(n) + suffix
+}
@@ -0,0 +1,161 @@
+// Package formula parses and evaluates notion formulas locally.
+//
+// Formula values returned by the API may be stale or incomplete, e.g. if a formula refers to more than 25 related pages.
+// This package computes them from the property values of a page instead.
+//
+// Both the operators and functions of the current formula language, like method calls and list functions,
+// and those of the legacy language, like the ternary operator or zero based months, are supported where they don't conflict.
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// ErrUnsupported is returned for functions that are not supported.
+var ErrUnsupported = errors.New("unsupported function")
+
+// Expression is a parsed formula.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse parses the formula.
+func Parse(expr string) (*Expression, error) {
+	root, err := parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing formula %q: %w", expr, err)
+	}
+
+	return &Expression{source: expr, root: root}, nil
+}
+
+// MustParse is like Parse but panics if the formula can't be parsed.
+func MustParse(expr string) *Expression {
+	e, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+// String returns the source of the formula.
+func (e *Expression) String() string { return e.source }
+
+// Evaluate evaluates the formula against the property values of a page.
+func (e *Expression) Evaluate(props notion.PropertyValueMap) (notion.Formula, error) {
+	return e.EvaluateAt(props, time.Now())
+}
+
+// EvaluateAt evaluates the formula as if it was the given time, which is used by now() and today().
+func (e *Expression) EvaluateAt(props notion.PropertyValueMap, now time.Time) (notion.Formula, error) {
+	v, err := e.root.eval(&env{props: props, now: now})
+	if err != nil {
+		return notion.Formula{}, fmt.Errorf("evaluating formula %q: %w", e.source, err)
+	}
+
+	return toFormula(v), nil
+}
+
+// Evaluate parses and evaluates the formula against the property values of a page.
+func Evaluate(expr string, props notion.PropertyValueMap) (notion.Formula, error) {
+	e, err := Parse(expr)
+	if err != nil {
+		return notion.Formula{}, err
+	}
+
+	return e.Evaluate(props)
+}
+
+// EvaluateProperty evaluates the formula property of the database schema with the given name for the page.
+func EvaluateProperty(schema notion.PropertyMetaMap, name string, p notion.Page) (notion.Formula, error) {
+	meta, ok := schema[name]
+	if !ok || meta.Type != notion.PropertyTypeFormula {
+		return notion.Formula{}, fmt.Errorf("%q is not a formula property", name)
+	}
+
+	return Evaluate(meta.GetFormula().Expression, p.Properties)
+}
+
+// env is the environment a formula is evaluated in.
+type env struct {
+	props notion.PropertyValueMap
+	now   time.Time
+	vars  map[string]any
+}
+
+// with returns a new environment with the variable set.
+func (e *env) with(name string, v any) *env {
+	vars := make(map[string]any, len(e.vars)+1)
+	for k, val := range e.vars {
+		vars[k] = val
+	}
+
+	vars[name] = v
+
+	return &env{props: e.props, now: e.now, vars: vars}
+}
+
+func (n literal) eval(*env) (any, error) { return n.value, nil }
+
+func (n ident) eval(e *env) (any, error) {
+	if v, ok := e.vars[n.name]; ok {
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("unknown variable %q at position %d", n.name, n.pos)
+}
+
+func (n call) eval(e *env) (any, error) {
+	if fn, ok := lazyBuiltins[n.name]; ok {
+		v, err := fn(e, n.args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", n.name, err)
+		}
+
+		return v, nil
+	}
+
+	b, ok := builtins[n.name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q at position %d", ErrUnsupported, n.name, n.pos)
+	}
+
+	if len(n.args) < b.min || (b.max >= 0 && len(n.args) > b.max) {
+		return nil, fmt.Errorf("%s: %s at position %d", n.name, arity(b.min, b.max, len(n.args)), n.pos)
+	}
+
+	args := make([]any, len(n.args))
+
+	for i, arg := range n.args {
+		v, err := arg.eval(e)
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = v
+	}
+
+	v, err := b.fn(e, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+
+	return v, nil
+}
+
+func arity(min, max, got int) string {
+	switch {
+	case min == max:
+		return fmt.Sprintf("expected %d arguments, got %d", min, got)
+	case max < 0:
+		return fmt.Sprintf("expected at least %d arguments, got %d", min, got)
+	default:
+		return fmt.Sprintf("expected %d to %d arguments, got %d", min, max, got)
+	}
+}
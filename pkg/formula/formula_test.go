@@ -0,0 +1,201 @@
+package formula_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion-example/fake"
+	"github.com/faetools/go-notion/pkg/formula"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func number(f float64) notion.PropertyValue {
+	return notion.PropertyValue{Type: notion.PropertyTypeNumber, Number: &f}
+}
+
+func text(s string) notion.PropertyValue {
+	return notion.PropertyValue{Type: notion.PropertyTypeRichText, RichText: notion.NewRichTextsP(s)}
+}
+
+var props = notion.PropertyValueMap{
+	"Name":   {Type: notion.PropertyTypeTitle, Title: notion.NewRichTextsP("Write tests")},
+	"Price":  number(12.5),
+	"Amount": number(4),
+	"Empty":  {Type: notion.PropertyTypeNumber},
+	"Note":   text("Hello World"),
+	"Done":   {Type: notion.PropertyTypeCheckbox, Checkbox: new(bool)},
+	"Tags": {Type: notion.PropertyTypeMultiSelect, MultiSelect: &notion.SelectValues{
+		{Name: "go"}, {Name: "notion"}, {Name: "go"},
+	}},
+	"Due": {Type: notion.PropertyTypeDate, Date: &notion.Date{
+		Start: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), DateOnly: true,
+	}},
+	"Meeting": {Type: notion.PropertyTypeDate, Date: &notion.Date{
+		Start: time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC),
+	}},
+}
+
+var now = time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+func eval(t *testing.T, expr string) any {
+	t.Helper()
+
+	f, err := formula.MustParse(expr).EvaluateAt(props, now)
+	require.NoError(t, err, expr)
+
+	switch f.Type {
+	case notion.FormulaTypeNumber:
+		return *f.Number
+	case notion.FormulaTypeBoolean:
+		return *f.Boolean
+	case notion.FormulaTypeDate:
+		return f.Date.String()
+	default:
+		if f.String == nil {
+			return nil
+		}
+
+		return *f.String
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	for expr, want := range map[string]any{
+		// arithmetic
+		`prop("Price") * prop("Amount") + 1`:      51.0,
+		`-2 ^ 2`:                                  -4.0,
+		`2 ^ 3 ^ 2`:                               512.0,
+		`(1 + 2) * 3 % 4`:                         1.0,
+		`prop("Empty") + 1`:                       1.0,
+		`round(prop("Price") / 3, 2)`:             4.17,
+		`max(prop("Price"), 3, [20, 1])`:          20.0,
+		`sum([1, 2, 3]) / length([1, 2, 3])`:      2.0,
+		`toNumber("42") + toNumber(true)`:         43.0,
+		`prop("Price").floor()`:                   12.0,
+		`median([5, 1, 3, 2])`:                    2.5,
+		`empty(prop("Empty"))`:                    true,
+		`prop("Amount") > 3 and not prop("Done")`: true,
+		`prop("Done") || prop("Amount") == 4`:     true,
+
+		// conditions
+		`if(prop("Done"), "done", "open")`:                                     "open",
+		`prop("Amount") > 10 ? "many" : "few"`:                                 "few",
+		`ifs(prop("Price") > 20, "high", prop("Price") > 10, "medium", "low")`: "medium",
+		`let(x, prop("Amount"), x * x)`:                                        16.0,
+		`lets(a, 1, b, a + 1, a + b)`:                                          3.0,
+
+		// text
+		`prop("Name") + " (" + prop("Amount") + ")"`:      "Write tests (4)",
+		`concat("a", "b", "c")`:                           "abc",
+		`join(", ", "a", "b")`:                            "a, b",
+		`prop("Note").lower().replaceAll("o", "0")`:       "hell0 w0rld",
+		`replace(prop("Note"), "(\\w+) (\\w+)", "$2 $1")`: "World Hello",
+		`contains(prop("Note"), "World")`:                 true,
+		`test(prop("Note"), "^H.*d$")`:                    true,
+		`substring(prop("Note"), 6)`:                      "World",
+		`slice(prop("Note"), 0, -6)`:                      "Hello",
+		`length(prop("Note"))`:                            11.0,
+		`padStart(format(7), 3, "0")`:                     "007",
+		`split("a-b-c", "-").at(1)`:                       "b",
+		`format(prop("Due"))`:                             "January 31, 2024",
+		`prop("Note").upper().repeat(2)`:                  "HELLO WORLDHELLO WORLD",
+
+		// lists
+		`prop("Tags").unique().join(" ")`:              "go notion",
+		`prop("Tags").length()`:                        3.0,
+		`prop("Tags").includes("notion")`:              true,
+		`prop("Tags").filter(current != "go").first()`: "notion",
+		`prop("Tags").map(upper(current) + index)`:     "GO0, NOTION1, GO2",
+		`prop("Tags").findIndex(current == "notion")`:  1.0,
+		`prop("Tags").some(current == "rust")`:         false,
+		`prop("Tags").every(length(current) >= 2)`:     true,
+		`count(prop("Tags"), current == "go")`:         2.0,
+		`[3, 1, 2].sort().reverse()`:                   "3, 2, 1",
+		`prop("Tags")`:                                 "go, notion, go",
+
+		// dates
+		`dateAdd(prop("Due"), 1, "months")`:                        "2024-03-02",
+		`dateSubtract(prop("Due"), 2, "days")`:                     "2024-01-29",
+		`dateBetween(prop("Due"), now(), "days")`:                  15.0,
+		`dateBetween(prop("Meeting"), prop("Due"), "months")`:      1.0,
+		`dateBetween(now(), prop("Meeting"), "weeks")`:             -7.0,
+		`formatDate(prop("Meeting"), "dddd, MMMM Do YYYY h:mm A")`: "Tuesday, March 5th 2024 2:30 PM",
+		`formatDate(prop("Due"), "[Week] W, YYYY-MM-DD")`:          "Week 5, 2024-01-31",
+		`today()`:             "2024-01-15",
+		`prop("Due") > now()`: true,
+		`day(prop("Due")) + month(prop("Due")) + year(prop("Due"))`: 2028.0,
+		`dateRange(now(), prop("Due")).dateEnd()`:                   "2024-01-31T00:00:00Z",
+		`dateAdd(prop("Due"), 3, "hours")`:                          "2024-01-31T03:00:00Z",
+		`timestamp(fromTimestamp(86400000))`:                        86400000.0,
+		`parseDate("2024-02-29").date()`:                            29.0,
+	} {
+		assert.Equal(t, want, eval(t, expr), expr)
+	}
+}
+
+func TestEvaluate_Errors(t *testing.T) {
+	t.Parallel()
+
+	for expr, want := range map[string]string{
+		`prop("Missing")`:            `prop: unknown property "Missing"`,
+		`median(prop("Name"))`:       "median: expected number but got text",
+		`prop("Note") - 1`:           "subtract: expected number but got text",
+		`foo(1)`:                     `unsupported function "foo" at position 0`,
+		`if(true, 1)`:                "if: expected an odd number of at least 3 arguments, got 2",
+		`abs(1, 2)`:                  "abs: expected 1 arguments, got 2 at position 0",
+		`1 / 0`:                      "divide: division by zero",
+		`dateAdd(now(), 1, "eons")`:  `dateAdd: unknown unit "eons"`,
+		`x + 1`:                      `unknown variable "x" at position 0`,
+		`repeat("ab", 1e18)`:         "repeat: count 1e+18 exceeds 1048576",
+		`repeat("ab", sqrt(-1))`:     "repeat: invalid count NaN",
+		`repeat("ab", -1)`:           "repeat: invalid count -1",
+		`repeat("ab", 600000)`:       "repeat: result would be longer than 1048576 bytes",
+		`padStart("a", 1e300, "-")`:  "padStart: count 1e+300 exceeds 1048576",
+		`padEnd("a", 1000000, "ab")`: "padEnd: result would be longer than 1048576 bytes",
+	} {
+		_, err := formula.MustParse(expr).EvaluateAt(props, now)
+		assert.EqualError(t, err, fmt.Sprintf("evaluating formula %q: %s", expr, want))
+	}
+
+	_, err := formula.Evaluate(`foo(1)`, props)
+	assert.ErrorIs(t, err, formula.ErrUnsupported)
+
+	for expr, want := range map[string]string{
+		`1 +`:           "unexpected end of formula at position 3",
+		`(1 + 2`:        `expected ")" but got end of formula at position 6`,
+		`"unterminated`: "unterminated string at position 0",
+		`1 # 2`:         `unexpected character '#' at position 2`,
+		`1 + ٣`:         `unexpected character '٣' at position 4`,
+	} {
+		_, err := formula.Parse(expr)
+		assert.EqualError(t, err, fmt.Sprintf("parsing formula %q: %s", expr, want))
+	}
+}
+
+func TestEvaluateProperty(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db, err := fake.NotionClient.GetNotionDatabase(ctx, "7a3c647e-4c1e-4c27-bf1d-cfb0105e55ce")
+	require.NoError(t, err)
+
+	p, err := fake.NotionClient.GetNotionPage(ctx, "20c5eed2-59ea-476e-af69-da0ae68a084d")
+	require.NoError(t, err)
+
+	// the formulas of the example database give the same results as notion
+	for _, name := range []string{"formula", "formula number", "Property checkbox", "date formula"} {
+		got, err := formula.EvaluateProperty(db.Properties, name, *p)
+		require.NoError(t, err)
+		assert.Equal(t, p.Properties[name].GetFormula(), got, name)
+	}
+
+	_, err = formula.EvaluateProperty(db.Properties, "Name", *p)
+	assert.EqualError(t, err, `"Name" is not a formula property`)
+}
@@ -0,0 +1,1003 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxTextLength is the maximum length in bytes of text created by repeat, padStart and padEnd.
+const maxTextLength = 1 << 20
+
+var errDivisionByZero = errors.New("division by zero")
+
+// builtin is a function whose arguments are evaluated before it is called.
+type builtin struct {
+	// min and max are the number of arguments, max is negative for variadic functions
+	min, max int
+	fn       func(e *env, args []any) (any, error)
+}
+
+// lazyBuiltin is a function that evaluates its arguments itself, e.g. to short circuit.
+type lazyBuiltin func(e *env, args []node) (any, error)
+
+var (
+	builtins     map[string]builtin
+	lazyBuiltins map[string]lazyBuiltin
+)
+
+func init() {
+	builtins = map[string]builtin{
+		"prop": {1, 1, prop},
+
+		// logic and comparison
+		"not":       {1, 1, unaryBool(func(b bool) bool { return !b })},
+		"empty":     {1, 1, func(_ *env, args []any) (any, error) { return isEmpty(args[0]), nil }},
+		"equal":     {2, 2, func(_ *env, args []any) (any, error) { return equal(args[0], args[1]), nil }},
+		"unequal":   {2, 2, func(_ *env, args []any) (any, error) { return !equal(args[0], args[1]), nil }},
+		"larger":    {2, 2, comparison(func(c int) bool { return c > 0 })},
+		"largerEq":  {2, 2, comparison(func(c int) bool { return c >= 0 })},
+		"smaller":   {2, 2, comparison(func(c int) bool { return c < 0 })},
+		"smallerEq": {2, 2, comparison(func(c int) bool { return c <= 0 })},
+
+		// math
+		"add":        {2, 2, add},
+		"subtract":   {2, 2, arithmetic(func(a, b float64) (float64, error) { return a - b, nil })},
+		"multiply":   {2, 2, arithmetic(func(a, b float64) (float64, error) { return a * b, nil })},
+		"divide":     {2, 2, arithmetic(divide)},
+		"mod":        {2, 2, arithmetic(mod)},
+		"pow":        {2, 2, arithmetic(func(a, b float64) (float64, error) { return math.Pow(a, b), nil })},
+		"unaryMinus": {1, 1, unaryNumber(func(x float64) float64 { return -x })},
+		"unaryPlus":  {1, 1, func(_ *env, args []any) (any, error) { return convertToNumber(args[0]) }},
+		"abs":        {1, 1, unaryNumber(math.Abs)},
+		"ceil":       {1, 1, unaryNumber(math.Ceil)},
+		"floor":      {1, 1, unaryNumber(math.Floor)},
+		"sqrt":       {1, 1, unaryNumber(math.Sqrt)},
+		"cbrt":       {1, 1, unaryNumber(math.Cbrt)},
+		"exp":        {1, 1, unaryNumber(math.Exp)},
+		"ln":         {1, 1, unaryNumber(math.Log)},
+		"log10":      {1, 1, unaryNumber(math.Log10)},
+		"log2":       {1, 1, unaryNumber(math.Log2)},
+		"sign":       {1, 1, unaryNumber(sign)},
+		"round":      {1, 2, round},
+		"min":        {1, -1, aggregate(minimum)},
+		"max":        {1, -1, aggregate(maximum)},
+		"sum":        {1, -1, aggregate(sum)},
+		"mean":       {1, -1, aggregate(mean)},
+		"median":     {1, -1, aggregate(median)},
+		"pi":         {0, 0, func(*env, []any) (any, error) { return math.Pi, nil }},
+		"e":          {0, 0, func(*env, []any) (any, error) { return math.E, nil }},
+		"toNumber":   {1, 1, func(_ *env, args []any) (any, error) { return convertToNumber(args[0]) }},
+
+		// text
+		"concat":     {1, -1, concat},
+		"join":       {1, -1, join},
+		"length":     {1, 1, length},
+		"contains":   {2, 2, contains},
+		"test":       {2, 2, test},
+		"match":      {2, 2, match},
+		"replace":    {3, 3, replace(false)},
+		"replaceAll": {3, 3, replace(true)},
+		"lower":      {1, 1, unaryString(strings.ToLower)},
+		"upper":      {1, 1, unaryString(strings.ToUpper)},
+		"trim":       {1, 1, unaryString(strings.TrimSpace)},
+		"unstyle":    {1, 1, unaryString(func(s string) string { return s })},
+		"style":      {1, -1, func(_ *env, args []any) (any, error) { return toString(args[0]) }},
+		"link":       {2, 2, func(_ *env, args []any) (any, error) { return toString(args[0]) }},
+		"repeat":     {2, 2, repeat},
+		"padStart":   {3, 3, pad(true)},
+		"padEnd":     {3, 3, pad(false)},
+		"split":      {2, 2, split},
+		"substring":  {2, 3, substring},
+		"format":     {1, 1, func(_ *env, args []any) (any, error) { return format(args[0]), nil }},
+
+		// lists
+		"list":     {0, -1, func(_ *env, args []any) (any, error) { return args, nil }},
+		"at":       {2, 2, at},
+		"first":    {1, 1, func(e *env, args []any) (any, error) { return at(e, []any{args[0], 0.0}) }},
+		"last":     {1, 1, func(e *env, args []any) (any, error) { return at(e, []any{args[0], -1.0}) }},
+		"slice":    {2, 3, slice},
+		"sort":     {1, 1, sortList},
+		"reverse":  {1, 1, reverse},
+		"unique":   {1, 1, unique},
+		"includes": {2, 2, includes},
+		"flat":     {1, 1, func(_ *env, args []any) (any, error) { return flatten(args), nil }},
+
+		// dates
+		"now":           {0, 0, now},
+		"today":         {0, 0, today},
+		"dateAdd":       {3, 3, dateAdd(1)},
+		"dateSubtract":  {3, 3, dateAdd(-1)},
+		"dateBetween":   {3, 3, dateBetween},
+		"dateRange":     {2, 2, dateRange},
+		"dateStart":     {1, 1, dateStart},
+		"dateEnd":       {1, 1, dateEnd},
+		"formatDate":    {2, 2, formatDate},
+		"parseDate":     {1, 1, parseDate},
+		"timestamp":     {1, 1, timestamp},
+		"fromTimestamp": {1, 1, fromTimestamp},
+		"minute":        {1, 1, datePart(func(d time.Time) int { return d.Minute() })},
+		"hour":          {1, 1, datePart(func(d time.Time) int { return d.Hour() })},
+		"day":           {1, 1, datePart(weekday)},
+		"date":          {1, 1, datePart(func(d time.Time) int { return d.Day() })},
+		"week":          {1, 1, datePart(isoWeek)},
+		"month":         {1, 1, datePart(func(d time.Time) int { return int(d.Month()) })},
+		"year":          {1, 1, datePart(func(d time.Time) int { return d.Year() })},
+	}
+
+	lazyBuiltins = map[string]lazyBuiltin{
+		"if":        ifs,
+		"ifs":       ifs,
+		"and":       and,
+		"or":        or,
+		"let":       lets,
+		"lets":      lets,
+		"map":       mapList,
+		"filter":    filter,
+		"find":      find,
+		"findIndex": findIndex,
+		"some":      some,
+		"every":     every,
+		"count":     count,
+	}
+}
+
+func prop(e *env, args []any) (any, error) {
+	name, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := e.props[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown property %q", name)
+	}
+
+	return propertyValue(p), nil
+}
+
+func unaryBool(fn func(bool) bool) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		b, err := toBool(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(b), nil
+	}
+}
+
+func unaryNumber(fn func(float64) float64) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		x, err := toNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(x), nil
+	}
+}
+
+func unaryString(fn func(string) string) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		s, err := toString(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(s), nil
+	}
+}
+
+func comparison(fn func(int) bool) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		c, err := compare(args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(c), nil
+	}
+}
+
+func arithmetic(fn func(a, b float64) (float64, error)) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		a, err := toNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := toNumber(args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(a, b)
+	}
+}
+
+// add adds numbers or concatenates text if one of the values is text.
+func add(e *env, args []any) (any, error) {
+	_, aStr := args[0].(string)
+	_, bStr := args[1].(string)
+
+	if aStr || bStr {
+		return format(args[0]) + format(args[1]), nil
+	}
+
+	return arithmetic(func(a, b float64) (float64, error) { return a + b, nil })(e, args)
+}
+
+func divide(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errDivisionByZero
+	}
+
+	return a / b, nil
+}
+
+func mod(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errDivisionByZero
+	}
+
+	return math.Mod(a, b), nil
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func round(_ *env, args []any) (any, error) {
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	places := 0.0
+
+	if len(args) == 2 {
+		if places, err = toNumber(args[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	factor := math.Pow(10, places)
+
+	// round half up like JavaScript does
+	return math.Floor(x*factor+0.5) / factor, nil
+}
+
+func convertToNumber(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, float64:
+		return v, nil
+	case bool:
+		return boolNumber(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, nil
+		}
+
+		return f, nil
+	default:
+		d, err := toDate(v)
+		if err != nil {
+			return nil, expected("number, text, boolean or date", v)
+		}
+
+		return float64(d.Start.UnixMilli()), nil
+	}
+}
+
+// flatten returns all elements of the arguments, with lists being expanded.
+func flatten(args []any) []any {
+	var all []any
+
+	for _, arg := range args {
+		if list, ok := arg.([]any); ok {
+			all = append(all, flatten(list)...)
+			continue
+		}
+
+		all = append(all, arg)
+	}
+
+	return all
+}
+
+func aggregate(fn func([]float64) float64) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		var nums []float64
+
+		for _, v := range flatten(args) {
+			if v == nil {
+				continue
+			}
+
+			n, err := toNumber(v)
+			if err != nil {
+				return nil, err
+			}
+
+			nums = append(nums, n)
+		}
+
+		if len(nums) == 0 {
+			return nil, nil
+		}
+
+		return fn(nums), nil
+	}
+}
+
+func minimum(nums []float64) float64 {
+	m := nums[0]
+	for _, n := range nums[1:] {
+		m = math.Min(m, n)
+	}
+
+	return m
+}
+
+func maximum(nums []float64) float64 {
+	m := nums[0]
+	for _, n := range nums[1:] {
+		m = math.Max(m, n)
+	}
+
+	return m
+}
+
+func sum(nums []float64) float64 {
+	s := 0.0
+	for _, n := range nums {
+		s += n
+	}
+
+	return s
+}
+
+func mean(nums []float64) float64 { return sum(nums) / float64(len(nums)) }
+
+func median(nums []float64) float64 {
+	sorted := append([]float64{}, nums...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+
+	return sorted[mid]
+}
+
+// concat concatenates lists or, in legacy formulas, text.
+func concat(_ *env, args []any) (any, error) {
+	if _, ok := args[0].([]any); ok {
+		all := []any{}
+
+		for _, arg := range args {
+			list, err := toList(arg)
+			if err != nil {
+				return nil, err
+			}
+
+			all = append(all, list...)
+		}
+
+		return all, nil
+	}
+
+	var b strings.Builder
+
+	for _, arg := range args {
+		s, err := toString(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		b.WriteString(s)
+	}
+
+	return b.String(), nil
+}
+
+// join joins a list with a separator, i.e. join(list, separator),
+// or in legacy formulas text arguments, i.e. join(separator, a, b, ...).
+func join(_ *env, args []any) (any, error) {
+	if list, ok := args[0].([]any); ok {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+		}
+
+		sep, err := toString(args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = format(item)
+		}
+
+		return strings.Join(parts, sep), nil
+	}
+
+	sep, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(args)-1)
+
+	for i, arg := range args[1:] {
+		if parts[i], err = toString(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	return strings.Join(parts, sep), nil
+}
+
+func length(_ *env, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return 0.0, nil
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []any:
+		return float64(len(v)), nil
+	default:
+		return nil, expected("text or list", v)
+	}
+}
+
+func contains(e *env, args []any) (any, error) {
+	if _, ok := args[0].([]any); ok {
+		return includes(e, args)
+	}
+
+	s, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := toString(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Contains(s, sub), nil
+}
+
+func includes(_ *env, args []any) (any, error) {
+	list, err := toList(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range list {
+		if equal(item, args[1]) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// regex returns the text and the compiled regular expression of the arguments.
+func regex(args []any) (string, *regexp.Regexp, error) {
+	s := format(args[0])
+
+	pattern, err := toString(args[1])
+	if err != nil {
+		return "", nil, err
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return s, re, nil
+}
+
+func test(_ *env, args []any) (any, error) {
+	s, re, err := regex(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return re.MatchString(s), nil
+}
+
+func match(_ *env, args []any) (any, error) {
+	s, re, err := regex(args)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []any{}
+	for _, m := range re.FindAllString(s, -1) {
+		matches = append(matches, m)
+	}
+
+	return matches, nil
+}
+
+func replace(all bool) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		s, re, err := regex(args)
+		if err != nil {
+			return nil, err
+		}
+
+		repl, err := toString(args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		// JavaScript uses $1 for groups, which Go understands as well
+		if all {
+			return re.ReplaceAllString(s, repl), nil
+		}
+
+		loc := re.FindStringSubmatchIndex(s)
+		if loc == nil {
+			return s, nil
+		}
+
+		return s[:loc[0]] + string(re.ExpandString(nil, repl, s, loc)) + s[loc[1]:], nil
+	}
+}
+
+func repeat(_ *env, args []any) (any, error) {
+	s := format(args[0])
+
+	n, err := toCount(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s) > 0 && n > maxTextLength/len(s) {
+		return nil, fmt.Errorf("result would be longer than %d bytes", maxTextLength)
+	}
+
+	return strings.Repeat(s, n), nil
+}
+
+// toCount returns the number as a count, which must be finite and not negative.
+func toCount(v any) (int, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return 0, err
+	}
+
+	if math.IsNaN(n) || math.IsInf(n, 0) || n < 0 {
+		return 0, fmt.Errorf("invalid count %v", n)
+	}
+
+	if n > maxTextLength {
+		return 0, fmt.Errorf("count %v exceeds %d", n, maxTextLength)
+	}
+
+	return int(n), nil
+}
+
+func pad(start bool) func(*env, []any) (any, error) {
+	return func(_ *env, args []any) (any, error) {
+		s := format(args[0])
+
+		n, err := toCount(args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := toString(args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		missing := n - utf8.RuneCountInString(s)
+		if missing <= 0 || p == "" {
+			return s, nil
+		}
+
+		if missing > maxTextLength/len(p) {
+			return nil, fmt.Errorf("result would be longer than %d bytes", maxTextLength)
+		}
+
+		padding := []rune(strings.Repeat(p, missing))[:missing]
+		if start {
+			return string(padding) + s, nil
+		}
+
+		return s + string(padding), nil
+	}
+}
+
+func split(_ *env, args []any) (any, error) {
+	s, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	sep, err := toString(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []any{}
+	for _, p := range strings.Split(s, sep) {
+		parts = append(parts, p)
+	}
+
+	return parts, nil
+}
+
+// bounds returns the start and end index of a slice of a sequence with the given length.
+// Negative indices count from the end.
+func bounds(n int, args []any) (int, int, error) {
+	idx := []int{0, n}
+
+	for i, arg := range args {
+		f, err := toNumber(arg)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		idx[i] = int(f)
+		if idx[i] < 0 {
+			idx[i] += n
+		}
+
+		idx[i] = min(max(idx[i], 0), n)
+	}
+
+	return idx[0], max(idx[0], idx[1]), nil
+}
+
+func substring(_ *env, args []any) (any, error) {
+	s, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(s)
+
+	start, end, err := bounds(len(runes), args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return string(runes[start:end]), nil
+}
+
+func slice(e *env, args []any) (any, error) {
+	if _, ok := args[0].(string); ok {
+		return substring(e, args)
+	}
+
+	list, err := toList(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := bounds(len(list), args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]any{}, list[start:end]...), nil
+}
+
+func at(_ *env, args []any) (any, error) {
+	list, err := toList(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := toNumber(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	i := int(f)
+	if i < 0 {
+		i += len(list)
+	}
+
+	if i < 0 || i >= len(list) {
+		return nil, nil
+	}
+
+	return list[i], nil
+}
+
+func sortList(_ *env, args []any) (any, error) {
+	list, err := toList(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := append([]any{}, list...)
+
+	var sortErr error
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		c, err := compare(sorted[i], sorted[j])
+		if err != nil {
+			sortErr = err
+		}
+
+		return c < 0
+	})
+
+	return sorted, sortErr
+}
+
+func reverse(_ *env, args []any) (any, error) {
+	list, err := toList(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	reversed := make([]any, len(list))
+	for i, item := range list {
+		reversed[len(list)-1-i] = item
+	}
+
+	return reversed, nil
+}
+
+func unique(_ *env, args []any) (any, error) {
+	list, err := toList(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	uniq := []any{}
+
+outer:
+	for _, item := range list {
+		for _, u := range uniq {
+			if equal(item, u) {
+				continue outer
+			}
+		}
+
+		uniq = append(uniq, item)
+	}
+
+	return uniq, nil
+}
+
+// ifs evaluates if(cond, then, else) as well as ifs(cond1, value1, cond2, value2, ..., else).
+func ifs(e *env, args []node) (any, error) {
+	if len(args) < 3 || len(args)%2 == 0 {
+		return nil, fmt.Errorf("expected an odd number of at least 3 arguments, got %d", len(args))
+	}
+
+	for i := 0; i+1 < len(args); i += 2 {
+		cond, err := evalBool(e, args[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if cond {
+			return args[i+1].eval(e)
+		}
+	}
+
+	return args[len(args)-1].eval(e)
+}
+
+func evalBool(e *env, n node) (bool, error) {
+	v, err := n.eval(e)
+	if err != nil {
+		return false, err
+	}
+
+	return toBool(v)
+}
+
+func and(e *env, args []node) (any, error) {
+	for _, arg := range args {
+		b, err := evalBool(e, arg)
+		if err != nil || !b {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func or(e *env, args []node) (any, error) {
+	for _, arg := range args {
+		b, err := evalBool(e, arg)
+		if err != nil || b {
+			return b, err
+		}
+	}
+
+	return false, nil
+}
+
+// lets evaluates let(name, value, expr) and lets(name1, value1, name2, value2, ..., expr).
+func lets(e *env, args []node) (any, error) {
+	if len(args) < 3 || len(args)%2 == 0 {
+		return nil, fmt.Errorf("expected an odd number of at least 3 arguments, got %d", len(args))
+	}
+
+	for i := 0; i+1 < len(args); i += 2 {
+		name, ok := args[i].(ident)
+		if !ok {
+			return nil, fmt.Errorf("argument %d must be a variable name", i+1)
+		}
+
+		v, err := args[i+1].eval(e)
+		if err != nil {
+			return nil, err
+		}
+
+		e = e.with(name.name, v)
+	}
+
+	return args[len(args)-1].eval(e)
+}
+
+// eachItem calls the function with the result of evaluating the expression for every item of the list,
+// which can refer to the item as current and to its position as index. It stops if the function returns false.
+func eachItem(e *env, args []node, fn func(i int, item, result any) (bool, error)) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected 2 arguments, got %d", len(args))
+	}
+
+	v, err := args[0].eval(e)
+	if err != nil {
+		return err
+	}
+
+	list, err := toList(v)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range list {
+		res, err := args[1].eval(e.with("current", item).with("index", float64(i)))
+		if err != nil {
+			return err
+		}
+
+		cont, err := fn(i, item, res)
+		if err != nil || !cont {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mapList(e *env, args []node) (any, error) {
+	mapped := []any{}
+
+	err := eachItem(e, args, func(_ int, _, res any) (bool, error) {
+		mapped = append(mapped, res)
+		return true, nil
+	})
+
+	return mapped, err
+}
+
+func filter(e *env, args []node) (any, error) {
+	filtered := []any{}
+
+	err := eachItem(e, args, func(_ int, item, res any) (bool, error) {
+		keep, err := toBool(res)
+		if keep {
+			filtered = append(filtered, item)
+		}
+
+		return err == nil, err
+	})
+
+	return filtered, err
+}
+
+func find(e *env, args []node) (any, error) {
+	var found any
+
+	err := eachItem(e, args, func(_ int, item, res any) (bool, error) {
+		ok, err := toBool(res)
+		if ok {
+			found = item
+		}
+
+		return !ok && err == nil, err
+	})
+
+	return found, err
+}
+
+func findIndex(e *env, args []node) (any, error) {
+	found := -1.0
+
+	err := eachItem(e, args, func(i int, _, res any) (bool, error) {
+		ok, err := toBool(res)
+		if ok {
+			found = float64(i)
+		}
+
+		return !ok && err == nil, err
+	})
+
+	return found, err
+}
+
+func some(e *env, args []node) (any, error) {
+	result := false
+
+	err := eachItem(e, args, func(_ int, _, res any) (bool, error) {
+		ok, err := toBool(res)
+		result = ok
+
+		return !ok && err == nil, err
+	})
+
+	return result, err
+}
+
+func every(e *env, args []node) (any, error) {
+	result := true
+
+	err := eachItem(e, args, func(_ int, _, res any) (bool, error) {
+		ok, err := toBool(res)
+		result = ok
+
+		return ok && err == nil, err
+	})
+
+	return result, err
+}
+
+// count counts the items of a list, or only those for which the condition is true.
+func count(e *env, args []node) (any, error) {
+	if len(args) == 1 {
+		v, err := args[0].eval(e)
+		if err != nil {
+			return nil, err
+		}
+
+		list, err := toList(v)
+
+		return float64(len(list)), err
+	}
+
+	n := 0.0
+
+	err := eachItem(e, args, func(_ int, _, res any) (bool, error) {
+		ok, err := toBool(res)
+		if ok {
+			n++
+		}
+
+		return err == nil, err
+	})
+
+	return n, err
+}
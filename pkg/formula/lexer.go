@@ -0,0 +1,146 @@
+package formula
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of formula"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// operators are sorted so that longer operators are matched first.
+var operators = []string{
+	"==", "!=", ">=", "<=", "&&", "||",
+	"+", "-", "*", "/", "%", "^", ">", "<", "!", "?", ":", "(", ")", ",", ".", "[", "]",
+}
+
+// tokenize splits the expression into tokens.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+
+	for pos := 0; pos < len(expr); {
+		r, size := utf8.DecodeRuneInString(expr[pos:])
+
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case r == '"' || r == '\'':
+			s, n, err := readString(expr[pos:], r)
+			if err != nil {
+				return nil, fmt.Errorf("%w at position %d", err, pos)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: pos})
+			pos += n
+		case isDigit(expr[pos]):
+			end := pos
+			for end < len(expr) && (isDigit(expr[end]) || expr[end] == '.') {
+				end++
+			}
+
+			// exponent, e.g. 1e10
+			if end < len(expr) && (expr[end] == 'e' || expr[end] == 'E') {
+				exp := end + 1
+				if exp < len(expr) && (expr[exp] == '+' || expr[exp] == '-') {
+					exp++
+				}
+
+				if exp < len(expr) && isDigit(expr[exp]) {
+					for end = exp; end < len(expr) && isDigit(expr[end]); end++ {
+					}
+				}
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: expr[pos:end], pos: pos})
+			pos = end
+		case unicode.IsLetter(r) || r == '_':
+			end := pos
+			for end < len(expr) {
+				r, size := utf8.DecodeRuneInString(expr[end:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+
+				end += size
+			}
+
+			tokens = append(tokens, token{kind: tokenIdent, text: expr[pos:end], pos: pos})
+			pos = end
+		default:
+			op := matchOperator(expr[pos:])
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, pos)
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+			pos += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+func isDigit(b byte) bool { return '0' <= b && b <= '9' }
+
+func matchOperator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+
+	return ""
+}
+
+// readString reads a quoted string and returns its content and length including the quotes.
+func readString(s string, quote rune) (string, int, error) {
+	var b strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case rune(c) == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(s):
+			i++
+
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string")
+}
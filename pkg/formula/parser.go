@@ -0,0 +1,340 @@
+package formula
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// node is a node of the syntax tree of a formula.
+// Operators are represented as calls of the functions they stand for, e.g. a + b is add(a, b).
+type node interface {
+	eval(e *env) (any, error)
+}
+
+type literal struct{ value any }
+
+type ident struct {
+	name string
+	pos  int
+}
+
+type call struct {
+	name string
+	args []node
+	pos  int
+}
+
+// binary operators by precedence, from lowest to highest
+var binaryOperators = []map[string]string{
+	{"or": "or", "||": "or"},
+	{"and": "and", "&&": "and"},
+	{"==": "equal", "!=": "unequal", ">": "larger", ">=": "largerEq", "<": "smaller", "<=": "smallerEq"},
+	{"+": "add", "-": "subtract"},
+	{"*": "multiply", "/": "divide", "%": "mod"},
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(expr string) (node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	n, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, unexpected(t)
+	}
+
+	return n, nil
+}
+
+func unexpected(t token) error {
+	return fmt.Errorf("unexpected %s at position %d", t, t.pos)
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+// is reports whether the next token is the operator or keyword.
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == tokenOperator || t.kind == tokenIdent) && t.text == text
+}
+
+func (p *parser) expect(op string) error {
+	if t := p.next(); t.kind != tokenOperator || t.text != op {
+		return fmt.Errorf("expected %q but got %s at position %d", op, t, t.pos)
+	}
+
+	return nil
+}
+
+// parseTernary parses cond ? a : b.
+func (p *parser) parseTernary() (node, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.is("?") {
+		return cond, nil
+	}
+
+	pos := p.next().pos
+
+	then, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	els, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	return call{name: "if", args: []node{cond, then, els}, pos: pos}, nil
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(binaryOperators) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+
+		fn, ok := binaryOperators[level][t.text]
+		if !ok || t.kind == tokenString || t.kind == tokenNumber {
+			return left, nil
+		}
+
+		p.next()
+
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		left = call{name: fn, args: []node{left, right}, pos: t.pos}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+
+	var fn string
+
+	switch {
+	case p.is("-"):
+		fn = "unaryMinus"
+	case p.is("+"):
+		fn = "unaryPlus"
+	case p.is("!"), p.is("not"):
+		fn = "not"
+	default:
+		return p.parsePower()
+	}
+
+	p.next()
+
+	arg, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return call{name: fn, args: []node{arg}, pos: t.pos}, nil
+}
+
+// parsePower parses the right associative exponentiation.
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.is("^") {
+		return base, nil
+	}
+
+	pos := p.next().pos
+
+	exp, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return call{name: "pow", args: []node{base, exp}, pos: pos}, nil
+}
+
+// parsePostfix parses method calls like x.length() and indexing like x[0].
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.is("."):
+			p.next()
+
+			t := p.next()
+			if t.kind != tokenIdent {
+				return nil, unexpected(t)
+			}
+
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+
+			n = call{name: t.text, args: append([]node{n}, args...), pos: t.pos}
+		case p.is("["):
+			pos := p.next().pos
+
+			idx, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+
+			n = call{name: "at", args: []node{n, idx}, pos: pos}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+
+		return literal{value: f}, nil
+	case tokenString:
+		return literal{value: t.text}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return literal{value: true}, nil
+		case "false":
+			return literal{value: false}, nil
+		}
+
+		if !p.is("(") {
+			return ident{name: t.text, pos: t.pos}, nil
+		}
+
+		args, err := p.parseArgs()
+		if err != nil {
+			return nil, err
+		}
+
+		return call{name: t.text, args: args, pos: t.pos}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			n, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+
+			return n, p.expect(")")
+		case "[":
+			return p.parseList(t.pos)
+		}
+	}
+
+	return nil, unexpected(t)
+}
+
+// parseArgs parses a parenthesized list of arguments.
+func (p *parser) parseArgs() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	args := []node{}
+
+	if p.is(")") {
+		p.next()
+		return args, nil
+	}
+
+	for {
+		arg, err := p.parseTernary()
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+
+		if p.is(",") {
+			p.next()
+			continue
+		}
+
+		return args, p.expect(")")
+	}
+}
+
+// parseList parses a list literal after the opening bracket.
+func (p *parser) parseList(pos int) (node, error) {
+	items := []node{}
+
+	if p.is("]") {
+		p.next()
+		return call{name: "list", args: items, pos: pos}, nil
+	}
+
+	for {
+		item, err := p.parseTernary()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+
+		if p.is(",") {
+			p.next()
+			continue
+		}
+
+		return call{name: "list", args: items, pos: pos}, p.expect("]")
+	}
+}
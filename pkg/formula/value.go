@@ -0,0 +1,385 @@
+package formula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Values during evaluation are either nil for empty values,
+// float64, string, bool, notion.Date or []any.
+
+// propertyValue returns the formula value of a property value.
+func propertyValue(prop notion.PropertyValue) any {
+	switch prop.Type {
+	case notion.PropertyTypeTitle:
+		return prop.GetTitle().Content()
+	case notion.PropertyTypeRichText:
+		return prop.GetRichText().Content()
+	case notion.PropertyTypeNumber:
+		if prop.Number == nil {
+			return nil
+		}
+
+		return *prop.Number
+	case notion.PropertyTypeCheckbox:
+		return prop.GetCheckbox()
+	case notion.PropertyTypeSelect:
+		return optionalString(prop.GetSelect().Name)
+	case notion.PropertyTypeStatus:
+		return optionalString(prop.GetStatus().Name)
+	case notion.PropertyTypeMultiSelect:
+		list := []any{}
+		for _, o := range prop.GetMultiSelect() {
+			list = append(list, o.Name)
+		}
+
+		return list
+	case notion.PropertyTypeDate:
+		if prop.Date == nil {
+			return nil
+		}
+
+		return *prop.Date
+	case notion.PropertyTypeUrl:
+		return optionalString(prop.GetURL())
+	case notion.PropertyTypeEmail:
+		return optionalString(prop.GetEmail())
+	case notion.PropertyTypePhoneNumber:
+		return optionalString(prop.GetPhoneNumber())
+	case notion.PropertyTypePeople:
+		list := []any{}
+		for _, u := range prop.GetPeople() {
+			list = append(list, userName(u))
+		}
+
+		return list
+	case notion.PropertyTypeRelation:
+		list := []any{}
+		for _, ref := range prop.GetRelation() {
+			list = append(list, string(ref.Id))
+		}
+
+		return list
+	case notion.PropertyTypeFiles:
+		list := []any{}
+
+		for _, f := range prop.GetFiles() {
+			if f.Name != nil {
+				list = append(list, *f.Name)
+			}
+		}
+
+		return list
+	case notion.PropertyTypeFormula:
+		return formulaValue(prop.GetFormula())
+	case notion.PropertyTypeRollup:
+		return rollupValue(prop.GetRollup())
+	case notion.PropertyTypeCreatedTime:
+		return notion.NewDate(prop.GetCreatedTime())
+	case notion.PropertyTypeLastEditedTime:
+		return notion.NewDate(prop.GetLastEditedTime())
+	case notion.PropertyTypeCreatedBy:
+		return userName(prop.GetCreatedBy())
+	case notion.PropertyTypeLastEditedBy:
+		return userName(prop.GetLastEditedBy())
+	default:
+		return nil
+	}
+}
+
+func optionalString(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+func userName(u notion.User) string {
+	if u.Name != nil {
+		return *u.Name
+	}
+
+	return string(u.Id)
+}
+
+func formulaValue(f notion.Formula) any {
+	switch {
+	case f.Number != nil:
+		return *f.Number
+	case f.String != nil:
+		return *f.String
+	case f.Boolean != nil:
+		return *f.Boolean
+	case f.Date != nil:
+		return *f.Date
+	default:
+		return nil
+	}
+}
+
+func rollupValue(r notion.Rollup) any {
+	switch {
+	case r.Number != nil:
+		return *r.Number
+	case r.Date != nil:
+		return *r.Date
+	case r.String != nil:
+		return *r.String
+	case r.Array != nil:
+		list := []any{}
+
+		for _, item := range *r.Array {
+			list = append(list, propertyValue(item.PropertyValue()))
+		}
+
+		return list
+	default:
+		return nil
+	}
+}
+
+// toFormula returns the formula value as it is returned by the API.
+// Lists are returned as strings.
+func toFormula(v any) notion.Formula {
+	switch v := v.(type) {
+	case float64:
+		return notion.Formula{Type: notion.FormulaTypeNumber, Number: &v}
+	case bool:
+		return notion.Formula{Type: notion.FormulaTypeBoolean, Boolean: &v}
+	case notion.Date:
+		return notion.Formula{Type: notion.FormulaTypeDate, Date: &v}
+	case nil:
+		return notion.Formula{Type: notion.FormulaTypeString}
+	default:
+		s := format(v)
+		return notion.Formula{Type: notion.FormulaTypeString, String: &s}
+	}
+}
+
+// typeName returns the name of the type of the value as used in error messages.
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "empty"
+	case float64:
+		return "number"
+	case string:
+		return "text"
+	case bool:
+		return "boolean"
+	case notion.Date:
+		return "date"
+	case []any:
+		return "list"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func expected(want string, got any) error {
+	return fmt.Errorf("expected %s but got %s", want, typeName(got))
+}
+
+func toNumber(v any) (float64, error) {
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	default:
+		return 0, expected("number", v)
+	}
+}
+
+func toString(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		return "", expected("text", v)
+	}
+}
+
+func toBool(v any) (bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	default:
+		return false, expected("boolean", v)
+	}
+}
+
+func toDate(v any) (notion.Date, error) {
+	d, ok := v.(notion.Date)
+	if !ok {
+		return notion.Date{}, expected("date", v)
+	}
+
+	return d, nil
+}
+
+func toList(v any) ([]any, error) {
+	switch v := v.(type) {
+	case nil:
+		return []any{}, nil
+	case []any:
+		return v, nil
+	default:
+		return nil, expected("list", v)
+	}
+}
+
+// format returns the value as text.
+func format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case notion.Date:
+		return formatDateDefault(v)
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = format(item)
+		}
+
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatDateDefault formats the date like notion does by default.
+func formatDateDefault(d notion.Date) string {
+	layout := "January 2, 2006"
+	if d.HasTime() {
+		layout += " 3:04 PM"
+	}
+
+	s := d.Start.Format(layout)
+	if d.End != nil {
+		s += " → " + d.End.Format(layout)
+	}
+
+	return s
+}
+
+// isEmpty reports whether the value is considered empty.
+func isEmpty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case []any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// equal reports whether both values are equal.
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case notion.Date:
+		b, ok := b.(notion.Date)
+		return ok && a.Start.Equal(b.Start)
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return a == b
+	}
+}
+
+// compare returns -1, 0 or 1 if a is smaller, equal or larger than b.
+// Empty values are smaller than any other value.
+func compare(a, b any) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return -1, nil
+	case b == nil:
+		return 1, nil
+	}
+
+	switch a := a.(type) {
+	case float64:
+		b, err := toNumber(b)
+		if err != nil {
+			return 0, err
+		}
+
+		return cmpOrdered(a, b), nil
+	case string:
+		b, err := toString(b)
+		if err != nil {
+			return 0, err
+		}
+
+		return cmpOrdered(a, b), nil
+	case notion.Date:
+		b, err := toDate(b)
+		if err != nil {
+			return 0, err
+		}
+
+		return a.Start.Compare(b.Start), nil
+	case bool:
+		b, err := toBool(b)
+		if err != nil {
+			return 0, err
+		}
+
+		return cmpOrdered(boolNumber(a), boolNumber(b)), nil
+	default:
+		return 0, fmt.Errorf("cannot compare %s", typeName(a))
+	}
+}
+
+func cmpOrdered[T float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func boolNumber(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,144 @@
+package ics
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// defaultTTL is the default time a feed is cached.
+const defaultTTL = 5 * time.Minute
+
+var _ Client = (*notion.Client)(nil)
+
+// Client is any client that can read databases.
+type Client interface {
+	// GetNotionDatabase returns the database.
+	GetNotionDatabase(ctx context.Context, id notion.Id) (*notion.Database, error)
+	// GetDatabaseEntries returns the filtered and sorted entries of a database.
+	GetDatabaseEntries(ctx context.Context, id notion.Id, filter *notion.Filter, sorts *notion.Sorts) (
+		notion.Pages, error)
+}
+
+// HandlerOptions define how a database is served as a feed.
+type HandlerOptions struct {
+	Options
+
+	// Filter limits the entries that become events.
+	Filter *notion.Filter
+
+	// TTL is how long the feed is cached before the entries are fetched again. Defaults to 5 minutes.
+	TTL time.Duration
+
+	// OnError is called with the errors of failed fetches.
+	// If the feed was fetched before, the outdated feed is served instead.
+	OnError func(error)
+}
+
+// Handler is an http.Handler that serves a database as a calendar feed.
+type Handler struct {
+	cli  Client
+	id   notion.Id
+	opts HandlerOptions
+
+	mu      sync.Mutex
+	feed    []byte
+	etag    string
+	fetched time.Time
+}
+
+// NewHandler returns a handler serving the entries of the database.
+// If no name is set, the title of the database is used.
+func NewHandler(cli Client, id notion.Id, opts HandlerOptions) *Handler {
+	if opts.TTL <= 0 {
+		opts.TTL = defaultTTL
+	}
+
+	if opts.OnError == nil {
+		opts.OnError = func(error) {}
+	}
+
+	return &Handler{cli: cli, id: id, opts: opts}
+}
+
+// ServeHTTP fulfils http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	feed, etag, modified, err := h.get(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "max-age="+strconv.Itoa(int(h.opts.TTL.Seconds())))
+	w.Header().Set("ETag", etag)
+
+	http.ServeContent(w, r, "", modified, bytes.NewReader(feed))
+}
+
+// get returns the cached feed or fetches it if it is outdated.
+func (h *Handler) get(ctx context.Context) ([]byte, string, time.Time, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.feed != nil && time.Since(h.fetched) < h.opts.TTL {
+		return h.feed, h.etag, h.fetched, nil
+	}
+
+	feed, err := h.fetch(ctx)
+	if err != nil {
+		h.opts.OnError(err)
+
+		if h.feed == nil {
+			return nil, "", time.Time{}, err
+		}
+
+		return h.feed, h.etag, h.fetched, nil
+	}
+
+	sum := sha256.Sum256(feed)
+
+	h.feed, h.etag, h.fetched = feed, `"`+hex.EncodeToString(sum[:16])+`"`, time.Now()
+
+	return h.feed, h.etag, h.fetched, nil
+}
+
+func (h *Handler) fetch(ctx context.Context) ([]byte, error) {
+	opts := h.opts.Options
+
+	if opts.Name == "" {
+		db, err := h.cli.GetNotionDatabase(ctx, h.id)
+		if err != nil {
+			return nil, fmt.Errorf("getting database: %w", err)
+		}
+
+		opts.Name = db.Title.Content()
+	}
+
+	pages, err := h.cli.GetDatabaseEntries(ctx, h.id, h.opts.Filter, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting entries: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Write(buf, pages, opts); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
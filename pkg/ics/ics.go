@@ -0,0 +1,276 @@
+// Package ics converts database entries into iCalendar (RFC 5545) events,
+// e.g. to subscribe to a database from a calendar app.
+//
+// Entries with a date without time information become all-day events,
+// all other entries become timed events in the time zone of their date,
+// which is defined by a VTIMEZONE component, or in UTC if the date has none.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// ProdID identifies the product that created the calendar.
+const ProdID = "-//faetools//go-notion//EN"
+
+const (
+	layoutDate     = "20060102"
+	layoutDateTime = "20060102T150405"
+	layoutUTC      = "20060102T150405Z"
+
+	// maxLineLength is the maximum length of a line in octets, excluding the line break.
+	maxLineLength = 75
+)
+
+// Options define how entries are converted into events.
+type Options struct {
+	// Property is the name of the property that determines when an event takes place.
+	// It can be a date, a formula or rollup with a date result, or the created or last edited time.
+	Property string
+
+	// Name is the name of the calendar as shown by calendar apps.
+	Name string
+}
+
+// Event is an event of a calendar.
+type Event struct {
+	// UID uniquely identifies the event.
+	UID string
+	// Summary is the title of the event.
+	Summary string
+	// Description is the text of the event.
+	Description string
+	// URL links to the entry of the event.
+	URL string
+	// Date determines when the event takes place.
+	Date notion.Date
+	// Modified is the time the event was last modified.
+	Modified time.Time
+}
+
+// NewEvent returns the event of the page, or false if its property is empty.
+func NewEvent(p notion.Page, property string) (Event, bool, error) {
+	prop, ok := p.Properties[property]
+	if !ok {
+		return Event{}, false, fmt.Errorf("page %s has no property %q", p.Id, property)
+	}
+
+	d, ok, err := date(prop)
+	if err != nil || !ok {
+		return Event{}, false, err
+	}
+
+	return Event{
+		UID:         fmt.Sprintf("%s@notion.so", p.Id),
+		Summary:     p.Title(),
+		Description: p.Url,
+		URL:         p.Url,
+		Date:        d,
+		Modified:    p.LastEditedTime,
+	}, true, nil
+}
+
+// date returns the date of the property or false if it is empty.
+func date(prop notion.PropertyValue) (notion.Date, bool, error) {
+	switch prop.Type {
+	case notion.PropertyTypeDate:
+		return optional(prop.Date)
+	case notion.PropertyTypeFormula:
+		if f := prop.GetFormula(); f.Type == notion.FormulaTypeDate {
+			return optional(f.Date)
+		}
+	case notion.PropertyTypeRollup:
+		if r := prop.GetRollup(); r.Type == notion.RollupTypeDate {
+			return optional(r.Date)
+		}
+	case notion.PropertyTypeCreatedTime:
+		if prop.CreatedTime == nil {
+			return notion.Date{}, false, nil
+		}
+
+		return notion.Date{Start: *prop.CreatedTime}, true, nil
+	case notion.PropertyTypeLastEditedTime:
+		if prop.LastEditedTime == nil {
+			return notion.Date{}, false, nil
+		}
+
+		return notion.Date{Start: *prop.LastEditedTime}, true, nil
+	}
+
+	return notion.Date{}, false, fmt.Errorf("%s properties have no date", prop.Type)
+}
+
+func optional(d *notion.Date) (notion.Date, bool, error) {
+	if d == nil {
+		return notion.Date{}, false, nil
+	}
+
+	return *d, true, nil
+}
+
+// Events returns the events of the pages, skipping pages with an empty property.
+func Events(pages notion.Pages, property string) ([]Event, error) {
+	events := make([]Event, 0, len(pages))
+
+	for _, p := range pages {
+		e, ok, err := NewEvent(p, property)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			events = append(events, e)
+		}
+	}
+
+	return events, nil
+}
+
+// Write writes the pages as a calendar.
+func Write(w io.Writer, pages notion.Pages, opts Options) error {
+	events, err := Events(pages, opts.Property)
+	if err != nil {
+		return err
+	}
+
+	return WriteEvents(w, opts.Name, events)
+}
+
+// WriteEvents writes the events as a calendar with the name.
+func WriteEvents(w io.Writer, name string, events []Event) error {
+	lw := &lineWriter{w: bufio.NewWriter(w)}
+
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + ProdID)
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+
+	if name != "" {
+		lw.line("X-WR-CALNAME:" + escape(name))
+	}
+
+	for _, tz := range timeZones(events) {
+		tz.write(lw)
+	}
+
+	for _, e := range events {
+		e.write(lw)
+	}
+
+	lw.line("END:VCALENDAR")
+
+	if lw.err != nil {
+		return lw.err
+	}
+
+	return lw.w.Flush()
+}
+
+func (e Event) write(lw *lineWriter) {
+	lw.line("BEGIN:VEVENT")
+	lw.line("UID:" + escape(e.UID))
+
+	// the time stamp is required, it defaults to the time the calendar is created
+	if e.Modified.IsZero() {
+		lw.line("DTSTAMP:" + time.Now().UTC().Format(layoutUTC))
+	} else {
+		lw.line("DTSTAMP:" + e.Modified.UTC().Format(layoutUTC))
+		lw.line("LAST-MODIFIED:" + e.Modified.UTC().Format(layoutUTC))
+	}
+
+	e.writeDate(lw)
+
+	lw.line("SUMMARY:" + escape(e.Summary))
+
+	if e.Description != "" {
+		lw.line("DESCRIPTION:" + escape(e.Description))
+	}
+
+	if e.URL != "" {
+		lw.line("URL:" + e.URL)
+	}
+
+	lw.line("END:VEVENT")
+}
+
+func (e Event) writeDate(lw *lineWriter) {
+	d := e.Date
+
+	if d.IsDateOnly() {
+		// the end of an all-day event is exclusive
+		lw.line("DTSTART;VALUE=DATE:" + d.Start.Format(layoutDate))
+		lw.line("DTEND;VALUE=DATE:" + end(d).AddDate(0, 0, 1).Format(layoutDate))
+
+		return
+	}
+
+	loc := location(d.TimeZone)
+
+	lw.line("DTSTART" + dateTime(d.Start, loc))
+
+	// without an end, a timed event ends when it starts
+	if d.End != nil {
+		lw.line("DTEND" + dateTime(*d.End, loc))
+	}
+}
+
+func end(d notion.Date) time.Time {
+	if d.End == nil {
+		return d.Start
+	}
+
+	return *d.End
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// escape escapes the text of a property value.
+func escape(s string) string { return escaper.Replace(s) }
+
+// lineWriter writes content lines, folding long lines.
+type lineWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (lw *lineWriter) line(s string) {
+	if lw.err != nil {
+		return
+	}
+
+	for len(s) > maxLineLength {
+		n := maxLineLength
+
+		// don't split a character
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+
+		lw.write(s[:n] + "\r\n")
+
+		// continuation lines start with a space, which counts towards their length
+		s = " " + s[n:]
+	}
+
+	lw.write(s + "\r\n")
+}
+
+func (lw *lineWriter) write(s string) {
+	if lw.err == nil {
+		_, lw.err = lw.w.WriteString(s)
+	}
+}
@@ -0,0 +1,267 @@
+package ics_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/ics"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var edited = time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+func page(id notion.UUID, title string, d *notion.Date) notion.Page {
+	return notion.Page{
+		Id:             id,
+		Url:            "https://www.notion.so/" + string(id),
+		LastEditedTime: edited,
+		Properties: notion.PropertyValueMap{
+			"Name": {Type: notion.PropertyTypeTitle, Title: notion.NewRichTextsP(title)},
+			"When": {Type: notion.PropertyTypeDate, Date: d},
+		},
+	}
+}
+
+func pages(t *testing.T) notion.Pages {
+	t.Helper()
+
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+
+	end := notion.NewDateOnly(2024, 2, 3).Start
+	allDay := notion.NewDateOnly(2024, 2, 1)
+	allDay.End = &end
+
+	midnight := time.Date(2024, 2, 5, 0, 0, 0, 0, shanghai)
+	meeting := notion.NewDateRange(midnight, midnight.Add(30*time.Minute), nil)
+
+	call := notion.NewDate(time.Date(2024, 2, 6, 15, 0, 0, 0, time.UTC))
+
+	return notion.Pages{
+		page("a1", "Offsite; Day 1, 2", &allDay),
+		page("b2", "Midnight meeting", &meeting),
+		page("c3", "Call", &call),
+		page("d4", "Someday", nil),
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ics.Write(buf, pages(t), ics.Options{Property: "When", Name: "Team"}))
+
+	assert.Equal(t, strings.ReplaceAll(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//faetools//go-notion//EN
+CALSCALE:GREGORIAN
+METHOD:PUBLISH
+X-WR-CALNAME:Team
+BEGIN:VTIMEZONE
+TZID:Asia/Shanghai
+BEGIN:STANDARD
+DTSTART:19910915T020000
+TZOFFSETFROM:+0900
+TZOFFSETTO:+0800
+TZNAME:CST
+END:STANDARD
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:a1@notion.so
+DTSTAMP:20240131T120000Z
+LAST-MODIFIED:20240131T120000Z
+DTSTART;VALUE=DATE:20240201
+DTEND;VALUE=DATE:20240204
+SUMMARY:Offsite\; Day 1\, 2
+DESCRIPTION:https://www.notion.so/a1
+URL:https://www.notion.so/a1
+END:VEVENT
+BEGIN:VEVENT
+UID:b2@notion.so
+DTSTAMP:20240131T120000Z
+LAST-MODIFIED:20240131T120000Z
+DTSTART;TZID=Asia/Shanghai:20240205T000000
+DTEND;TZID=Asia/Shanghai:20240205T003000
+SUMMARY:Midnight meeting
+DESCRIPTION:https://www.notion.so/b2
+URL:https://www.notion.so/b2
+END:VEVENT
+BEGIN:VEVENT
+UID:c3@notion.so
+DTSTAMP:20240131T120000Z
+LAST-MODIFIED:20240131T120000Z
+DTSTART:20240206T150000Z
+SUMMARY:Call
+DESCRIPTION:https://www.notion.so/c3
+URL:https://www.notion.so/c3
+END:VEVENT
+END:VCALENDAR
+`, "\n", "\r\n"), buf.String())
+
+	err := ics.Write(buf, pages(t), ics.Options{Property: "Name"})
+	assert.EqualError(t, err, "title properties have no date")
+
+	err = ics.Write(buf, pages(t), ics.Options{Property: "Due"})
+	assert.EqualError(t, err, `page a1 has no property "Due"`)
+}
+
+func TestWriteEvents_Folding(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ics.WriteEvents(buf, "", []ics.Event{{
+		UID:         "long",
+		Summary:     strings.Repeat("äöü", 30),
+		Description: "first line\nsecond line",
+		Date:        notion.NewDateOnly(2024, 2, 1),
+		Modified:    edited,
+	}}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	assert.Contains(t, lines, `DESCRIPTION:first line\nsecond line`)
+
+	var summary string
+
+	for i, l := range lines {
+		assert.LessOrEqual(t, len(l), 75, "line %d is too long", i)
+
+		switch {
+		case strings.HasPrefix(l, "SUMMARY:"):
+			summary = l
+		case strings.HasPrefix(l, " "):
+			summary += l[1:]
+		}
+	}
+
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("äöü", 30), summary)
+}
+
+func TestWriteEvents_TimeZone(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// the event spans the change to daylight saving time
+	start := time.Date(2024, 3, 30, 10, 0, 0, 0, berlin)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, ics.WriteEvents(buf, "", []ics.Event{{
+		UID:      "trip",
+		Summary:  "Trip",
+		Date:     notion.NewDateRange(start, start.AddDate(0, 0, 2), nil),
+		Modified: edited,
+	}}))
+
+	assert.Contains(t, buf.String(), strings.ReplaceAll(`BEGIN:VTIMEZONE
+TZID:Europe/Berlin
+BEGIN:STANDARD
+DTSTART:20231029T030000
+TZOFFSETFROM:+0200
+TZOFFSETTO:+0100
+TZNAME:CET
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:20240331T020000
+TZOFFSETFROM:+0100
+TZOFFSETTO:+0200
+TZNAME:CEST
+END:DAYLIGHT
+END:VTIMEZONE
+`, "\n", "\r\n"))
+	assert.Contains(t, buf.String(), "DTSTART;TZID=Europe/Berlin:20240330T100000\r\n")
+	assert.Contains(t, buf.String(), "DTEND;TZID=Europe/Berlin:20240401T100000\r\n")
+}
+
+// memClient has a single database.
+type memClient struct {
+	mu      sync.Mutex
+	entries notion.Pages
+	err     error
+	calls   int
+}
+
+func (c *memClient) GetNotionDatabase(context.Context, notion.Id) (*notion.Database, error) {
+	return &notion.Database{Title: notion.NewRichTexts("Team Calendar")}, nil
+}
+
+func (c *memClient) GetDatabaseEntries(
+	context.Context, notion.Id, *notion.Filter, *notion.Sorts,
+) (notion.Pages, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+
+	return c.entries, c.err
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	cli := &memClient{entries: pages(t)}
+
+	var errs []error
+
+	h := ics.NewHandler(cli, "db", ics.HandlerOptions{
+		Options: ics.Options{Property: "When"},
+		TTL:     50 * time.Millisecond,
+		OnError: func(err error) { errs = append(errs, err) },
+	})
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/team.ics", nil)
+
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		h.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := get(nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "X-WR-CALNAME:Team Calendar\r\n")
+	assert.Contains(t, rec.Body.String(), "SUMMARY:Call\r\n")
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rec = get(http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, 1, cli.calls, "feed should be cached")
+
+	// serve the outdated feed if fetching fails
+	time.Sleep(60 * time.Millisecond)
+
+	cli.err = errors.New("boom")
+
+	rec = get(nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+	assert.Equal(t, 2, cli.calls)
+	assert.Len(t, errs, 1)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/team.ics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	// nothing to serve
+	rec = httptest.NewRecorder()
+	ics.NewHandler(cli, "db", ics.HandlerOptions{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Equal(t, "getting entries: boom\n", rec.Body.String())
+}
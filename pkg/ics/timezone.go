@@ -0,0 +1,134 @@
+package ics
+
+import (
+	"fmt"
+	"time"
+)
+
+// location returns the location of the time zone or nil if the times should be in UTC.
+func location(tz *string) *time.Location {
+	if tz == nil || *tz == "UTC" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return nil
+	}
+
+	return loc
+}
+
+// dateTime returns the parameters and value of a date-time property.
+func dateTime(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		return ":" + t.UTC().Format(layoutUTC)
+	}
+
+	return fmt.Sprintf(";TZID=%s:%s", loc, t.In(loc).Format(layoutDateTime))
+}
+
+// timeZone is a time zone that events refer to.
+type timeZone struct {
+	loc *time.Location
+	// from and to is the period the events of the time zone cover.
+	from, to time.Time
+}
+
+// timeZones returns the time zones of the timed events in order of their first use.
+func timeZones(events []Event) []*timeZone {
+	var (
+		zones  []*timeZone
+		byName = map[string]*timeZone{}
+	)
+
+	for _, e := range events {
+		loc := location(e.Date.TimeZone)
+		if loc == nil || e.Date.IsDateOnly() {
+			continue
+		}
+
+		from, to := e.Date.Start, end(e.Date)
+
+		tz, ok := byName[loc.String()]
+		if !ok {
+			tz = &timeZone{loc: loc, from: from, to: to}
+			byName[loc.String()] = tz
+			zones = append(zones, tz)
+
+			continue
+		}
+
+		if from.Before(tz.from) {
+			tz.from = from
+		}
+
+		if to.After(tz.to) {
+			tz.to = to
+		}
+	}
+
+	return zones
+}
+
+// write writes the VTIMEZONE component with all offsets in effect while the events take place.
+func (tz *timeZone) write(lw *lineWriter) {
+	lw.line("BEGIN:VTIMEZONE")
+	lw.line("TZID:" + tz.loc.String())
+
+	t := tz.from.In(tz.loc)
+
+	for {
+		start, next := t.ZoneBounds()
+		writeObservance(lw, t, start)
+
+		if next.IsZero() || next.After(tz.to) {
+			break
+		}
+
+		t = next.In(tz.loc)
+	}
+
+	lw.line("END:VTIMEZONE")
+}
+
+// writeObservance writes the offset in effect at t, which started at start.
+// A zero start means the offset has always been in effect.
+func writeObservance(lw *lineWriter, t, start time.Time) {
+	name, offsetTo := t.Zone()
+	offsetFrom := offsetTo
+
+	if start.IsZero() {
+		start = t
+	} else {
+		_, offsetFrom = start.Add(-time.Second).In(t.Location()).Zone()
+	}
+
+	component := "STANDARD"
+	if t.IsDST() {
+		component = "DAYLIGHT"
+	}
+
+	lw.line("BEGIN:" + component)
+	// the onset is given in the local time before it
+	lw.line("DTSTART:" + start.In(time.FixedZone("", offsetFrom)).Format(layoutDateTime))
+	lw.line("TZOFFSETFROM:" + utcOffset(offsetFrom))
+	lw.line("TZOFFSETTO:" + utcOffset(offsetTo))
+	lw.line("TZNAME:" + escape(name))
+	lw.line("END:" + component)
+}
+
+// utcOffset formats the offset in seconds east of UTC, e.g. as +0800.
+func utcOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+
+	s := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+	if sec := offset % 60; sec != 0 {
+		s += fmt.Sprintf("%02d", sec)
+	}
+
+	return s
+}
@@ -0,0 +1,513 @@
+// Package importer adds or updates database entries from rows of CSV or JSON Lines,
+// converting the values into the types of the properties.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/export"
+	"github.com/faetools/go-notion/pkg/notion"
+	"golang.org/x/sync/errgroup"
+)
+
+var _ Client = (*notion.Client)(nil)
+
+// Client is any client that can read and change databases and their entries.
+type Client interface {
+	// GetNotionDatabase returns the database.
+	GetNotionDatabase(ctx context.Context, id notion.Id) (*notion.Database, error)
+	// GetAllDatabaseEntries returns all entries of the database.
+	GetAllDatabaseEntries(ctx context.Context, id notion.Id) (notion.Pages, error)
+	// UpdateNotionDatabase updates the database.
+	UpdateNotionDatabase(ctx context.Context, db notion.Database) (*notion.Database, error)
+	// CreateNotionPage creates a page.
+	CreateNotionPage(ctx context.Context, p notion.Page) (*notion.Page, error)
+	// UpdateNotionPage updates the properties of a page.
+	UpdateNotionPage(ctx context.Context, p notion.Page) (*notion.Page, error)
+}
+
+// Options define how rows are imported.
+type Options struct {
+	// Columns maps column names to property names.
+	// Columns that are not mapped are imported into the property with the same name.
+	Columns map[string]string
+
+	// IgnoreUnknown skips columns without a property instead of failing the import.
+	IgnoreUnknown bool
+
+	// Key is the name of the property that identifies entries. A row with the same key
+	// as an existing entry updates it, all other rows create new entries.
+	// If empty, all rows create new entries.
+	Key string
+
+	// CreateOptions adds unknown select and multi-select options to the database.
+	// Otherwise, rows with unknown options fail.
+	CreateOptions bool
+
+	// Location is the location of times without UTC offset. Defaults to UTC.
+	Location *time.Location
+
+	// Concurrency is the maximum number of entries created or updated in parallel. Defaults to 1.
+	Concurrency int
+
+	// DryRun only reports what an import would do without changing anything.
+	DryRun bool
+}
+
+// Importer imports rows into databases.
+type Importer struct {
+	cli  Client
+	opts Options
+}
+
+// New returns a new importer.
+func New(cli Client, opts Options) *Importer {
+	return &Importer{cli: cli, opts: opts}
+}
+
+// run is the state of a single import.
+type run struct {
+	*Importer
+
+	db *notion.Database
+
+	// props maps the columns to the properties
+	props map[string]string
+	// existing maps the keys of existing entries to their IDs
+	existing map[string]notion.UUID
+	// titles maps the titles of related pages to their IDs, by relation property
+	titles map[string]map[string]notion.UUID
+	// options are the known options of select and multi-select properties, by lower case name
+	options map[string]map[string]string
+
+	report *Report
+}
+
+// write is the creation or update of an entry.
+type write struct {
+	row    int
+	page   notion.Page
+	update bool
+}
+
+// Import imports the rows into the database. Rows that fail do not stop the import,
+// their errors are collected in the report.
+// An error is only returned if the import could not be prepared or the new options could not be added.
+func (im *Importer) Import(ctx context.Context, id notion.Id, rows []Row) (*Report, error) {
+	db, err := im.cli.GetNotionDatabase(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting database %s: %w", id, err)
+	}
+
+	r := &run{
+		Importer: im,
+		db:       db,
+		props:    map[string]string{},
+		existing: map[string]notion.UUID{},
+		titles:   map[string]map[string]notion.UUID{},
+		options:  map[string]map[string]string{},
+		report:   &Report{Created: []int{}, Updated: []int{}, NewOptions: map[string][]string{}},
+	}
+
+	if err := r.mapColumns(rows); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadExisting(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadTitles(ctx); err != nil {
+		return nil, err
+	}
+
+	writes := r.prepare(rows)
+
+	if im.opts.DryRun {
+		for _, w := range writes {
+			r.done(w)
+		}
+
+		r.report.sort()
+
+		return r.report, nil
+	}
+
+	if err := r.addOptions(ctx); err != nil {
+		return nil, err
+	}
+
+	r.write(ctx, writes)
+	r.report.sort()
+
+	return r.report, nil
+}
+
+// mapColumns maps all columns of the rows to properties.
+func (r *run) mapColumns(rows []Row) error {
+	for _, row := range rows {
+		for col := range row {
+			if _, ok := r.props[col]; ok {
+				continue
+			}
+
+			name := col
+			if mapped, ok := r.opts.Columns[col]; ok {
+				name = mapped
+			}
+
+			prop, ok := r.db.Properties[name]
+			switch {
+			case !ok && r.opts.IgnoreUnknown:
+				r.props[col] = ""
+				continue
+			case !ok:
+				return fmt.Errorf("database has no property %q for column %q", name, col)
+			case prop.Type.ReadOnly():
+				return fmt.Errorf("cannot import column %q into %s property %q", col, prop.Type, name)
+			}
+
+			r.props[col] = name
+		}
+	}
+
+	return nil
+}
+
+// loadExisting indexes the existing entries by their key.
+func (r *run) loadExisting(ctx context.Context) error {
+	if r.opts.Key == "" {
+		return nil
+	}
+
+	if _, ok := r.db.Properties[r.opts.Key]; !ok {
+		return fmt.Errorf("database has no key property %q", r.opts.Key)
+	}
+
+	entries, err := r.cli.GetAllDatabaseEntries(ctx, notion.Id(r.db.Id))
+	if err != nil {
+		return fmt.Errorf("getting entries: %w", err)
+	}
+
+	for _, p := range entries {
+		if k := key(p.Properties[r.opts.Key]); k != "" {
+			r.existing[k] = p.Id
+		}
+	}
+
+	return nil
+}
+
+func key(prop notion.PropertyValue) string {
+	v, _ := export.Value(prop, nil)
+	return export.Text(v, ", ")
+}
+
+// loadTitles loads the titles of all pages in related databases.
+func (r *run) loadTitles(ctx context.Context) error {
+	for _, name := range r.props {
+		prop := r.db.Properties[name]
+		if prop.Type != notion.PropertyTypeRelation || r.titles[name] != nil {
+			continue
+		}
+
+		dbID := prop.GetRelation().DatabaseId
+
+		entries, err := r.cli.GetAllDatabaseEntries(ctx, notion.Id(dbID))
+		if err != nil {
+			return fmt.Errorf("getting entries of database %s related by %q: %w", dbID, name, err)
+		}
+
+		titles := make(map[string]notion.UUID, len(entries))
+		for _, p := range entries {
+			titles[p.Title()] = p.Id
+		}
+
+		r.titles[name] = titles
+	}
+
+	return nil
+}
+
+// prepare converts the rows into writes, collecting the errors of rows that can't be converted.
+func (r *run) prepare(rows []Row) []write {
+	writes := make([]write, 0, len(rows))
+	keys := map[string]int{}
+
+	for i, row := range rows {
+		w := write{row: i + 1}
+
+		props, added, err := r.properties(row)
+		if err == nil && r.opts.Key != "" {
+			w.page.Id, w.update, err = r.identify(props, keys, w.row)
+		}
+
+		if err != nil {
+			r.report.Errors = append(r.report.Errors, &RowError{Row: w.row, Err: err})
+			continue
+		}
+
+		for name, opts := range added {
+			for _, opt := range opts {
+				r.options[name][strings.ToLower(opt)] = opt
+				r.report.NewOptions[name] = append(r.report.NewOptions[name], opt)
+			}
+		}
+
+		w.page.Properties = props
+		if !w.update {
+			w.page.Parent = &notion.Parent{Type: "database_id", DatabaseId: &r.db.Id}
+		}
+
+		writes = append(writes, w)
+	}
+
+	return writes
+}
+
+// identify returns the ID of the existing entry with the same key as the properties, if any.
+func (r *run) identify(props notion.PropertyValueMap, keys map[string]int, row int) (notion.UUID, bool, error) {
+	k := key(props[r.opts.Key])
+	if k == "" {
+		return "", false, fmt.Errorf("key %q is empty", r.opts.Key)
+	}
+
+	if prev, ok := keys[k]; ok {
+		return "", false, fmt.Errorf("key %q is the same as in row %d", k, prev)
+	}
+
+	keys[k] = row
+	id, ok := r.existing[k]
+
+	return id, ok, nil
+}
+
+// properties converts the row into property values.
+// It also returns the select options that need to be added, by property.
+func (r *run) properties(row Row) (notion.PropertyValueMap, map[string][]string, error) {
+	props := notion.PropertyValueMap{}
+	added := map[string][]string{}
+
+	for _, col := range sortedColumns(row) {
+		name := r.props[col]
+		if name == "" {
+			continue
+		}
+
+		v, err := r.value(name, row[col], added)
+		if err != nil {
+			return nil, nil, fmt.Errorf("column %q: %w", col, err)
+		}
+
+		props[name] = v
+	}
+
+	return props, added, nil
+}
+
+func sortedColumns(row Row) []string {
+	cols := make([]string, 0, len(row))
+	for col := range row {
+		cols = append(cols, col)
+	}
+
+	sort.Strings(cols)
+
+	return cols
+}
+
+// value converts a value of a row into a value of the property.
+func (r *run) value(name string, v any, added map[string][]string) (notion.PropertyValue, error) {
+	meta := r.db.Properties[name]
+
+	if s, ok := v.(string); ok && meta.Type == notion.PropertyTypeDate {
+		d, err := notion.ParseDate(s, r.opts.Location)
+		if err != nil {
+			return notion.PropertyValue{}, err
+		}
+
+		return notion.PropertyValue{Type: notion.PropertyTypeDate, Date: &d}, nil
+	}
+
+	prop, err := notion.NewPropertyValue(meta.Type, v)
+	if err != nil {
+		return notion.PropertyValue{}, err
+	}
+
+	switch {
+	case prop.Select != nil:
+		prop.Select.Name, err = r.option(name, prop.Select.Name, added)
+	case prop.Status != nil:
+		prop.Status.Name, err = r.option(name, prop.Status.Name, nil)
+	case prop.MultiSelect != nil:
+		for i, opt := range *prop.MultiSelect {
+			if (*prop.MultiSelect)[i].Name, err = r.option(name, opt.Name, added); err != nil {
+				break
+			}
+		}
+	case prop.Relation != nil:
+		for i, ref := range *prop.Relation {
+			if (*prop.Relation)[i].Id, err = r.related(name, string(ref.Id)); err != nil {
+				break
+			}
+		}
+	}
+
+	return prop, err
+}
+
+// option returns the name of the option as it is spelled in the database.
+// Unknown options are added if allowed, which is never the case for status properties.
+func (r *run) option(prop, name string, added map[string][]string) (string, error) {
+	known := r.knownOptions(prop)
+	if opt, ok := known[strings.ToLower(name)]; ok {
+		return opt, nil
+	}
+
+	for _, opt := range added[prop] {
+		if strings.EqualFold(opt, name) {
+			return opt, nil
+		}
+	}
+
+	if added == nil || !r.opts.CreateOptions {
+		return "", fmt.Errorf("unknown option %q", name)
+	}
+
+	added[prop] = append(added[prop], name)
+
+	return name, nil
+}
+
+func (r *run) knownOptions(prop string) map[string]string {
+	if known, ok := r.options[prop]; ok {
+		return known
+	}
+
+	meta := r.db.Properties[prop]
+	known := map[string]string{}
+
+	switch meta.Type {
+	case notion.PropertyTypeSelect:
+		for _, opt := range meta.GetSelect().Options {
+			known[strings.ToLower(opt.Name)] = opt.Name
+		}
+	case notion.PropertyTypeMultiSelect:
+		for _, opt := range meta.GetMultiSelect().Options {
+			known[strings.ToLower(opt.Name)] = opt.Name
+		}
+	case notion.PropertyTypeStatus:
+		if meta.Status != nil {
+			for _, opt := range meta.Status.Options {
+				known[strings.ToLower(opt.Name)] = opt.Name
+			}
+		}
+	}
+
+	r.options[prop] = known
+
+	return known
+}
+
+// related returns the ID of the related page, given either its ID or its title.
+func (r *run) related(prop, s string) (notion.UUID, error) {
+	if id, ok := r.titles[prop][s]; ok {
+		return id, nil
+	}
+
+	if notion.IsUUID(s) {
+		return notion.UUID(s), nil
+	}
+
+	return "", fmt.Errorf("no related page titled %q", s)
+}
+
+// addOptions adds the new options to the database.
+func (r *run) addOptions(ctx context.Context) error {
+	if len(r.report.NewOptions) == 0 {
+		return nil
+	}
+
+	update := notion.Database{
+		Id:          r.db.Id,
+		Title:       r.db.Title,
+		Description: r.db.Description,
+		Properties:  notion.PropertyMetaMap{},
+	}
+
+	for name, opts := range r.report.NewOptions {
+		meta := r.db.Properties[name]
+
+		wrapper := &notion.SelectValuesWrapper{}
+		if meta.Type == notion.PropertyTypeSelect {
+			wrapper.Options = append(wrapper.Options, meta.GetSelect().Options...)
+			meta.Select = wrapper
+		} else {
+			wrapper.Options = append(wrapper.Options, meta.GetMultiSelect().Options...)
+			meta.MultiSelect = wrapper
+		}
+
+		for _, opt := range opts {
+			wrapper.Options = append(wrapper.Options, notion.SelectValue{Name: opt})
+		}
+
+		update.Properties[name] = meta
+	}
+
+	if _, err := r.cli.UpdateNotionDatabase(ctx, update); err != nil {
+		return fmt.Errorf("adding options: %w", err)
+	}
+
+	return nil
+}
+
+// write creates and updates the entries.
+func (r *run) write(ctx context.Context, writes []write) {
+	var (
+		mu sync.Mutex
+		eg errgroup.Group
+	)
+
+	eg.SetLimit(max(r.opts.Concurrency, 1))
+
+	for _, w := range writes {
+		w := w
+
+		eg.Go(func() error {
+			var err error
+			if w.update {
+				_, err = r.cli.UpdateNotionPage(ctx, w.page)
+			} else {
+				_, err = r.cli.CreateNotionPage(ctx, w.page)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				r.report.Errors = append(r.report.Errors, &RowError{Row: w.row, Err: err})
+				return nil
+			}
+
+			r.done(w)
+
+			return nil
+		})
+	}
+
+	_ = eg.Wait()
+}
+
+// done records the successful write.
+func (r *run) done(w write) {
+	if w.update {
+		r.report.Updated = append(r.report.Updated, w.row)
+	} else {
+		r.report.Created = append(r.report.Created, w.row)
+	}
+}
@@ -0,0 +1,207 @@
+package importer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/importer"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tasksDB    notion.UUID = "00000000-0000-4000-8000-000000000001"
+	projectsDB notion.UUID = "00000000-0000-4000-8000-000000000002"
+)
+
+// memClient keeps all objects in memory.
+type memClient struct {
+	mu        sync.Mutex
+	db        notion.Database
+	entries   map[notion.UUID]notion.Pages
+	dbUpdates []notion.Database
+	created   notion.Pages
+	updated   notion.Pages
+}
+
+func (c *memClient) GetNotionDatabase(context.Context, notion.Id) (*notion.Database, error) {
+	return &c.db, nil
+}
+
+func (c *memClient) GetAllDatabaseEntries(_ context.Context, id notion.Id) (notion.Pages, error) {
+	return c.entries[notion.UUID(id)], nil
+}
+
+func (c *memClient) UpdateNotionDatabase(_ context.Context, db notion.Database) (*notion.Database, error) {
+	c.dbUpdates = append(c.dbUpdates, db)
+	return &db, nil
+}
+
+func (c *memClient) CreateNotionPage(_ context.Context, p notion.Page) (*notion.Page, error) {
+	if p.Title() == "Fail" {
+		return nil, errors.New("bad request")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.created = append(c.created, p)
+
+	return &p, nil
+}
+
+func (c *memClient) UpdateNotionPage(_ context.Context, p notion.Page) (*notion.Page, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.updated = append(c.updated, p)
+
+	return &p, nil
+}
+
+func title(s string) notion.PropertyValue {
+	return notion.PropertyValue{Type: notion.PropertyTypeTitle, Title: notion.NewRichTextsP(s)}
+}
+
+func newMemClient() *memClient {
+	return &memClient{
+		db: notion.Database{Id: tasksDB, Properties: notion.PropertyMetaMap{
+			"Name":  {Type: notion.PropertyTypeTitle},
+			"Due":   {Type: notion.PropertyTypeDate},
+			"Done":  {Type: notion.PropertyTypeCheckbox},
+			"Hours": {Type: notion.PropertyTypeNumber},
+			"Priority": {Type: notion.PropertyTypeSelect, Select: &notion.SelectValuesWrapper{
+				Options: notion.SelectValues{{Name: "High"}, {Name: "Low"}},
+			}},
+			"Tags": {Type: notion.PropertyTypeMultiSelect, MultiSelect: &notion.SelectValuesWrapper{
+				Options: notion.SelectValues{{Name: "go"}},
+			}},
+			"Project": {Type: notion.PropertyTypeRelation, Relation: &notion.RelationConfiguration{
+				DatabaseId: projectsDB,
+			}},
+			"Total": {Type: notion.PropertyTypeFormula},
+		}},
+		entries: map[notion.UUID]notion.Pages{
+			tasksDB: {
+				{Id: "t1", Properties: notion.PropertyValueMap{"Name": title("Write docs")}},
+			},
+			projectsDB: {
+				{Id: "p1", Properties: notion.PropertyValueMap{"Name": title("Website")}},
+			},
+		},
+	}
+}
+
+const csvRows = `Task,Due,Done,Hours,Priority,Tags,Project
+Write docs,2024-01-31 14:00,yes,2.5,high,"go, docs",Website
+Ship it,2024-02-01,no,,Low,,
+Broken,yesterday,no,,,,
+Ship it,,,,,,
+Fail,,,,,,
+Unknown,,,,,,Mobile App
+`
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	rows, err := importer.ReadCSV(strings.NewReader(csvRows))
+	require.NoError(t, err)
+	require.Len(t, rows, 6)
+
+	cli := newMemClient()
+
+	report, err := importer.New(cli, importer.Options{
+		Columns:       map[string]string{"Task": "Name"},
+		Key:           "Name",
+		CreateOptions: true,
+		Location:      berlin,
+		Concurrency:   3,
+	}).Import(context.Background(), notion.Id(tasksDB), rows)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{2}, report.Created)
+	assert.Equal(t, []int{1}, report.Updated)
+	assert.Equal(t, map[string][]string{"Tags": {"docs"}}, report.NewOptions)
+
+	errs := []string{}
+	for _, err := range report.Errors {
+		errs = append(errs, err.Error())
+	}
+
+	assert.Equal(t, []string{
+		`row 3: column "Due": invalid date "yesterday"`,
+		`row 4: key "Ship it" is the same as in row 2`,
+		`row 5: bad request`,
+		`row 6: column "Project": no related page titled "Mobile App"`,
+	}, errs)
+	assert.Error(t, report.Err())
+
+	// the new option was added to the existing ones
+	require.Len(t, cli.dbUpdates, 1)
+	assert.Equal(t, []string{"go", "docs"},
+		cli.dbUpdates[0].Properties["Tags"].GetMultiSelect().Options.GetNames())
+
+	// the existing entry was updated
+	require.Len(t, cli.updated, 1)
+
+	p := cli.updated[0]
+	assert.Equal(t, notion.UUID("t1"), p.Id)
+	assert.Equal(t, "Europe/Berlin", *p.Properties["Due"].GetDate().TimeZone)
+	assert.Equal(t, "2024-01-31T13:00:00Z", p.Properties["Due"].GetDate().Start.UTC().Format(time.RFC3339))
+	assert.True(t, p.Properties["Done"].GetCheckbox())
+	assert.Equal(t, 2.5, p.Properties["Hours"].GetNumber())
+	assert.Equal(t, "High", p.Properties["Priority"].GetSelect().Name)
+	assert.Equal(t, []string{"go", "docs"}, p.Properties["Tags"].GetMultiSelect().GetNames())
+	assert.Equal(t, notion.UUIDs{"p1"}, p.Properties["Project"].GetRelation().GetUUIDs())
+
+	require.Len(t, cli.created, 1)
+	assert.Equal(t, tasksDB, *cli.created[0].Parent.DatabaseId)
+	assert.Equal(t, "Ship it", cli.created[0].Title())
+}
+
+func TestImport_DryRun(t *testing.T) {
+	t.Parallel()
+
+	rows, err := importer.ReadJSONL(strings.NewReader(`{"Name": "Write docs", "Hours": 3}
+{"Name": "New", "Done": true, "Priority": "Medium"}
+
+{"Name": "Other", "Tags": ["rust"]}
+{"Name": "Other", "Hours": 1}
+`))
+	require.NoError(t, err)
+
+	cli := newMemClient()
+
+	report, err := importer.New(cli, importer.Options{Key: "Name", DryRun: true}).
+		Import(context.Background(), notion.Id(tasksDB), rows)
+	require.NoError(t, err)
+
+	assert.Empty(t, cli.dbUpdates)
+	assert.Empty(t, cli.created)
+	assert.Empty(t, cli.updated)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.Print(buf))
+	assert.Equal(t, `create 1 rows [4]
+update 1 rows [1]
+error in row 2: column "Priority": unknown option "Medium"
+error in row 3: column "Tags": unknown option "rust"
+`, buf.String())
+
+	_, err = importer.New(cli, importer.Options{}).
+		Import(context.Background(), notion.Id(tasksDB), []importer.Row{{"Total": "1"}})
+	assert.EqualError(t, err, `cannot import column "Total" into formula property "Total"`)
+
+	_, err = importer.New(cli, importer.Options{}).
+		Import(context.Background(), notion.Id(tasksDB), []importer.Row{{"Owner": "me"}})
+	assert.EqualError(t, err, `database has no property "Owner" for column "Owner"`)
+}
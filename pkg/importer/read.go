@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Row maps the columns of a row to their values. Values are strings when read from CSV,
+// otherwise anything JSON can decode, with numbers as json.Number.
+type Row map[string]any
+
+// ReadCSV reads rows from CSV. The first line contains the column names.
+// Empty cells are nil, i.e. they clear the property.
+func ReadCSV(r io.Reader) ([]Row, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("CSV is empty")
+	}
+
+	header := records[0]
+	rows := make([]Row, 0, len(records)-1)
+
+	for _, record := range records[1:] {
+		row := make(Row, len(header))
+
+		for i, col := range header {
+			if record[i] == "" {
+				row[col] = nil
+			} else {
+				row[col] = record[i]
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ReadJSONL reads rows from JSON Lines, i.e. one JSON object per line.
+func ReadJSONL(r io.Reader) ([]Row, error) {
+	rows := []Row{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(sc.Bytes()))
+		dec.UseNumber()
+
+		row := Row{}
+		if err := dec.Decode(&row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading JSON Lines: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// RowError is the error of a single row.
+type RowError struct {
+	// Row is the number of the row, starting at 1.
+	Row int
+	Err error
+}
+
+// Error fulfils the error interface.
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RowError) Unwrap() error { return e.Err }
+
+// Report describes what an import did or, in a dry run, would do.
+type Report struct {
+	// Created are the numbers of the rows that were added as new entries.
+	Created []int
+	// Updated are the numbers of the rows that updated an existing entry with the same key.
+	Updated []int
+	// NewOptions are the names of the select options that were added, by property.
+	NewOptions map[string][]string
+	// Errors are the errors of the rows that could not be imported.
+	Errors []*RowError
+}
+
+// Err returns the errors of all rows as one error or nil if there were none.
+func (r *Report) Err() error {
+	errs := make([]error, len(r.Errors))
+	for i, err := range r.Errors {
+		errs[i] = err
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Report) sort() {
+	sort.Ints(r.Created)
+	sort.Ints(r.Updated)
+	sort.Slice(r.Errors, func(i, j int) bool { return r.Errors[i].Row < r.Errors[j].Row })
+}
+
+// Print prints a human readable summary of the report.
+func (r *Report) Print(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "create %d rows %v\n", len(r.Created), r.Created)
+	fmt.Fprintf(&b, "update %d rows %v\n", len(r.Updated), r.Updated)
+
+	props := make([]string, 0, len(r.NewOptions))
+	for prop := range r.NewOptions {
+		props = append(props, prop)
+	}
+
+	sort.Strings(props)
+
+	for _, prop := range props {
+		fmt.Fprintf(&b, "add options to %q: %s\n", prop, strings.Join(r.NewOptions[prop], ", "))
+	}
+
+	for _, err := range r.Errors {
+		fmt.Fprintf(&b, "error in %v\n", err)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
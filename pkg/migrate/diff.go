@@ -0,0 +1,225 @@
+package migrate
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Diff computes the operations needed to turn the current schema into the desired one.
+//
+// Desired properties are matched to current ones by ID, by the renames in the options or by name.
+// Current properties without a match are removed.
+// The configuration of a property only needs to specify what should be changed,
+// e.g. the database of a relation but not its synced property.
+func Diff(current, desired notion.PropertyMetaMap, opts Options) Plan {
+	nameByID := make(map[string]string, len(current))
+	for name, p := range current {
+		nameByID[p.Id] = name
+	}
+
+	renamedFrom := make(map[string]string, len(opts.Renames))
+	for from, to := range opts.Renames {
+		renamedFrom[to] = from
+	}
+
+	plan := Plan{}
+	matched := map[string]bool{}
+
+	for _, name := range sortedNames(desired) {
+		want := desired[name]
+		want.Name = name
+
+		curName := matchProperty(current, nameByID, renamedFrom, name, want)
+		if curName == "" || matched[curName] {
+			plan = append(plan, Operation{Kind: OperationKindAdd, Name: name, Desired: &want})
+			continue
+		}
+
+		matched[curName] = true
+		cur := current[curName]
+
+		plan = append(plan, diffProperty(curName, cur, want)...)
+	}
+
+	for _, name := range sortedNames(current) {
+		if !matched[name] {
+			cur := current[name]
+			plan = append(plan, Operation{Kind: OperationKindRemove, Name: name, Current: &cur})
+		}
+	}
+
+	return plan
+}
+
+func matchProperty(
+	current notion.PropertyMetaMap, nameByID, renamedFrom map[string]string,
+	name string, want notion.PropertyMeta,
+) string {
+	if curName, ok := nameByID[want.Id]; ok && want.Id != "" {
+		return curName
+	}
+
+	if from, ok := renamedFrom[name]; ok {
+		if _, ok := current[from]; ok {
+			return from
+		}
+	}
+
+	if _, ok := current[name]; ok {
+		return name
+	}
+
+	return ""
+}
+
+func diffProperty(name string, cur, want notion.PropertyMeta) Plan {
+	plan := Plan{}
+
+	if name != want.Name {
+		plan = append(plan, Operation{
+			Kind: OperationKindRename, Name: name, NewName: want.Name,
+			Current: &cur, Desired: &want,
+		})
+	}
+
+	if cur.Type != want.Type {
+		return append(plan, Operation{Kind: OperationKindChangeType, Name: name, Current: &cur, Desired: &want})
+	}
+
+	if want.Type == notion.PropertyTypeStatus {
+		// the options of status properties can't be changed via the API
+		return plan
+	}
+
+	curConf, wantConf := propertyConfig(cur), propertyConfig(want)
+
+	if wantConf["options"] != nil {
+		added, removed := diffOptions(cur, &want)
+		if len(added) > 0 || len(removed) > 0 {
+			plan = append(plan, Operation{
+				Kind: OperationKindChangeOptions, Name: name, Current: &cur, Desired: &want,
+				AddedOptions: added, RemovedOptions: removed,
+			})
+		}
+	}
+
+	delete(wantConf, "options")
+
+	if !isSubset(wantConf, curConf) {
+		plan = append(plan, Operation{Kind: OperationKindChangeConfig, Name: name, Current: &cur, Desired: &want})
+	}
+
+	return plan
+}
+
+// diffOptions returns the names of the options that are added and removed.
+// Options that stay keep their ID and color unless a color is desired.
+func diffOptions(cur notion.PropertyMeta, want *notion.PropertyMeta) (added, removed []string) {
+	curOpts := options(cur)
+
+	// don't change the options of the caller
+	wantOpts := append(notion.SelectValues{}, options(*want)...)
+	setOptions(want, wantOpts)
+
+	existing := make(map[string]notion.SelectValue, len(curOpts))
+	for _, o := range curOpts {
+		existing[o.Name] = o
+	}
+
+	kept := map[string]bool{}
+
+	for i, o := range wantOpts {
+		e, ok := existing[o.Name]
+		if !ok {
+			added = append(added, o.Name)
+			continue
+		}
+
+		kept[o.Name] = true
+
+		if o.Color == nil {
+			wantOpts[i] = e
+		}
+	}
+
+	for _, o := range curOpts {
+		if !kept[o.Name] {
+			removed = append(removed, o.Name)
+		}
+	}
+
+	return added, removed
+}
+
+func setOptions(p *notion.PropertyMeta, opts notion.SelectValues) {
+	switch p.Type {
+	case notion.PropertyTypeSelect:
+		p.Select = &notion.SelectValuesWrapper{Options: opts}
+	case notion.PropertyTypeMultiSelect:
+		p.MultiSelect = &notion.SelectValuesWrapper{Options: opts}
+	}
+}
+
+func options(p notion.PropertyMeta) notion.SelectValues {
+	switch p.Type {
+	case notion.PropertyTypeSelect:
+		return p.GetSelect().Options
+	case notion.PropertyTypeMultiSelect:
+		return p.GetMultiSelect().Options
+	default:
+		return nil
+	}
+}
+
+// propertyConfig returns the type specific configuration of the property.
+func propertyConfig(p notion.PropertyMeta) map[string]any {
+	all := map[string]any{}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil
+	}
+
+	conf, _ := all[string(p.Type)].(map[string]any)
+
+	return conf
+}
+
+// isSubset reports whether everything that is set in want is also set in have.
+func isSubset(want, have any) bool {
+	wantMap, ok := want.(map[string]any)
+	if !ok {
+		return reflect.DeepEqual(want, have)
+	}
+
+	haveMap, ok := have.(map[string]any)
+	if !ok {
+		return len(wantMap) == 0
+	}
+
+	for key, val := range wantMap {
+		if !isSubset(val, haveMap[key]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortedNames(props notion.PropertyMetaMap) []string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
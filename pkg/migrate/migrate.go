@@ -0,0 +1,146 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// ErrNotAllowed is returned if a plan contains destructive operations that are not allowed.
+var ErrNotAllowed = errors.New("destructive operation not allowed")
+
+var _ Client = (*notion.Client)(nil)
+
+// Client is any client that can read and change the schema of a database.
+type Client interface {
+	// GetNotionDatabase returns the database.
+	GetNotionDatabase(ctx context.Context, id notion.Id) (*notion.Database, error)
+	// UpdateNotionDatabaseProperties changes the properties of a database.
+	UpdateNotionDatabaseProperties(ctx context.Context, id notion.Id, props map[string]*notion.PropertyMeta) (
+		*notion.Database, error)
+}
+
+// Options define how the migrator behaves.
+type Options struct {
+	// Renames maps current names of properties to their desired names.
+	// Properties can also be renamed by giving the desired property the ID of the current one.
+	Renames map[string]string
+
+	// AllowRemove allows removing properties and select options, deleting their values.
+	AllowRemove bool
+	// AllowTypeChange allows changing the type of properties, which may convert or delete their values.
+	AllowTypeChange bool
+
+	// DryRun only prints the planned operations instead of applying them.
+	DryRun bool
+
+	// Output is where the plan is printed in a dry run. Defaults to os.Stdout.
+	Output io.Writer
+}
+
+// Migrator migrates the schema of databases.
+type Migrator struct {
+	cli  Client
+	opts Options
+}
+
+// New returns a new migrator.
+func New(cli Client, opts Options) *Migrator {
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	return &Migrator{cli: cli, opts: opts}
+}
+
+// Plan fetches the database and computes the operations needed to migrate its schema.
+func (m *Migrator) Plan(ctx context.Context, id notion.Id, desired notion.PropertyMetaMap) (Plan, error) {
+	db, err := m.cli.GetNotionDatabase(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting database %s: %w", id, err)
+	}
+
+	return Diff(db.Properties, desired, m.opts), nil
+}
+
+// Migrate makes sure the database has the desired properties.
+// In a dry run, the plan is printed instead of applied.
+func (m *Migrator) Migrate(ctx context.Context, id notion.Id, desired notion.PropertyMetaMap) (Plan, error) {
+	plan, err := m.Plan(ctx, id, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.opts.DryRun {
+		return plan, plan.Print(m.opts.Output)
+	}
+
+	return plan, m.Apply(ctx, id, plan)
+}
+
+// Apply applies all operations of the plan in a single request.
+// It returns ErrNotAllowed if the plan contains destructive operations that are not allowed.
+func (m *Migrator) Apply(ctx context.Context, id notion.Id, plan Plan) error {
+	if err := m.check(plan); err != nil {
+		return err
+	}
+
+	if len(plan) == 0 {
+		return nil
+	}
+
+	props, err := updates(plan)
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.cli.UpdateNotionDatabaseProperties(ctx, id, props); err != nil {
+		return fmt.Errorf("updating properties of database %s: %w", id, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) check(plan Plan) error {
+	for _, op := range plan.Destructive() {
+		switch {
+		case op.Kind == OperationKindChangeType && !m.opts.AllowTypeChange:
+			return fmt.Errorf("%w: changing the type of %q (set AllowTypeChange)", ErrNotAllowed, op.Name)
+		case op.Kind != OperationKindChangeType && !m.opts.AllowRemove:
+			return fmt.Errorf("%w: %s %q (set AllowRemove)", ErrNotAllowed, op.Kind, op.Name)
+		}
+	}
+
+	return nil
+}
+
+// updates returns the changes to the properties, keyed by their current name.
+func updates(plan Plan) (map[string]*notion.PropertyMeta, error) {
+	props := map[string]*notion.PropertyMeta{}
+
+	for _, op := range plan {
+		if op.Kind == OperationKindRemove {
+			props[op.Name] = nil
+			continue
+		}
+
+		p, err := op.Desired.WithConfig()
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", op.Name, err)
+		}
+
+		if p.Type == notion.PropertyTypeStatus {
+			// the configuration of status properties can't be changed via the API
+			p.Status = nil
+		}
+
+		// several operations for the same property all carry the desired property
+		props[op.Name] = &p
+	}
+
+	return props, nil
+}
@@ -0,0 +1,146 @@
+package migrate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/faetools/go-notion/pkg/migrate"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dbID notion.Id = "668d797c-76fa-4934-9b05-ad288df2d136"
+
+func selectProp(id string, options ...string) notion.PropertyMeta {
+	opts := notion.SelectValues{}
+	for _, o := range options {
+		color := notion.ColorGreen
+		opts = append(opts, notion.SelectValue{Name: o, Color: &color})
+	}
+
+	return notion.PropertyMeta{
+		Id: id, Type: notion.PropertyTypeSelect,
+		Select: &notion.SelectValuesWrapper{Options: opts},
+	}
+}
+
+func current() notion.PropertyMetaMap {
+	return notion.PropertyMetaMap{
+		"Name":     {Id: "title", Type: notion.PropertyTypeTitle, Title: &map[string]interface{}{}},
+		"Owner":    {Id: "a", Type: notion.PropertyTypePeople, People: &map[string]interface{}{}},
+		"Priority": selectProp("b", "Low", "High"),
+		"Estimate": {Id: "c", Type: notion.PropertyTypeNumber, Number: &notion.NumberConfig{Format: "number"}},
+		"Obsolete": {Id: "d", Type: notion.PropertyTypeCheckbox, Checkbox: &map[string]interface{}{}},
+		"Points":   {Id: "e", Type: notion.PropertyTypeRichText, RichText: &map[string]interface{}{}},
+	}
+}
+
+func desired() notion.PropertyMetaMap {
+	high := selectProp("", "High", "Medium")
+	high.Select.Options[0].Color = nil
+
+	return notion.PropertyMetaMap{
+		"Name":     {Type: notion.PropertyTypeTitle},
+		"Assignee": {Type: notion.PropertyTypePeople},
+		"Priority": high,
+		"Estimate": {Type: notion.PropertyTypeNumber, Number: &notion.NumberConfig{Format: "dollar"}},
+		"Points":   {Type: notion.PropertyTypeNumber, Number: &notion.NumberConfig{Format: "number"}},
+		"Due":      {Type: notion.PropertyTypeDate},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	want := desired()
+
+	plan := migrate.Diff(current(), want, migrate.Options{Renames: map[string]string{"Owner": "Assignee"}})
+
+	assert.Equal(t, `  rename         "Owner" to "Assignee"
+  add            "Due" (date)
+  change-config  "Estimate" of type number
+! change-type    "Points" from rich_text to number
+! change-options "Priority": add "Medium", remove "Low"
+! remove         "Obsolete" (checkbox)`, plan.String())
+
+	assert.Len(t, plan.Destructive(), 3)
+	assert.Equal(t, 1, plan.Count(migrate.OperationKindRename))
+
+	// the desired schema is not changed
+	assert.Nil(t, want["Priority"].Select.Options[0].Color)
+
+	// existing options keep their color
+	assert.Equal(t, notion.ColorGreen, *plan[4].Desired.Select.Options[0].Color)
+
+	// nothing to do
+	assert.Empty(t, migrate.Diff(current(), current(), migrate.Options{}))
+
+	// renaming via ID
+	renamed := current()
+	renamed["Assignee"] = renamed["Owner"]
+	delete(renamed, "Owner")
+
+	plan = migrate.Diff(current(), renamed, migrate.Options{})
+	require.Len(t, plan, 1)
+	assert.Equal(t, migrate.OperationKindRename, plan[0].Kind)
+}
+
+type fakeClient struct {
+	props map[string]*notion.PropertyMeta
+}
+
+func (c *fakeClient) GetNotionDatabase(_ context.Context, id notion.Id) (*notion.Database, error) {
+	return &notion.Database{Id: notion.UUID(id), Properties: current()}, nil
+}
+
+func (c *fakeClient) UpdateNotionDatabaseProperties(
+	_ context.Context, id notion.Id, props map[string]*notion.PropertyMeta,
+) (*notion.Database, error) {
+	c.props = props
+	return &notion.Database{Id: notion.UUID(id)}, nil
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	renames := map[string]string{"Owner": "Assignee"}
+
+	cli := &fakeClient{}
+	out := &bytes.Buffer{}
+
+	plan, err := migrate.New(cli, migrate.Options{Renames: renames, DryRun: true, Output: out}).
+		Migrate(ctx, dbID, desired())
+	require.NoError(t, err)
+	assert.Nil(t, cli.props)
+	assert.Equal(t, plan.String()+"\n", out.String())
+
+	_, err = migrate.New(cli, migrate.Options{Renames: renames}).Migrate(ctx, dbID, desired())
+	assert.ErrorIs(t, err, migrate.ErrNotAllowed)
+	assert.ErrorContains(t, err, `changing the type of "Points"`)
+
+	_, err = migrate.New(cli, migrate.Options{Renames: renames, AllowTypeChange: true}).
+		Migrate(ctx, dbID, desired())
+	assert.ErrorIs(t, err, migrate.ErrNotAllowed)
+	assert.Nil(t, cli.props)
+
+	_, err = migrate.New(cli, migrate.Options{Renames: renames, AllowTypeChange: true, AllowRemove: true}).
+		Migrate(ctx, dbID, desired())
+	require.NoError(t, err)
+
+	b, err := json.Marshal(cli.props)
+	require.NoError(t, err)
+
+	var body map[string]map[string]any
+	require.NoError(t, json.Unmarshal(b, &body))
+
+	assert.Len(t, body, 6)
+	assert.Nil(t, body["Obsolete"])
+	assert.Equal(t, "Assignee", body["Owner"]["name"])
+	assert.Equal(t, map[string]any{}, body["Due"]["date"])
+	assert.Equal(t, map[string]any{"format": "dollar"}, body["Estimate"]["number"])
+	assert.Equal(t, "number", body["Points"]["type"])
+}
@@ -0,0 +1,152 @@
+// Package migrate evolves the schema of a database towards a desired set of properties.
+//
+// The differences between the live and the desired schema are expressed as a plan of operations
+// that can be reviewed before it is applied. Destructive operations, i.e. removing properties
+// or options and changing the type of a property, need to be allowed explicitly.
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// OperationKind defines the kind of operation.
+type OperationKind string
+
+// Defines values for OperationKind.
+const (
+	OperationKindAdd           OperationKind = "add"
+	OperationKindRename        OperationKind = "rename"
+	OperationKindRemove        OperationKind = "remove"
+	OperationKindChangeType    OperationKind = "change-type"
+	OperationKindChangeOptions OperationKind = "change-options"
+	OperationKindChangeConfig  OperationKind = "change-config"
+)
+
+// Operation is a single change to the schema of a database.
+type Operation struct {
+	Kind OperationKind
+
+	// Name is the current name of the property or the name of the new property.
+	Name string
+	// NewName is the name of the property after it has been renamed.
+	NewName string
+
+	// Current is the property as it is now, nil for new properties.
+	Current *notion.PropertyMeta
+	// Desired is the property as it should be, nil for removed properties.
+	Desired *notion.PropertyMeta
+
+	// AddedOptions are the names of new select options.
+	AddedOptions []string
+	// RemovedOptions are the names of select options that will be removed.
+	RemovedOptions []string
+}
+
+// Plan is the list of operations needed to migrate a schema.
+type Plan []Operation
+
+// Destructive returns true if the operation can lose data.
+func (op Operation) Destructive() bool {
+	switch op.Kind {
+	case OperationKindRemove, OperationKindChangeType:
+		return true
+	case OperationKindChangeOptions:
+		return len(op.RemovedOptions) > 0
+	default:
+		return false
+	}
+}
+
+// String returns a human readable description of the operation.
+func (op Operation) String() string {
+	prefix := "  "
+	if op.Destructive() {
+		prefix = "! "
+	}
+
+	switch op.Kind {
+	case OperationKindAdd:
+		return fmt.Sprintf("%s%-14s %q (%s)", prefix, op.Kind, op.Name, op.Desired.Type)
+	case OperationKindRename:
+		return fmt.Sprintf("%s%-14s %q to %q", prefix, op.Kind, op.Name, op.NewName)
+	case OperationKindRemove:
+		return fmt.Sprintf("%s%-14s %q (%s)", prefix, op.Kind, op.Name, op.Current.Type)
+	case OperationKindChangeType:
+		return fmt.Sprintf("%s%-14s %q from %s to %s", prefix, op.Kind, op.Name, op.Current.Type, op.Desired.Type)
+	case OperationKindChangeOptions:
+		parts := []string{}
+		if len(op.AddedOptions) > 0 {
+			parts = append(parts, "add "+quoteAll(op.AddedOptions))
+		}
+
+		if len(op.RemovedOptions) > 0 {
+			parts = append(parts, "remove "+quoteAll(op.RemovedOptions))
+		}
+
+		return fmt.Sprintf("%s%-14s %q: %s", prefix, op.Kind, op.Name, strings.Join(parts, ", "))
+	case OperationKindChangeConfig:
+		return fmt.Sprintf("%s%-14s %q of type %s", prefix, op.Kind, op.Name, op.Desired.Type)
+	default:
+		return fmt.Sprintf("unknown operation %q", op.Kind)
+	}
+}
+
+// String returns a human readable description of the plan.
+// Destructive operations are marked with an exclamation mark.
+func (p Plan) String() string {
+	if len(p) == 0 {
+		return "no changes"
+	}
+
+	lines := make([]string, len(p))
+	for i, op := range p {
+		lines[i] = op.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Print writes the plan to the writer.
+func (p Plan) Print(w io.Writer) error {
+	_, err := fmt.Fprintln(w, p.String())
+	return err
+}
+
+// Count returns the number of operations of the given kind.
+func (p Plan) Count(kind OperationKind) int {
+	count := 0
+
+	for _, op := range p {
+		if op.Kind == kind {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Destructive returns the operations that can lose data.
+func (p Plan) Destructive() Plan {
+	var destructive Plan
+
+	for _, op := range p {
+		if op.Destructive() {
+			destructive = append(destructive, op)
+		}
+	}
+
+	return destructive
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,377 @@
+// Package mirror keeps a copy of databases in SQL tables, e.g. in a local SQLite file for reporting.
+//
+// Every database gets a table with a column per property. Relations, people and multi-select
+// properties get junction tables and all users are kept in a shared users table.
+// After the first sync, only entries that were edited since the last sync are fetched.
+//
+// The package writes SQL as understood by SQLite and registers modernc.org/sqlite,
+// a driver that builds without cgo, under the name "sqlite". Open a file with Open:
+//
+//	db, err := mirror.Open("notion.db")
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/notion"
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+// driverName is the name of the SQLite driver.
+const driverName = "sqlite"
+
+var _ Client = (*notion.Client)(nil)
+
+// Client is any client that can read databases and users.
+type Client interface {
+	// GetNotionDatabase returns the database.
+	GetNotionDatabase(ctx context.Context, id notion.Id) (*notion.Database, error)
+	// GetDatabaseEntries returns the filtered and sorted entries of a database.
+	GetDatabaseEntries(ctx context.Context, id notion.Id, filter *notion.Filter, sorts *notion.Sorts) (
+		notion.Pages, error)
+	// ListAllUsers returns all users of the workspace.
+	ListAllUsers(ctx context.Context) (notion.Users, error)
+}
+
+// Options define how databases are mirrored.
+type Options struct {
+	// Tables maps the IDs of databases to the names of their tables.
+	// Defaults to the titles of the databases in snake case.
+	Tables map[notion.UUID]string
+}
+
+// Mirror copies databases into SQL tables.
+type Mirror struct {
+	cli  Client
+	db   *sql.DB
+	opts Options
+}
+
+// Open opens the SQLite database file, creating it if needed.
+func Open(path string) (*sql.DB, error) {
+	return sql.Open(driverName, path)
+}
+
+// New returns a new mirror writing into the SQL database.
+func New(cli Client, db *sql.DB, opts Options) *Mirror {
+	return &Mirror{cli: cli, db: db, opts: opts}
+}
+
+// Sync mirrors the entries of the database that were edited since the last sync
+// and returns the number of entries that were written.
+// Entries that were deleted are only removed by a full sync.
+func (m *Mirror) Sync(ctx context.Context, id notion.Id) (int, error) {
+	return m.sync(ctx, id, false)
+}
+
+// FullSync mirrors all entries of the database and removes the rows of deleted entries.
+func (m *Mirror) FullSync(ctx context.Context, id notion.Id) (int, error) {
+	return m.sync(ctx, id, true)
+}
+
+func (m *Mirror) sync(ctx context.Context, id notion.Id, full bool) (int, error) {
+	db, err := m.cli.GetNotionDatabase(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("getting database %s: %w", id, err)
+	}
+
+	t := NewTable(*db, m.opts.Tables[db.Id])
+
+	if err := m.migrate(ctx, t); err != nil {
+		return 0, fmt.Errorf("creating table %q: %w", t.Name, err)
+	}
+
+	since, err := m.lastEdited(ctx, db.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	var filter *notion.Filter
+	if !full && since != nil {
+		filter = notion.LastEditedSince(*since)
+	}
+
+	entries, err := m.cli.GetDatabaseEntries(ctx, id, filter, nil)
+	if err != nil {
+		return 0, fmt.Errorf("getting entries of database %s: %w", id, err)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := m.write(ctx, tx, t, entries, full, since); err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("writing into table %q: %w", t.Name, err)
+	}
+
+	return len(entries), tx.Commit()
+}
+
+// migrate creates the tables and adds missing columns to existing tables.
+func (m *Mirror) migrate(ctx context.Context, t *Table) error {
+	stmts := append(append([]string{}, sharedSchema...), t.Schema()...)
+
+	for _, stmt := range stmts {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	existing, err := m.columns(ctx, t.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range t.Columns {
+		if existing[c.Name] {
+			continue
+		}
+
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", quote(t.Name), quote(c.Name), c.Type)
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// columns returns the names of the columns of the table.
+func (m *Mirror) columns(ctx context.Context, table string) (map[string]bool, error) {
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf("SELECT name FROM pragma_table_info(%s)", quoteString(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols := map[string]bool{}
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		cols[name] = true
+	}
+
+	return cols, rows.Err()
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// lastEdited returns the time the last synced entry was edited or nil if the database was never synced.
+func (m *Mirror) lastEdited(ctx context.Context, id notion.UUID) (*time.Time, error) {
+	var s string
+
+	err := m.db.QueryRowContext(ctx,
+		`SELECT "last_edited_time" FROM "_notion_sync" WHERE "database_id" = ?`, string(id)).Scan(&s)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting last sync of %s: %w", id, err)
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// write upserts the entries and their values in junction tables.
+// Edit times only have a precision of minutes, so entries edited at the time of the last sync
+// are fetched again next time.
+func (m *Mirror) write(
+	ctx context.Context, tx *sql.Tx, t *Table, entries notion.Pages, full bool, since *time.Time,
+) error {
+	upsert := t.Upsert()
+	latest := since
+
+	for _, p := range entries {
+		if _, err := tx.ExecContext(ctx, upsert, t.Values(p)...); err != nil {
+			return fmt.Errorf("entry %s: %w", p.Id, err)
+		}
+
+		for _, j := range t.Junctions {
+			if err := writeJunction(ctx, tx, j, p); err != nil {
+				return fmt.Errorf("entry %s: %w", p.Id, err)
+			}
+		}
+
+		if err := writeUsers(ctx, tx, p); err != nil {
+			return fmt.Errorf("entry %s: %w", p.Id, err)
+		}
+
+		if latest == nil || p.LastEditedTime.After(*latest) {
+			edited := p.LastEditedTime
+			latest = &edited
+		}
+	}
+
+	if full {
+		if err := removeOthers(ctx, tx, t, entries); err != nil {
+			return err
+		}
+	}
+
+	if latest == nil {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO "_notion_sync" ("database_id", "table_name", "last_edited_time")
+VALUES (?, ?, ?) ON CONFLICT ("database_id") DO UPDATE SET
+"table_name" = excluded."table_name", "last_edited_time" = excluded."last_edited_time"`,
+		string(t.DatabaseID), t.Name, latest.UTC().Format(time.RFC3339))
+
+	return err
+}
+
+func writeJunction(ctx context.Context, tx *sql.Tx, j Junction, p notion.Page) error {
+	if _, err := tx.ExecContext(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE "page_id" = ?`, quote(j.Name)), string(p.Id)); err != nil {
+		return err
+	}
+
+	insert := fmt.Sprintf(`INSERT OR IGNORE INTO %s ("page_id", %s) VALUES (?, ?)`, quote(j.Name), quote(j.Column))
+
+	for _, v := range junctionValues(p.Properties[j.Property]) {
+		if _, err := tx.ExecContext(ctx, insert, string(p.Id), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeUsers adds the users of people properties and the creators and editors of the page.
+func writeUsers(ctx context.Context, tx *sql.Tx, p notion.Page) error {
+	users := notion.Users{}
+
+	for _, prop := range p.Properties {
+		switch prop.Type {
+		case notion.PropertyTypePeople:
+			users = append(users, prop.GetPeople()...)
+		case notion.PropertyTypeCreatedBy:
+			users = append(users, prop.GetCreatedBy())
+		case notion.PropertyTypeLastEditedBy:
+			users = append(users, prop.GetLastEditedBy())
+		}
+	}
+
+	for _, u := range users {
+		if err := writeUser(ctx, tx, u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upsertUser only overwrites the fields that are known,
+// since users in properties often only have an ID.
+const upsertUser = `INSERT INTO "users" ("id", "name", "email", "type", "avatar_url") VALUES (?, ?, ?, ?, ?)
+ON CONFLICT ("id") DO UPDATE SET
+"name" = coalesce(excluded."name", "name"),
+"email" = coalesce(excluded."email", "email"),
+"type" = coalesce(excluded."type", "type"),
+"avatar_url" = coalesce(excluded."avatar_url", "avatar_url")`
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func writeUser(ctx context.Context, db execer, u notion.User) error {
+	var email, tp any
+	if u.Person != nil && u.Person.Email != "" {
+		email = string(u.Person.Email)
+	}
+
+	if u.Type != nil {
+		tp = string(*u.Type)
+	}
+
+	_, err := db.ExecContext(ctx, upsertUser, string(u.Id), u.Name, email, tp, u.AvatarUrl)
+
+	return err
+}
+
+// removeOthers removes the rows of all entries that are not in the list.
+func removeOthers(ctx context.Context, tx *sql.Tx, t *Table, entries notion.Pages) error {
+	keep := make(map[string]bool, len(entries))
+	for _, p := range entries {
+		keep[string(p.Id)] = true
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT "id" FROM %s`, quote(t.Name)))
+	if err != nil {
+		return err
+	}
+
+	remove := []string{}
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+
+		if !keep[id] {
+			remove = append(remove, id)
+		}
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range remove {
+		for _, j := range t.Junctions {
+			if _, err := tx.ExecContext(ctx,
+				fmt.Sprintf(`DELETE FROM %s WHERE "page_id" = ?`, quote(j.Name)), id); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE "id" = ?`, quote(t.Name)), id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SyncUsers mirrors all users of the workspace.
+func (m *Mirror) SyncUsers(ctx context.Context) error {
+	users, err := m.cli.ListAllUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("listing users: %w", err)
+	}
+
+	if _, err := m.db.ExecContext(ctx, sharedSchema[0]); err != nil {
+		return fmt.Errorf("creating users table: %w", err)
+	}
+
+	for _, u := range users {
+		if err := writeUser(ctx, m.db, u); err != nil {
+			return fmt.Errorf("writing user %s: %w", u.Id, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package mirror_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/mirror"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memClient has a single database and applies the last edited filter of incremental syncs.
+type memClient struct {
+	mu      sync.Mutex
+	entries notion.Pages
+	filters []*notion.Filter
+}
+
+func (c *memClient) GetNotionDatabase(context.Context, notion.Id) (*notion.Database, error) {
+	return &notion.Database{
+		Id:    "00000000-0000-4000-8000-000000000001",
+		Title: notion.NewRichTexts("Tasks"),
+		Properties: notion.PropertyMetaMap{
+			"Name":    {Type: notion.PropertyTypeTitle},
+			"Tags":    {Type: notion.PropertyTypeMultiSelect},
+			"Project": {Type: notion.PropertyTypeRelation},
+		},
+	}, nil
+}
+
+func (c *memClient) GetDatabaseEntries(
+	_ context.Context, _ notion.Id, filter *notion.Filter, _ *notion.Sorts,
+) (notion.Pages, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.filters = append(c.filters, filter)
+
+	if filter == nil {
+		return c.entries, nil
+	}
+
+	since := *filter.Timestamp.LastEditedTime.OnOrAfter
+	entries := notion.Pages{}
+
+	for _, p := range c.entries {
+		if !p.LastEditedTime.Before(since) {
+			entries = append(entries, p)
+		}
+	}
+
+	return entries, nil
+}
+
+func (c *memClient) ListAllUsers(context.Context) (notion.Users, error) { return nil, nil }
+
+func (c *memClient) set(entries ...notion.Page) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = entries
+}
+
+func task(id notion.UUID, edited time.Time, name string, tags []string, projects ...notion.UUID) notion.Page {
+	options := notion.SelectValues{}
+	for _, tag := range tags {
+		options = append(options, notion.SelectValue{Name: tag})
+	}
+
+	refs := notion.References{}
+	for _, p := range projects {
+		refs = append(refs, notion.Reference{Id: p})
+	}
+
+	return notion.Page{
+		Id:             id,
+		LastEditedTime: edited,
+		Properties: notion.PropertyValueMap{
+			"Name":    {Type: notion.PropertyTypeTitle, Title: notion.NewRichTextsP(name)},
+			"Tags":    {Type: notion.PropertyTypeMultiSelect, MultiSelect: &options},
+			"Project": {Type: notion.PropertyTypeRelation, Relation: &refs},
+		},
+	}
+}
+
+func query(t *testing.T, db *sql.DB, q string) []string {
+	t.Helper()
+
+	rows, err := db.Query(q)
+	require.NoError(t, err)
+
+	defer rows.Close()
+
+	res := []string{}
+
+	for rows.Next() {
+		var s string
+		require.NoError(t, rows.Scan(&s))
+		res = append(res, s)
+	}
+
+	require.NoError(t, rows.Err())
+
+	return res
+}
+
+func TestMirror_Sync(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db, err := mirror.Open(filepath.Join(t.TempDir(), "notion.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	morning := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	earlier, later := morning.Add(-time.Hour), morning.Add(time.Hour)
+
+	cli := &memClient{}
+	cli.set(
+		task("t1", morning, "Write docs", []string{"docs", "urgent"}, "p1"),
+		task("t2", earlier, "Fix bug", []string{"bug"}),
+	)
+
+	m := mirror.New(cli, db, mirror.Options{})
+
+	n, err := m.Sync(ctx, "tasks")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Nil(t, cli.filters[0], "first sync should fetch all entries")
+
+	assert.Equal(t, []string{"Write docs", "Fix bug"}, query(t, db, `SELECT "name" FROM "tasks" ORDER BY "id"`))
+	assert.Equal(t, []string{"docs", "urgent"},
+		query(t, db, `SELECT "option" FROM "tasks_tags" WHERE "page_id" = 't1' ORDER BY "option"`))
+
+	// only edited entries are fetched and their junctions are rewritten
+	cli.set(
+		task("t1", later, "Write more docs", []string{"docs"}, "p2"),
+		task("t2", earlier, "Fix bug", []string{"bug"}),
+	)
+
+	n, err = m.Sync(ctx, "tasks")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.NotNil(t, cli.filters[1])
+	assert.Equal(t, morning, *cli.filters[1].Timestamp.LastEditedTime.OnOrAfter)
+
+	assert.Equal(t, []string{"Write more docs", "Fix bug"}, query(t, db, `SELECT "name" FROM "tasks" ORDER BY "id"`))
+	assert.Equal(t, []string{"docs"},
+		query(t, db, `SELECT "option" FROM "tasks_tags" WHERE "page_id" = 't1'`))
+	assert.Equal(t, []string{"p2"},
+		query(t, db, `SELECT "related_id" FROM "tasks_project" WHERE "page_id" = 't1'`))
+
+	// deleted entries are kept by incremental syncs
+	cli.set(task("t1", later, "Write more docs", []string{"docs"}, "p2"))
+
+	_, err = m.Sync(ctx, "tasks")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2"}, query(t, db, `SELECT "id" FROM "tasks" ORDER BY "id"`))
+
+	// and removed by full syncs
+	n, err = m.FullSync(ctx, "tasks")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Nil(t, cli.filters[3])
+
+	assert.Equal(t, []string{"t1"}, query(t, db, `SELECT "id" FROM "tasks"`))
+	assert.Equal(t, []string{"t1"}, query(t, db, `SELECT DISTINCT "page_id" FROM "tasks_tags"`))
+	assert.Equal(t, []string{later.Format(time.RFC3339)},
+		query(t, db, `SELECT "last_edited_time" FROM "_notion_sync"`))
+}
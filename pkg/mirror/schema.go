@@ -0,0 +1,293 @@
+package mirror
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/faetools/go-notion/pkg/export"
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Names of the tables shared by all databases.
+const (
+	UsersTable = "users"
+	SyncTable  = "_notion_sync"
+)
+
+// systemColumns are the columns every table has, before the columns of the properties.
+var systemColumns = []Column{
+	{Name: "id", Type: "TEXT PRIMARY KEY"},
+	{Name: "url", Type: "TEXT"},
+	{Name: "created_time", Type: "TEXT"},
+	{Name: "last_edited_time", Type: "TEXT"},
+	{Name: "archived", Type: "INTEGER"},
+}
+
+// Column is a column of the table of a database.
+type Column struct {
+	Name string
+	// Type is the SQL type of the column.
+	Type string
+	// Property is the name of the property, empty for system columns.
+	Property string
+	// End is set for the column holding the end of a date.
+	End bool
+}
+
+// Junction is a table that holds the values of a property that has several values per entry,
+// i.e. relations, people and multi-select properties.
+type Junction struct {
+	Name     string
+	Property string
+	// Column is the name of the column for the values, next to "page_id".
+	Column string
+}
+
+// Table is the SQL representation of a database.
+type Table struct {
+	Name       string
+	DatabaseID notion.UUID
+	Columns    []Column
+	Junctions  []Junction
+}
+
+// NewTable returns the table for the database.
+// If the name is empty, the title of the database in snake case is used.
+// The columns of the properties are in the order of export.Columns, since Notion does not expose their order.
+func NewTable(db notion.Database, name string) *Table {
+	if name == "" {
+		name = snakeCase(db.Title.Content())
+	}
+
+	if name == "" {
+		name = "database_" + strings.ReplaceAll(string(db.Id), "-", "")
+	}
+
+	t := &Table{Name: name, DatabaseID: db.Id, Columns: append([]Column{}, systemColumns...)}
+	used := map[string]bool{}
+
+	for _, c := range systemColumns {
+		used[c.Name] = true
+	}
+
+	for _, prop := range export.Columns(db.Properties) {
+		meta := db.Properties[prop]
+		col := unique(snakeCase(prop), used)
+
+		switch meta.Type {
+		case notion.PropertyTypeMultiSelect:
+			t.Junctions = append(t.Junctions, Junction{Name: name + "_" + col, Property: prop, Column: "option"})
+		case notion.PropertyTypeRelation:
+			t.Junctions = append(t.Junctions, Junction{Name: name + "_" + col, Property: prop, Column: "related_id"})
+		case notion.PropertyTypePeople:
+			t.Junctions = append(t.Junctions, Junction{Name: name + "_" + col, Property: prop, Column: "user_id"})
+		case notion.PropertyTypeDate:
+			t.Columns = append(t.Columns,
+				Column{Name: col, Type: "TEXT", Property: prop},
+				Column{Name: unique(col+"_end", used), Type: "TEXT", Property: prop, End: true})
+		default:
+			t.Columns = append(t.Columns, Column{Name: col, Type: sqlType(meta.Type), Property: prop})
+		}
+	}
+
+	return t
+}
+
+func sqlType(tp notion.PropertyType) string {
+	switch tp {
+	case notion.PropertyTypeNumber:
+		return "REAL"
+	case notion.PropertyTypeCheckbox:
+		return "INTEGER"
+	default:
+		return "TEXT"
+	}
+}
+
+// unique returns the name or, if it is already used, the name with a number.
+func unique(name string, used map[string]bool) string {
+	if name == "" {
+		name = "property"
+	}
+
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = name + "_" + strconv.Itoa(i)
+	}
+
+	used[candidate] = true
+
+	return candidate
+}
+
+// snakeCase returns the name in lower case with all non-alphanumeric characters replaced by underscores.
+func snakeCase(s string) string {
+	var b strings.Builder
+
+	underscore := false
+
+	for _, r := range strings.TrimSpace(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if underscore && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+
+			b.WriteRune(unicode.ToLower(r))
+			underscore = false
+
+			continue
+		}
+
+		underscore = true
+	}
+
+	return b.String()
+}
+
+// quote returns the quoted SQL identifier.
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Schema returns the statements that create the tables, if they don't exist yet.
+func (t *Table) Schema() []string {
+	cols := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		cols[i] = quote(c.Name) + " " + c.Type
+	}
+
+	stmts := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quote(t.Name), strings.Join(cols, ", ")),
+	}
+
+	for _, j := range t.Junctions {
+		stmts = append(stmts, fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (%q TEXT NOT NULL REFERENCES %s (%q), %s TEXT NOT NULL, PRIMARY KEY (%q, %s))",
+			quote(j.Name), "page_id", quote(t.Name), "id", quote(j.Column), "page_id", quote(j.Column)))
+	}
+
+	return stmts
+}
+
+// sharedSchema are the statements that create the tables shared by all databases.
+var sharedSchema = []string{
+	`CREATE TABLE IF NOT EXISTS "users" ("id" TEXT PRIMARY KEY, "name" TEXT, "email" TEXT, "type" TEXT, "avatar_url" TEXT)`,
+	`CREATE TABLE IF NOT EXISTS "_notion_sync" ("database_id" TEXT PRIMARY KEY, "table_name" TEXT NOT NULL, "last_edited_time" TEXT NOT NULL)`,
+}
+
+// Upsert returns the statement that inserts or updates a row, given the values of the columns.
+func (t *Table) Upsert() string {
+	names := make([]string, len(t.Columns))
+	params := make([]string, len(t.Columns))
+	updates := make([]string, 0, len(t.Columns)-1)
+
+	for i, c := range t.Columns {
+		names[i] = quote(c.Name)
+		params[i] = "?"
+
+		if i > 0 {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", names[i], names[i]))
+		}
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%q) DO UPDATE SET %s",
+		quote(t.Name), strings.Join(names, ", "), strings.Join(params, ", "), "id", strings.Join(updates, ", "))
+}
+
+// Values returns the values of the columns for the page.
+func (t *Table) Values(p notion.Page) []any {
+	vals := make([]any, len(t.Columns))
+
+	for i, c := range t.Columns {
+		if c.Property != "" {
+			vals[i] = value(p.Properties[c.Property], c.End)
+			continue
+		}
+
+		switch c.Name {
+		case "id":
+			vals[i] = string(p.Id)
+		case "url":
+			vals[i] = p.Url
+		case "created_time":
+			vals[i] = formatTime(p.CreatedTime)
+		case "last_edited_time":
+			vals[i] = formatTime(&p.LastEditedTime)
+		case "archived":
+			vals[i] = p.Archived
+		}
+	}
+
+	return vals
+}
+
+func formatTime(t *time.Time) any {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// value returns the SQL value of the property.
+func value(prop notion.PropertyValue, end bool) any {
+	switch prop.Type {
+	case notion.PropertyTypeNumber:
+		if prop.Number == nil {
+			return nil
+		}
+
+		return *prop.Number
+	case notion.PropertyTypeCheckbox:
+		return prop.GetCheckbox()
+	case notion.PropertyTypeDate:
+		switch {
+		case prop.Date == nil:
+			return nil
+		case end && prop.Date.End == nil:
+			return nil
+		case end:
+			return notion.Date{Start: *prop.Date.End, DateOnly: prop.Date.DateOnly}.String()
+		default:
+			return notion.Date{Start: prop.Date.Start, DateOnly: prop.Date.DateOnly}.String()
+		}
+	case notion.PropertyTypeCreatedBy:
+		return string(prop.GetCreatedBy().Id)
+	case notion.PropertyTypeLastEditedBy:
+		return string(prop.GetLastEditedBy().Id)
+	default:
+		v, _ := export.Value(prop, nil)
+		if v == nil {
+			return nil
+		}
+
+		return export.Text(v, ", ")
+	}
+}
+
+// junctionValues returns the values of the property for its junction table.
+func junctionValues(prop notion.PropertyValue) []string {
+	switch prop.Type {
+	case notion.PropertyTypeMultiSelect:
+		return prop.GetMultiSelect().GetNames()
+	case notion.PropertyTypeRelation:
+		ids := []string{}
+		for _, ref := range prop.GetRelation() {
+			ids = append(ids, string(ref.Id))
+		}
+
+		return ids
+	case notion.PropertyTypePeople:
+		ids := []string{}
+		for _, u := range prop.GetPeople() {
+			ids = append(ids, string(u.Id))
+		}
+
+		return ids
+	default:
+		return nil
+	}
+}
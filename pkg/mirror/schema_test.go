@@ -0,0 +1,79 @@
+package mirror_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/mirror"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	db := notion.Database{
+		Id:    "00000000-0000-4000-8000-000000000001",
+		Title: notion.NewRichTexts("My Tasks"),
+		Properties: notion.PropertyMetaMap{
+			"Name":      {Type: notion.PropertyTypeTitle},
+			"URL":       {Type: notion.PropertyTypeUrl},
+			"Due Date":  {Type: notion.PropertyTypeDate},
+			"Estimate":  {Type: notion.PropertyTypeNumber},
+			"Done?":     {Type: notion.PropertyTypeCheckbox},
+			"Tags":      {Type: notion.PropertyTypeMultiSelect},
+			"Project":   {Type: notion.PropertyTypeRelation},
+			"Assignees": {Type: notion.PropertyTypePeople},
+		},
+	}
+
+	table := mirror.NewTable(db, "")
+	assert.Equal(t, "my_tasks", table.Name)
+
+	assert.Equal(t, []string{
+		`CREATE TABLE IF NOT EXISTS "my_tasks" ("id" TEXT PRIMARY KEY, "url" TEXT, "created_time" TEXT, ` +
+			`"last_edited_time" TEXT, "archived" INTEGER, "name" TEXT, "done" INTEGER, ` +
+			`"due_date" TEXT, "due_date_end" TEXT, "estimate" REAL, "url_2" TEXT)`,
+		`CREATE TABLE IF NOT EXISTS "my_tasks_assignees" ("page_id" TEXT NOT NULL REFERENCES "my_tasks" ("id"), ` +
+			`"user_id" TEXT NOT NULL, PRIMARY KEY ("page_id", "user_id"))`,
+		`CREATE TABLE IF NOT EXISTS "my_tasks_project" ("page_id" TEXT NOT NULL REFERENCES "my_tasks" ("id"), ` +
+			`"related_id" TEXT NOT NULL, PRIMARY KEY ("page_id", "related_id"))`,
+		`CREATE TABLE IF NOT EXISTS "my_tasks_tags" ("page_id" TEXT NOT NULL REFERENCES "my_tasks" ("id"), ` +
+			`"option" TEXT NOT NULL, PRIMARY KEY ("page_id", "option"))`,
+	}, table.Schema())
+
+	assert.Equal(t, `INSERT INTO "my_tasks" ("id", "url", "created_time", "last_edited_time", "archived", `+
+		`"name", "done", "due_date", "due_date_end", "estimate", "url_2") `+
+		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT ("id") DO UPDATE SET `+
+		`"url" = excluded."url", "created_time" = excluded."created_time", `+
+		`"last_edited_time" = excluded."last_edited_time", "archived" = excluded."archived", `+
+		`"name" = excluded."name", "done" = excluded."done", "due_date" = excluded."due_date", `+
+		`"due_date_end" = excluded."due_date_end", "estimate" = excluded."estimate", "url_2" = excluded."url_2"`,
+		table.Upsert())
+
+	estimate, done := 2.5, true
+	end := time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)
+	edited := time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, []any{
+		"t1", "https://www.notion.so/t1", nil, "2024-01-15T09:30:00Z", false,
+		"Write docs", true, "2024-01-31", "2024-02-02", 2.5, nil,
+	}, table.Values(notion.Page{
+		Id:             "t1",
+		Url:            "https://www.notion.so/t1",
+		LastEditedTime: edited,
+		Properties: notion.PropertyValueMap{
+			"Name":  {Type: notion.PropertyTypeTitle, Title: notion.NewRichTextsP("Write docs")},
+			"Done?": {Type: notion.PropertyTypeCheckbox, Checkbox: &done},
+			"Due Date": {Type: notion.PropertyTypeDate, Date: &notion.Date{
+				Start: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), End: &end, DateOnly: true,
+			}},
+			"Estimate": {Type: notion.PropertyTypeNumber, Number: &estimate},
+			"URL":      {Type: notion.PropertyTypeUrl},
+		},
+	}))
+
+	assert.Equal(t, "tasks", mirror.NewTable(db, "tasks").Name)
+	assert.Equal(t, "database_00000000000040008000000000000001",
+		mirror.NewTable(notion.Database{Id: db.Id}, "").Name)
+}
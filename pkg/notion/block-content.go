@@ -0,0 +1,158 @@
+package notion
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Content returns the text content of the block.
+func (b Block) Content() string {
+	switch b.Type {
+	case BlockTypeParagraph:
+		return b.Paragraph.RichText.Content()
+	case BlockTypeBulletedListItem:
+		return b.BulletedListItem.RichText.Content()
+	case BlockTypeNumberedListItem:
+		return b.NumberedListItem.RichText.Content()
+	case BlockTypeQuote:
+		return b.Quote.RichText.Content()
+	case BlockTypeToggle:
+		return b.Toggle.RichText.Content()
+	case BlockTypeHeading1:
+		return b.Heading1.RichText.Content()
+	case BlockTypeHeading2:
+		return b.Heading2.RichText.Content()
+	case BlockTypeHeading3:
+		return b.Heading3.RichText.Content()
+	case BlockTypeToDo:
+		return b.ToDo.RichText.Content()
+	case BlockTypeCallout:
+		return b.Callout.RichText.Content()
+	case BlockTypeCode:
+		return b.Code.RichText.Content()
+	case BlockTypeTemplate:
+		return b.Template.RichText.Content()
+	case BlockTypeEquation:
+		return b.Equation.Expression
+	case BlockTypeTableRow:
+		cells := make([]string, len(b.TableRow.Cells))
+		for i, c := range b.TableRow.Cells {
+			cells[i] = c.Content()
+		}
+
+		return strings.Join(cells, " ")
+	case BlockTypeBookmark:
+		return b.Bookmark.Url + " " + b.Bookmark.Caption.Content()
+	case BlockTypeEmbed:
+		return b.Embed.Url + " " + b.Embed.Caption.Content()
+	case BlockTypeLinkPreview:
+		return b.LinkPreview.Url
+	case BlockTypeLinkToPage:
+		return string(b.LinkToPage.ID())
+	case BlockTypeChildPage, BlockTypeChildDatabase:
+		return b.Title()
+	case BlockTypeImage:
+		return fileText(b.Image)
+	case BlockTypeVideo:
+		return fileText(b.Video)
+	case BlockTypeAudio:
+		return fileText(b.Audio)
+	case BlockTypeFile:
+		return fileText(b.File)
+	case BlockTypePdf:
+		return fileText(b.Pdf)
+	default:
+		return ""
+	}
+}
+
+func fileText(f *FileWithCaption) string {
+	s := WithoutQuery(f.URL())
+	if f.Caption != nil {
+		s += " " + f.Caption.Content()
+	}
+
+	return s
+}
+
+// WithoutQuery removes the query from the URL, which contains the signature of files hosted by notion.
+func WithoutQuery(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	u.RawQuery = ""
+
+	return u.String()
+}
+
+// volatileKeys are keys of the content that are either derived from other keys,
+// only present in responses or change without the content changing.
+var volatileKeys = map[string]bool{
+	"plain_text":  true,
+	"href":        true,
+	"expiry_time": true,
+	"children":    true,
+	"table_width": true,
+}
+
+// EqualContent reports whether the content of both blocks is the same.
+// It ignores fields that change without the content changing, e.g. signatures of file URLs.
+func (b Block) EqualContent(other Block) bool {
+	if b.Type != other.Type {
+		return false
+	}
+
+	return reflect.DeepEqual(normalizedContent(b), normalizedContent(other))
+}
+
+func normalizedContent(b Block) any {
+	all := map[string]any{}
+
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil
+	}
+
+	return normalize(all[string(b.Type)])
+}
+
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, val := range v {
+			switch {
+			case volatileKeys[key]:
+				delete(v, key)
+			case key == "url":
+				if s, ok := val.(string); ok {
+					v[key] = WithoutQuery(s)
+				}
+			default:
+				v[key] = normalize(val)
+			}
+		}
+
+		// an empty caption is the same as no caption
+		if c, ok := v["caption"].([]any); ok && len(c) == 0 {
+			delete(v, "caption")
+		}
+
+		return v
+	case []any:
+		for i, elem := range v {
+			v[i] = normalize(elem)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
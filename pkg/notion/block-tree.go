@@ -0,0 +1,318 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrStopWalk can be returned by a BlockNodeVisit to stop the traversal early.
+// It is not returned as an error by the traversal functions.
+var ErrStopWalk = errors.New("stop walking the block tree")
+
+// BlockNode is a block together with its (recursively fetched) children.
+type BlockNode struct {
+	Block
+
+	// Children are the child blocks of the block.
+	Children []*BlockNode `json:"children,omitempty"`
+
+	parent *BlockNode
+}
+
+// BlockTree is the tree of blocks of a page or block.
+type BlockTree struct {
+	// ID is the ID of the page or block whose children make up the tree.
+	ID UUID `json:"id"`
+
+	// Children are the top level blocks of the tree.
+	Children []*BlockNode `json:"children"`
+}
+
+// BlockNodeVisit defines what is to be done when visiting a node.
+type BlockNodeVisit func(n *BlockNode) error
+
+// BlockTreeOptions defines how a block tree is fetched.
+type BlockTreeOptions struct {
+	// MaxDepth limits how deep the tree is fetched, 1 meaning only the top level blocks.
+	// Zero means there is no limit.
+	MaxDepth int
+
+	// Concurrency is the maximum number of requests made in parallel.
+	// Zero or one means all requests are made sequentially.
+	Concurrency int
+
+	// IncludeChildPages defines whether the content of child pages should be included.
+	IncludeChildPages bool
+}
+
+// GetBlockTree fetches all blocks of a given page or block recursively.
+func (c Client) GetBlockTree(ctx context.Context, id Id, opts BlockTreeOptions) (*BlockTree, error) {
+	return NewBlockTreeBuilder(c.GetAllBlocks, opts).Build(ctx, id)
+}
+
+// BlockTreeBuilder builds block trees by recursively fetching all children.
+type BlockTreeBuilder struct {
+	getAllBlocks func(ctx context.Context, id Id) (Blocks, error)
+	opts         BlockTreeOptions
+}
+
+// NewBlockTreeBuilder returns a builder that uses the function to get the children of a block.
+// The function has the same signature as Getter.GetAllBlocks.
+func NewBlockTreeBuilder(
+	getAllBlocks func(ctx context.Context, id Id) (Blocks, error), opts BlockTreeOptions,
+) *BlockTreeBuilder {
+	return &BlockTreeBuilder{getAllBlocks: getAllBlocks, opts: opts}
+}
+
+// Build fetches the tree of blocks for the page or block with the given ID.
+func (b *BlockTreeBuilder) Build(ctx context.Context, id Id) (*BlockTree, error) {
+	eg, ctx := errgroup.WithContext(ctx)
+	if b.opts.Concurrency > 1 {
+		eg.SetLimit(b.opts.Concurrency)
+	}
+
+	tree := &BlockTree{ID: UUID(id)}
+
+	eg.Go(func() error {
+		children, err := b.fetch(ctx, eg, id, nil, 1)
+		if err != nil {
+			return err
+		}
+
+		tree.Children = children
+
+		return nil
+	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+func (b *BlockTreeBuilder) fetch(
+	ctx context.Context, eg *errgroup.Group, id Id, parent *BlockNode, depth int,
+) ([]*BlockNode, error) {
+	blocks, err := b.getAllBlocks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*BlockNode, len(blocks))
+	for i, block := range blocks {
+		nodes[i] = &BlockNode{Block: block, parent: parent}
+	}
+
+	if b.opts.MaxDepth > 0 && depth >= b.opts.MaxDepth {
+		return nodes, nil
+	}
+
+	for _, n := range nodes {
+		if !b.shouldDescend(n) {
+			continue
+		}
+
+		n := n
+		fetchChildren := func() error {
+			children, err := b.fetch(ctx, eg, Id(n.Id), n, depth+1)
+			if err != nil {
+				return fmt.Errorf("getting children of block %s: %w", n.Id, err)
+			}
+
+			// each node is only ever written by one goroutine
+			n.Children = children
+
+			return nil
+		}
+
+		// if we can't start another goroutine, we fetch the children ourselves
+		// so that we never block while holding a slot of the group
+		if b.opts.Concurrency > 1 && eg.TryGo(fetchChildren) {
+			continue
+		}
+
+		if err := fetchChildren(); err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, nil
+}
+
+func (b *BlockTreeBuilder) shouldDescend(n *BlockNode) bool {
+	switch n.Type {
+	case BlockTypeChildDatabase:
+		// entries of a database are not blocks
+		return false
+	case BlockTypeChildPage:
+		return b.opts.IncludeChildPages
+	default:
+		return n.HasChildren
+	}
+}
+
+// NewBlockNode returns a node for the block with the given children.
+// It can be used to describe a desired tree of blocks.
+func NewBlockNode(b Block, children ...*BlockNode) *BlockNode {
+	n := &BlockNode{Block: b, Children: children}
+	linkParents(children, n)
+
+	return n
+}
+
+// NewBlockTree returns a tree of the given nodes as children of the page or block with the ID.
+func NewBlockTree(id UUID, children ...*BlockNode) *BlockTree {
+	linkParents(children, nil)
+
+	return &BlockTree{ID: id, Children: children}
+}
+
+// Parent returns the parent node or nil if the node is at the top level.
+func (n *BlockNode) Parent() *BlockNode { return n.parent }
+
+// Depth returns the depth of the node, 1 being the top level.
+func (n *BlockNode) Depth() int {
+	depth := 1
+	for p := n.parent; p != nil; p = p.parent {
+		depth++
+	}
+
+	return depth
+}
+
+// Walk visits all nodes in pre-order, i.e. the parent before its children.
+func (t *BlockTree) Walk(visit BlockNodeVisit) error {
+	return ignoreStop(walkPreOrder(t.Children, visit))
+}
+
+// WalkPostOrder visits all nodes in post-order, i.e. the children before their parent.
+func (t *BlockTree) WalkPostOrder(visit BlockNodeVisit) error {
+	return ignoreStop(walkPostOrder(t.Children, visit))
+}
+
+func walkPreOrder(nodes []*BlockNode, visit BlockNodeVisit) error {
+	for _, n := range nodes {
+		if err := visit(n); err != nil {
+			return err
+		}
+
+		if err := walkPreOrder(n.Children, visit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func walkPostOrder(nodes []*BlockNode, visit BlockNodeVisit) error {
+	for _, n := range nodes {
+		if err := walkPostOrder(n.Children, visit); err != nil {
+			return err
+		}
+
+		if err := visit(n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ignoreStop(err error) error {
+	if errors.Is(err, ErrStopWalk) {
+		return nil
+	}
+
+	return err
+}
+
+// Find returns the node of the block with the given ID or nil if there is none.
+func (t *BlockTree) Find(id UUID) *BlockNode {
+	var found *BlockNode
+
+	_ = t.Walk(func(n *BlockNode) error {
+		if n.Id == id {
+			found = n
+			return ErrStopWalk
+		}
+
+		return nil
+	})
+
+	return found
+}
+
+// FindByType returns all nodes of blocks of the given type in pre-order.
+func (t *BlockTree) FindByType(tp BlockType) []*BlockNode {
+	var nodes []*BlockNode
+
+	_ = t.Walk(func(n *BlockNode) error {
+		if n.Type == tp {
+			nodes = append(nodes, n)
+		}
+
+		return nil
+	})
+
+	return nodes
+}
+
+// Parent returns the parent node of the block with the given ID.
+// It returns nil if the block is at the top level or not part of the tree.
+func (t *BlockTree) Parent(id UUID) *BlockNode {
+	if n := t.Find(id); n != nil {
+		return n.parent
+	}
+
+	return nil
+}
+
+// Flatten returns all blocks of the tree in pre-order.
+func (t *BlockTree) Flatten() Blocks {
+	var blocks Blocks
+
+	_ = t.Walk(func(n *BlockNode) error {
+		blocks = append(blocks, n.Block)
+		return nil
+	})
+
+	return blocks
+}
+
+// Len returns the number of blocks in the tree.
+func (t *BlockTree) Len() int {
+	count := 0
+
+	_ = t.Walk(func(*BlockNode) error {
+		count++
+		return nil
+	})
+
+	return count
+}
+
+type blockTree BlockTree
+
+// UnmarshalJSON fulfils json.Unmarshaler.
+// Its purpose is to restore the links from each node to its parent.
+func (t *BlockTree) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, (*blockTree)(t)); err != nil {
+		return err
+	}
+
+	linkParents(t.Children, nil)
+
+	return nil
+}
+
+func linkParents(nodes []*BlockNode, parent *BlockNode) {
+	for _, n := range nodes {
+		n.parent = parent
+		linkParents(n.Children, n)
+	}
+}
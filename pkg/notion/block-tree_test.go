@@ -0,0 +1,89 @@
+package notion_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/faetools/go-notion-example/fake"
+	. "github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBlockTree(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tree, err := fake.NotionClient.GetBlockTree(ctx, fake.ExamplePageID, BlockTreeOptions{})
+	require.NoError(t, err)
+
+	concurrent, err := fake.NotionClient.GetBlockTree(ctx, fake.ExamplePageID,
+		BlockTreeOptions{Concurrency: 4})
+	require.NoError(t, err)
+
+	assert.Equal(t, tree.Flatten(), concurrent.Flatten())
+
+	topLevel, err := fake.NotionClient.GetAllBlocks(ctx, fake.ExamplePageID)
+	require.NoError(t, err)
+
+	assert.Len(t, tree.Children, len(topLevel))
+	assert.Greater(t, tree.Len(), len(topLevel), "expected nested blocks")
+
+	// every node is reachable through its parent
+	assert.NoError(t, tree.Walk(func(n *BlockNode) error {
+		if p := n.Parent(); p != nil {
+			assert.Contains(t, p.Children, n)
+			assert.Equal(t, p.Depth()+1, n.Depth())
+		} else {
+			assert.Equal(t, 1, n.Depth())
+		}
+
+		return nil
+	}))
+
+	// post-order visits children before their parents
+	seen := map[UUID]bool{}
+	assert.NoError(t, tree.WalkPostOrder(func(n *BlockNode) error {
+		for _, child := range n.Children {
+			assert.True(t, seen[child.Id])
+		}
+
+		seen[n.Id] = true
+
+		return nil
+	}))
+
+	for _, n := range tree.FindByType(BlockTypeParagraph) {
+		assert.Equal(t, BlockTypeParagraph, n.Type)
+	}
+
+	first := tree.Children[0]
+	assert.Equal(t, first, tree.Find(first.Id))
+	assert.Nil(t, tree.Parent(first.Id))
+
+	if len(first.Children) > 0 {
+		assert.Equal(t, first, tree.Parent(first.Children[0].Id))
+	}
+
+	shallow, err := fake.NotionClient.GetBlockTree(ctx, fake.ExamplePageID, BlockTreeOptions{MaxDepth: 1})
+	require.NoError(t, err)
+	assert.Equal(t, len(topLevel), shallow.Len())
+
+	// JSON round trip
+	b, err := json.Marshal(tree)
+	require.NoError(t, err)
+
+	got := &BlockTree{}
+	require.NoError(t, json.Unmarshal(b, got))
+
+	assert.Equal(t, tree.Len(), got.Len())
+	assert.NoError(t, got.Walk(func(n *BlockNode) error {
+		if p := n.Parent(); p != nil {
+			assert.Contains(t, p.Children, n)
+		}
+
+		return nil
+	}))
+}
@@ -3,6 +3,7 @@ package notion
 import (
 	"errors"
 	"fmt"
+	"net/url"
 )
 
 var errNoType = errors.New("could not determine type of block")
@@ -84,3 +85,417 @@ func (b *Block) Validate() error {
 
 	return nil
 }
+
+// There are no constructors for these block types:
+//   - child_page and child_database blocks are created along with the page or database
+//     they stand for, see Client.CreateNotionPage and Client.CreateNotionDatabase.
+//   - template blocks can no longer be created through the API.
+//   - link_preview and unsupported blocks are only ever returned by the API.
+
+// NewParagraphBlock returns a paragraph block with the given text.
+func NewParagraphBlock(txt string) Block {
+	return Block{
+		Object:    "block",
+		Type:      BlockTypeParagraph,
+		Paragraph: NewParagraph(txt),
+	}
+}
+
+// NewHeading returns a heading block of the given level (1, 2 or 3).
+func NewHeading(level int, txt string) (Block, error) {
+	h := &Heading{
+		Color:    ColorDefault,
+		RichText: NewRichTexts(txt),
+	}
+
+	switch level {
+	case 1:
+		return Block{Object: "block", Type: BlockTypeHeading1, Heading1: h}, nil
+	case 2:
+		return Block{Object: "block", Type: BlockTypeHeading2, Heading2: h}, nil
+	case 3:
+		return Block{Object: "block", Type: BlockTypeHeading3, Heading3: h}, nil
+	default:
+		return Block{}, fmt.Errorf("invalid heading level %d, must be 1, 2 or 3", level)
+	}
+}
+
+// NewBulletedListItem returns a bulleted list item with the given text.
+func NewBulletedListItem(txt string) Block {
+	return Block{
+		Object:           "block",
+		Type:             BlockTypeBulletedListItem,
+		BulletedListItem: NewParagraph(txt),
+	}
+}
+
+// NewNumberedListItem returns a numbered list item with the given text.
+func NewNumberedListItem(txt string) Block {
+	return Block{
+		Object:           "block",
+		Type:             BlockTypeNumberedListItem,
+		NumberedListItem: NewParagraph(txt),
+	}
+}
+
+// NewToDo returns a to-do block with the given text.
+func NewToDo(txt string, checked bool) Block {
+	return Block{
+		Object: "block",
+		Type:   BlockTypeToDo,
+		ToDo: &ToDo{
+			Checked:  checked,
+			Color:    ColorDefault,
+			RichText: NewRichTexts(txt),
+		},
+	}
+}
+
+// NewToggle returns a toggle block with the given text.
+func NewToggle(txt string) Block {
+	return Block{
+		Object: "block",
+		Type:   BlockTypeToggle,
+		Toggle: NewParagraph(txt),
+	}
+}
+
+// NewQuote returns a quote block with the given text.
+func NewQuote(txt string) Block {
+	return Block{
+		Object: "block",
+		Type:   BlockTypeQuote,
+		Quote:  NewParagraph(txt),
+	}
+}
+
+// NewCallout returns a callout block with the given text.
+// Without an icon, Notion shows its default icon.
+func NewCallout(txt string, icon *Icon) Block {
+	return Block{
+		Object: "block",
+		Type:   BlockTypeCallout,
+		Callout: &Callout{
+			Color:    ColorDefault,
+			Icon:     icon,
+			RichText: NewRichTexts(txt),
+		},
+	}
+}
+
+// NewCode returns a code block with the given source code.
+func NewCode(lang CodeLanguage, src string) (Block, error) {
+	if !lang.Valid() {
+		return Block{}, fmt.Errorf("unsupported code language %q", lang)
+	}
+
+	return Block{
+		Object: "block",
+		Type:   BlockTypeCode,
+		Code: &Code{
+			Language: lang,
+			RichText: NewRichTexts(src),
+		},
+	}, nil
+}
+
+// NewEquation returns an equation block with the given KaTeX expression.
+func NewEquation(expression string) (Block, error) {
+	if expression == "" {
+		return Block{}, errors.New("equation needs an expression")
+	}
+
+	return Block{
+		Object:   "block",
+		Type:     BlockTypeEquation,
+		Equation: &Equation{Expression: expression},
+	}, nil
+}
+
+// NewDivider returns a divider block.
+func NewDivider() Block {
+	return Block{
+		Object:  "block",
+		Type:    BlockTypeDivider,
+		Divider: &map[string]interface{}{},
+	}
+}
+
+// NewBreadcrumb returns a breadcrumb block.
+func NewBreadcrumb() Block {
+	return Block{
+		Object:     "block",
+		Type:       BlockTypeBreadcrumb,
+		Breadcrumb: &map[string]interface{}{},
+	}
+}
+
+// NewTableOfContents returns a table of contents block.
+func NewTableOfContents() Block {
+	return Block{
+		Object:          "block",
+		Type:            BlockTypeTableOfContents,
+		TableOfContents: &TableOfContents{Color: ColorDefault},
+	}
+}
+
+// NewTable returns a table block with the given rows as children.
+// If header is true, the first row is shown as column header.
+func NewTable(rows [][]string, header bool) (Block, error) {
+	if len(rows) == 0 {
+		return Block{}, errors.New("table needs at least one row")
+	}
+
+	width := len(rows[0])
+	if width == 0 {
+		return Block{}, errors.New("table needs at least one column")
+	}
+
+	children := make(Blocks, len(rows))
+
+	for i, row := range rows {
+		if len(row) != width {
+			return Block{}, fmt.Errorf("row %d has %d cells, expected %d", i, len(row), width)
+		}
+
+		children[i] = NewTableRow(row...)
+	}
+
+	return Block{
+		Object: "block",
+		Type:   BlockTypeTable,
+		Table: &Table{
+			Children:        children,
+			HasColumnHeader: header,
+			TableWidth:      width,
+		},
+	}, nil
+}
+
+// NewTableRow returns a table row block with the given cells.
+func NewTableRow(cells ...string) Block {
+	return Block{
+		Object:   "block",
+		Type:     BlockTypeTableRow,
+		TableRow: &TableRow{Cells: mapSlice(cells, NewRichTexts)},
+	}
+}
+
+// NewColumnList returns a column list block with one column per argument.
+func NewColumnList(columns ...Blocks) (Block, error) {
+	if len(columns) < 2 {
+		return Block{}, fmt.Errorf("column list needs at least two columns, got %d", len(columns))
+	}
+
+	children := make(Blocks, len(columns))
+
+	for i, col := range columns {
+		if len(col) == 0 {
+			return Block{}, fmt.Errorf("column %d has no content", i)
+		}
+
+		for _, b := range col {
+			if b.Type == BlockTypeColumn || b.Type == BlockTypeColumnList {
+				return Block{}, fmt.Errorf("column %d contains a block of type %q", i, b.Type)
+			}
+		}
+
+		children[i] = Block{
+			Object: "block",
+			Type:   BlockTypeColumn,
+			Column: &map[string]interface{}{"children": col},
+		}
+	}
+
+	return Block{
+		Object:     "block",
+		Type:       BlockTypeColumnList,
+		ColumnList: &map[string]interface{}{"children": children},
+	}, nil
+}
+
+// NewLinkToPage returns a block linking to the page with the given ID.
+func NewLinkToPage(id UUID) Block {
+	return Block{
+		Object:     "block",
+		Type:       BlockTypeLinkToPage,
+		LinkToPage: &LinkToPage{Type: LinkToPageTypePageId, PageId: &id},
+	}
+}
+
+// NewLinkToDatabase returns a block linking to the database with the given ID.
+func NewLinkToDatabase(id UUID) Block {
+	return Block{
+		Object:     "block",
+		Type:       BlockTypeLinkToPage,
+		LinkToPage: &LinkToPage{Type: LinkToPageTypeDatabaseId, DatabaseId: &id},
+	}
+}
+
+// NewSyncedBlock returns an original synced block with the given content.
+// Its content can be shown elsewhere with NewSyncedBlockCopy.
+func NewSyncedBlock(children ...Block) (Block, error) {
+	if len(children) == 0 {
+		return Block{}, errors.New("synced block has no content")
+	}
+
+	return Block{
+		Object:      "block",
+		Type:        BlockTypeSyncedBlock,
+		SyncedBlock: &SyncedBlock{Children: children},
+	}, nil
+}
+
+// NewSyncedBlockCopy returns a synced block that shows the content
+// of the original synced block with the given ID.
+func NewSyncedBlockCopy(original UUID) Block {
+	return Block{
+		Object: "block",
+		Type:   BlockTypeSyncedBlock,
+		SyncedBlock: &SyncedBlock{SyncedFrom: &SyncedFrom{
+			Type:    SyncedFromTypeBlockId,
+			BlockId: &original,
+		}},
+	}
+}
+
+// NewBookmark returns a bookmark block for the given URL.
+func NewBookmark(rawURL, caption string) (Block, error) {
+	if err := validateExternalURL(rawURL); err != nil {
+		return Block{}, fmt.Errorf("bookmark: %w", err)
+	}
+
+	return Block{
+		Object:   "block",
+		Type:     BlockTypeBookmark,
+		Bookmark: &Embed{Url: rawURL, Caption: newCaption(caption)},
+	}, nil
+}
+
+// NewEmbed returns an embed block for the given URL.
+func NewEmbed(rawURL, caption string) (Block, error) {
+	if err := validateExternalURL(rawURL); err != nil {
+		return Block{}, fmt.Errorf("embed: %w", err)
+	}
+
+	return Block{
+		Object: "block",
+		Type:   BlockTypeEmbed,
+		Embed:  &Embed{Url: rawURL, Caption: newCaption(caption)},
+	}, nil
+}
+
+// NewImageExternal returns an image block showing the external file.
+func NewImageExternal(rawURL, caption string) (Block, error) {
+	f, err := newExternalFileWithCaption(rawURL, caption)
+	if err != nil {
+		return Block{}, fmt.Errorf("image: %w", err)
+	}
+
+	return Block{Object: "block", Type: BlockTypeImage, Image: f}, nil
+}
+
+// NewVideoExternal returns a video block showing the external file.
+func NewVideoExternal(rawURL, caption string) (Block, error) {
+	f, err := newExternalFileWithCaption(rawURL, caption)
+	if err != nil {
+		return Block{}, fmt.Errorf("video: %w", err)
+	}
+
+	return Block{Object: "block", Type: BlockTypeVideo, Video: f}, nil
+}
+
+// NewAudioExternal returns an audio block for the external file.
+func NewAudioExternal(rawURL, caption string) (Block, error) {
+	f, err := newExternalFileWithCaption(rawURL, caption)
+	if err != nil {
+		return Block{}, fmt.Errorf("audio: %w", err)
+	}
+
+	return Block{Object: "block", Type: BlockTypeAudio, Audio: f}, nil
+}
+
+// NewFileExternal returns a file block for the external file.
+func NewFileExternal(rawURL, caption string) (Block, error) {
+	f, err := newExternalFileWithCaption(rawURL, caption)
+	if err != nil {
+		return Block{}, fmt.Errorf("file: %w", err)
+	}
+
+	return Block{Object: "block", Type: BlockTypeFile, File: f}, nil
+}
+
+// NewPdfExternal returns a PDF block for the external file.
+func NewPdfExternal(rawURL, caption string) (Block, error) {
+	f, err := newExternalFileWithCaption(rawURL, caption)
+	if err != nil {
+		return Block{}, fmt.Errorf("pdf: %w", err)
+	}
+
+	return Block{Object: "block", Type: BlockTypePdf, Pdf: f}, nil
+}
+
+func newExternalFileWithCaption(rawURL, caption string) (*FileWithCaption, error) {
+	if err := validateExternalURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	f := &FileWithCaption{
+		Type:     FileWithCaptionTypeExternal,
+		External: &ExternalFile{Url: rawURL},
+	}
+
+	if caption != "" {
+		f.Caption = NewRichTextsP(caption)
+	}
+
+	return f, nil
+}
+
+func newCaption(caption string) RichTexts {
+	if caption == "" {
+		return RichTexts{}
+	}
+
+	return NewRichTexts(caption)
+}
+
+func validateExternalURL(rawURL string) error {
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid URL %q: scheme must be http or https", rawURL)
+	}
+
+	return nil
+}
+
+// Valid reports whether the code language is supported by notion.
+func (l CodeLanguage) Valid() bool {
+	switch l {
+	case CodeLanguageAbap, CodeLanguageArduino, CodeLanguageBash, CodeLanguageBasic,
+		CodeLanguageC, CodeLanguageC1, CodeLanguageC2, CodeLanguageClojure,
+		CodeLanguageCoffeescript, CodeLanguageCss, CodeLanguageDart, CodeLanguageDiff,
+		CodeLanguageDocker, CodeLanguageElixir, CodeLanguageElm, CodeLanguageErlang,
+		CodeLanguageF, CodeLanguageFlow, CodeLanguageFortran, CodeLanguageGherkin,
+		CodeLanguageGlsl, CodeLanguageGo, CodeLanguageGraphql, CodeLanguageGroovy,
+		CodeLanguageHaskell, CodeLanguageHtml, CodeLanguageJava, CodeLanguageJavaccc,
+		CodeLanguageJavascript, CodeLanguageJson, CodeLanguageJulia, CodeLanguageKotlin,
+		CodeLanguageLatex, CodeLanguageLess, CodeLanguageLisp, CodeLanguageLivescript,
+		CodeLanguageLua, CodeLanguageMakefile, CodeLanguageMarkdown, CodeLanguageMarkup,
+		CodeLanguageMatlab, CodeLanguageMermaid, CodeLanguageNix, CodeLanguageObjectiveC,
+		CodeLanguageOcaml, CodeLanguagePascal, CodeLanguagePerl, CodeLanguagePhp,
+		CodeLanguagePlainText, CodeLanguagePowershell, CodeLanguageProlog, CodeLanguageProtobuf,
+		CodeLanguagePython, CodeLanguageR, CodeLanguageReason, CodeLanguageRuby,
+		CodeLanguageRust, CodeLanguageSass, CodeLanguageScala, CodeLanguageScheme,
+		CodeLanguageScss, CodeLanguageShell, CodeLanguageSql, CodeLanguageSwift,
+		CodeLanguageTypescript, CodeLanguageVbNet, CodeLanguageVerilog, CodeLanguageVhdl,
+		CodeLanguageVisualBasic, CodeLanguageWebassembly, CodeLanguageXml, CodeLanguageYaml:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,6 +2,7 @@ package notion_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -404,8 +405,10 @@ func validateCallout(c *Callout) error {
 		return err
 	}
 
-	if err := validateIcon(c.Icon); err != nil {
-		return err
+	if c.Icon != nil {
+		if err := validateIcon(*c.Icon); err != nil {
+			return err
+		}
 	}
 
 	return validateRichTexts(c.RichText)
@@ -528,3 +531,81 @@ func validateMention(m *Mention) error {
 		return fmt.Errorf("unknown mention type %q", m.Type)
 	}
 }
+
+func TestNewBlocks(t *testing.T) {
+	t.Parallel()
+
+	h, err := NewHeading(2, "Title")
+	assert.NoError(t, err)
+
+	code, err := NewCode(CodeLanguageGo, "package main")
+	assert.NoError(t, err)
+
+	img, err := NewImageExternal("https://example.com/image.png", "an image")
+	assert.NoError(t, err)
+
+	table, err := NewTable([][]string{{"a", "b"}, {"c", "d"}}, true)
+	assert.NoError(t, err)
+
+	cols, err := NewColumnList(Blocks{NewParagraphBlock("left")}, Blocks{NewParagraphBlock("right")})
+	assert.NoError(t, err)
+
+	synced, err := NewSyncedBlock(NewParagraphBlock("shared"))
+	assert.NoError(t, err)
+
+	emoji := "💡"
+
+	for _, b := range []Block{
+		NewParagraphBlock("foo"), h, NewToDo("bar", true), code, img, table, cols,
+		NewDivider(), NewLinkToPage("96245c8f-1784-44a4-82ad-1941127c3ec3"),
+		NewCallout("note", nil), NewCallout("tip", &Icon{Type: IconTypeEmoji, Emoji: &emoji}),
+		synced, NewSyncedBlockCopy("96245c8f-1784-44a4-82ad-1941127c3ec3"),
+	} {
+		tp := b.Type
+		b.Type = ""
+
+		assert.NoError(t, b.Validate())
+		assert.Equal(t, tp, b.Type)
+	}
+
+	assert.Equal(t, BlockTypeHeading2, h.Type)
+	assert.Equal(t, ColorDefault, h.Heading2.Color)
+
+	assert.Equal(t, 2, table.Table.TableWidth)
+	assert.True(t, table.Table.HasColumnHeader)
+	assert.Len(t, table.Table.Children, 2)
+	assert.Equal(t, "d", table.Table.Children[1].TableRow.Cells[1].Content())
+
+	b, err := json.Marshal(NewCallout("note", nil).Callout)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), `"icon"`)
+
+	b, err = json.Marshal(synced.SyncedBlock)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"synced_from":null`)
+}
+
+func TestNewBlocks_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewHeading(4, "Title")
+	assert.EqualError(t, err, "invalid heading level 4, must be 1, 2 or 3")
+
+	_, err = NewCode("cobol", "")
+	assert.EqualError(t, err, `unsupported code language "cobol"`)
+
+	_, err = NewTable([][]string{{"a", "b"}, {"c"}}, false)
+	assert.EqualError(t, err, "row 1 has 1 cells, expected 2")
+
+	_, err = NewTable(nil, false)
+	assert.EqualError(t, err, "table needs at least one row")
+
+	_, err = NewImageExternal("not a url", "")
+	assert.EqualError(t, err, `image: invalid URL "not a url": parse "not a url": invalid URI for request`)
+
+	_, err = NewSyncedBlock()
+	assert.EqualError(t, err, "synced block has no content")
+
+	_, err = NewColumnList(Blocks{NewDivider()})
+	assert.EqualError(t, err, "column list needs at least two columns, got 1")
+}
@@ -19,26 +19,30 @@ import (
 // operation paths
 
 const (
-	opPathDeleteBlockFormat    = "./v1/blocks/%s"
-	opPathGetBlockFormat       = "./v1/blocks/%s"
-	opPathUpdateablockFormat   = "./v1/blocks/%s"
-	opPathGetBlocksFormat      = "./v1/blocks/%s/children"
-	opPathAppendBlocksFormat   = "./v1/blocks/%s/children"
-	opPathGetDatabaseFormat    = "./v1/databases/%s"
-	opPathUpdateDatabaseFormat = "./v1/databases/%s"
-	opPathQueryDatabaseFormat  = "./v1/databases/%s/query"
-	opPathDeletePageFormat     = "./v1/pages/%s"
-	opPathGetPageFormat        = "./v1/pages/%s"
-	opPathUpdatePageFormat     = "./v1/pages/%s"
-	opPathGetUserFormat        = "./v1/users/%s"
+	opPathDeleteBlockFormat        = "./v1/blocks/%s"
+	opPathGetBlockFormat           = "./v1/blocks/%s"
+	opPathUpdateablockFormat       = "./v1/blocks/%s"
+	opPathGetBlocksFormat          = "./v1/blocks/%s/children"
+	opPathAppendBlocksFormat       = "./v1/blocks/%s/children"
+	opPathGetDatabaseFormat        = "./v1/databases/%s"
+	opPathUpdateDatabaseFormat     = "./v1/databases/%s"
+	opPathQueryDatabaseFormat      = "./v1/databases/%s/query"
+	opPathGetFileUploadFormat      = "./v1/file_uploads/%s"
+	opPathCompleteFileUploadFormat = "./v1/file_uploads/%s/complete"
+	opPathSendFileUploadFormat     = "./v1/file_uploads/%s/send"
+	opPathDeletePageFormat         = "./v1/pages/%s"
+	opPathGetPageFormat            = "./v1/pages/%s"
+	opPathUpdatePageFormat         = "./v1/pages/%s"
+	opPathGetUserFormat            = "./v1/users/%s"
 )
 
 var (
-	opPathCreateDatabase = client.MustParseURL("./v1/databases/")
-	opPathCreatePage     = client.MustParseURL("./v1/pages/")
-	opPathSearch         = client.MustParseURL("./v1/search")
-	opPathListUsers      = client.MustParseURL("./v1/users")
-	opPathGetMe          = client.MustParseURL("./v1/users/me")
+	opPathCreateDatabase   = client.MustParseURL("./v1/databases/")
+	opPathCreateFileUpload = client.MustParseURL("./v1/file_uploads")
+	opPathCreatePage       = client.MustParseURL("./v1/pages/")
+	opPathSearch           = client.MustParseURL("./v1/search")
+	opPathListUsers        = client.MustParseURL("./v1/users")
+	opPathGetMe            = client.MustParseURL("./v1/users/me")
 )
 
 // ClientInterface interface specification for the client.
@@ -78,6 +82,20 @@ type ClientInterface interface {
 
 	QueryDatabase(ctx context.Context, id Id, body QueryDatabaseJSONRequestBody, reqEditors ...client.RequestEditorFn) (*QueryDatabaseResponse, error)
 
+	// CreateFileUpload request with any body
+	CreateFileUploadWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...client.RequestEditorFn) (*CreateFileUploadResponse, error)
+
+	CreateFileUpload(ctx context.Context, body CreateFileUploadJSONRequestBody, reqEditors ...client.RequestEditorFn) (*CreateFileUploadResponse, error)
+
+	// GetFileUpload request
+	GetFileUpload(ctx context.Context, id Id, reqEditors ...client.RequestEditorFn) (*GetFileUploadResponse, error)
+
+	// CompleteFileUpload request
+	CompleteFileUpload(ctx context.Context, id Id, reqEditors ...client.RequestEditorFn) (*CompleteFileUploadResponse, error)
+
+	// SendFileUpload request with any body
+	SendFileUploadWithBody(ctx context.Context, id Id, contentType string, body io.Reader, reqEditors ...client.RequestEditorFn) (*SendFileUploadResponse, error)
+
 	// CreatePage request with any body
 	CreatePageWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...client.RequestEditorFn) (*CreatePageResponse, error)
 
@@ -1337,6 +1355,489 @@ func parseQueryDatabaseResponse(rsp *http.Response) (*QueryDatabaseResponse, err
 	return response, nil
 }
 
+// CreateFileUpload: POST /v1/file_uploads
+
+type CreateFileUploadResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *FileUpload
+	JSON400      *Error
+	JSON404      *Error
+	JSON429      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r CreateFileUploadResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CreateFileUploadResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// newCreateFileUploadRequestWithBody generates requests for CreateFileUpload with any type of body
+func newCreateFileUploadRequestWithBody(baseURL *url.URL, contentType string, body io.Reader) (*http.Request, error) {
+	queryURL := baseURL.ResolveReference(opPathCreateFileUpload)
+
+	req, err := http.NewRequest(http.MethodPost, queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add(client.ContentType, contentType)
+
+	return req, nil
+}
+
+// CreateFileUploadWithBody returns a parsed response.
+// POST /v1/file_uploads
+func (c *Client) CreateFileUploadWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...client.RequestEditorFn) (*CreateFileUploadResponse, error) {
+	rsp, err := c.doCreateFileUploadWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCreateFileUploadResponse(rsp)
+}
+
+func (c *Client) doCreateFileUploadWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...client.RequestEditorFn) (*http.Response, error) {
+	req, err := newCreateFileUploadRequestWithBody(c.BaseURL, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+
+	return c.Client.Do(req)
+}
+
+func (c *Client) CreateFileUpload(ctx context.Context, body CreateFileUploadJSONRequestBody, reqEditors ...client.RequestEditorFn) (*CreateFileUploadResponse, error) {
+	rsp, err := c.doCreateFileUpload(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCreateFileUploadResponse(rsp)
+}
+
+// newCreateFileUploadRequest calls the generic CreateFileUpload builder with application/json body.
+func newCreateFileUploadRequest(baseURL *url.URL, body CreateFileUploadJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return newCreateFileUploadRequestWithBody(baseURL, client.MIMEApplicationJSON, bodyReader)
+}
+
+func (c *Client) doCreateFileUpload(ctx context.Context, body CreateFileUploadJSONRequestBody, reqEditors ...client.RequestEditorFn) (*http.Response, error) {
+	req, err := newCreateFileUploadRequest(c.BaseURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+
+	return c.Client.Do(req)
+}
+
+// parseCreateFileUploadResponse parses an HTTP response from a CreateFileUpload call.
+func parseCreateFileUploadResponse(rsp *http.Response) (*CreateFileUploadResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	response := &CreateFileUploadResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest FileUpload
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 429:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON429 = &dest
+
+	}
+
+	return response, nil
+}
+
+// GetFileUpload: GET /v1/file_uploads/{id}
+
+type GetFileUploadResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *FileUpload
+	JSON400      *Error
+	JSON404      *Error
+	JSON429      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetFileUploadResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetFileUploadResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// newGetFileUploadRequest generates requests for GetFileUpload
+func newGetFileUploadRequest(baseURL *url.URL, id Id) (*http.Request, error) {
+	pathParam0, err := client.GetPathParam("id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	opPath := fmt.Sprintf(opPathGetFileUploadFormat, pathParam0)
+
+	queryURL, err := baseURL.Parse(opPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// GetFileUpload returns a parsed response.
+// GET /v1/file_uploads/{id}
+func (c *Client) GetFileUpload(ctx context.Context, id Id, reqEditors ...client.RequestEditorFn) (*GetFileUploadResponse, error) {
+	req, err := newGetFileUploadRequest(c.BaseURL, id)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+
+	rsp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	response := &GetFileUploadResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest FileUpload
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 429:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON429 = &dest
+
+	}
+
+	return response, nil
+}
+
+// CompleteFileUpload: POST /v1/file_uploads/{id}/complete
+
+type CompleteFileUploadResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *FileUpload
+	JSON400      *Error
+	JSON404      *Error
+	JSON429      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r CompleteFileUploadResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CompleteFileUploadResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// newCompleteFileUploadRequest generates requests for CompleteFileUpload
+func newCompleteFileUploadRequest(baseURL *url.URL, id Id) (*http.Request, error) {
+	pathParam0, err := client.GetPathParam("id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	opPath := fmt.Sprintf(opPathCompleteFileUploadFormat, pathParam0)
+
+	queryURL, err := baseURL.Parse(opPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// CompleteFileUpload returns a parsed response.
+// POST /v1/file_uploads/{id}/complete
+func (c *Client) CompleteFileUpload(ctx context.Context, id Id, reqEditors ...client.RequestEditorFn) (*CompleteFileUploadResponse, error) {
+	req, err := newCompleteFileUploadRequest(c.BaseURL, id)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+
+	rsp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	response := &CompleteFileUploadResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest FileUpload
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 429:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON429 = &dest
+
+	}
+
+	return response, nil
+}
+
+// SendFileUpload: POST /v1/file_uploads/{id}/send
+
+type SendFileUploadResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *FileUpload
+	JSON400      *Error
+	JSON404      *Error
+	JSON429      *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r SendFileUploadResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SendFileUploadResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// newSendFileUploadRequestWithBody generates requests for SendFileUpload with any type of body
+func newSendFileUploadRequestWithBody(baseURL *url.URL, id Id, contentType string, body io.Reader) (*http.Request, error) {
+	pathParam0, err := client.GetPathParam("id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	opPath := fmt.Sprintf(opPathSendFileUploadFormat, pathParam0)
+
+	queryURL, err := baseURL.Parse(opPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add(client.ContentType, contentType)
+
+	return req, nil
+}
+
+// SendFileUploadWithBody returns a parsed response.
+// POST /v1/file_uploads/{id}/send
+func (c *Client) SendFileUploadWithBody(ctx context.Context, id Id, contentType string, body io.Reader, reqEditors ...client.RequestEditorFn) (*SendFileUploadResponse, error) {
+	req, err := newSendFileUploadRequestWithBody(c.BaseURL, id, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+
+	rsp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	response := &SendFileUploadResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest FileUpload
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 429:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON429 = &dest
+
+	}
+
+	return response, nil
+}
+
 // CreatePage: POST /v1/pages/
 
 type CreatePageResponse struct {
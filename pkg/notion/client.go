@@ -1,7 +1,9 @@
 package notion
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,8 +14,13 @@ import (
 )
 
 const (
-	versionHeader = "Notion-Version"
-	version       = "2022-06-28"
+	// VersionHeader is the header that sets the version of the API.
+	VersionHeader = "Notion-Version"
+	// Version is the version of the API this package is written for.
+	Version = "2022-06-28"
+
+	// MaxBlockChildren is the maximum number of blocks that can be appended or created with a block in one request.
+	MaxBlockChildren = 100
 )
 
 var (
@@ -29,7 +36,7 @@ func NewDefaultClient(bearer string, opts ...client.Option) (*Client, error) {
 	opts = append([]client.Option{
 		client.WithBearer(bearer),
 		client.WithRequestEditorFn(func(_ context.Context, req *http.Request) error {
-			req.Header.Set(versionHeader, version)
+			req.Header.Set(VersionHeader, Version)
 			return nil
 		}),
 	}, opts...)
@@ -131,7 +138,8 @@ func (c Client) UpdateNotionPage(ctx context.Context, p Page) (*Page, error) {
 		case PropertyTypeCreatedTime,
 			PropertyTypeCreatedBy,
 			PropertyTypeLastEditedTime,
-			PropertyTypeLastEditedBy:
+			PropertyTypeLastEditedBy,
+			PropertyTypeUniqueId:
 			// we can't update these
 			delete(props, key)
 		case PropertyTypeSelect:
@@ -439,6 +447,54 @@ func (c Client) ListAllUsers(ctx context.Context) (Users, error) {
 	}
 }
 
+// GetNotionUser returns the user or an error.
+func (c Client) GetNotionUser(ctx context.Context, id Id) (*User, error) {
+	resp, err := c.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
+
+// GetNotionMe returns the bot user of the integration or an error.
+func (c Client) GetNotionMe(ctx context.Context) (*User, error) {
+	resp, err := c.GetMe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
+
 // GetAllBlocks returns all blocks of a given page or block.
 func (c Client) GetAllBlocks(ctx context.Context, id Id) (Blocks, error) {
 	var (
@@ -643,13 +699,89 @@ func (c Client) GetNotionDatabasesByTitle(
 	}
 }
 
+// GetNotionPagesEditedSince returns all pages that were last edited at or after the time,
+// the most recently edited first.
+func (c Client) GetNotionPagesEditedSince(ctx context.Context, since time.Time) (Pages, error) {
+	pages := Pages{}
+	body := SearchJSONRequestBody{
+		Filter: &SearchFilter{
+			Value:    SearchFilterValuePage,
+			Property: SearchFilterPropertyObject,
+		},
+		Sort: &SearchSort{
+			Direction: SearchSortDirectionDescending,
+			Timestamp: SearchSortTimestampLastEditedTime,
+		},
+		PageSize: &maxPageSizeInt,
+	}
+
+	for {
+		res, err := c.searchNotion(ctx, body)
+		if err != nil {
+			return nil, fmt.Errorf("searching pages edited since %s: %w", since, err)
+		}
+
+		for _, r := range res.Results {
+			if r.Page == nil {
+				continue
+			}
+
+			if r.Page.LastEditedTime.Before(since) {
+				return pages, nil
+			}
+
+			pages = append(pages, *r.Page)
+		}
+
+		if !res.HasMore || res.NextCursor == nil {
+			return pages, nil
+		}
+
+		cursor := string(*res.NextCursor)
+		body.StartCursor = &cursor
+	}
+}
+
+func (c Client) searchNotion(ctx context.Context, body SearchJSONRequestBody) (*SearchResult, error) {
+	resp, err := c.Search(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
+
+// AppendBlocksToPage appends blocks to the end of the page.
 func (c Client) AppendBlocksToPage(ctx context.Context, pageID Id, blocks ...Block) (Blocks, error) {
-	pageUUID := UUID(pageID)
+	return c.AppendNotionBlocks(ctx, pageID, nil, blocks...)
+}
+
+// AppendNotionBlocks appends blocks to the children of a page or block.
+// If after is set, the blocks are inserted after the block with that ID
+// instead of being appended at the end.
+// The blocks passed in are not changed.
+func (c Client) AppendNotionBlocks(ctx context.Context, id Id, after *UUID, blocks ...Block) (Blocks, error) {
+	parentUUID := UUID(id)
+	children := make(Blocks, len(blocks))
 
 	for i, b := range blocks {
 		b.Parent = Parent{
-			Type:   ParentTypePageId,
-			PageId: &pageUUID,
+			Type:    ParentTypeBlockId,
+			BlockId: &parentUUID,
 		}
 
 		if b.Id == "" {
@@ -660,12 +792,15 @@ func (c Client) AppendBlocksToPage(ctx context.Context, pageID Id, blocks ...Blo
 			return nil, fmt.Errorf("validating block %d to be appended: %w", i, err)
 		}
 
-		blocks[i] = b
+		children[i] = b
 	}
 
-	resp, err := c.AppendBlocks(ctx, pageID, AppendBlocksJSONRequestBody{Children: blocks})
+	resp, err := c.AppendBlocks(ctx, id, AppendBlocksJSONRequestBody{
+		After:    after,
+		Children: children,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("appending blocks to page %s: %w", pageID, err)
+		return nil, fmt.Errorf("appending blocks to %s: %w", id, err)
 	}
 
 	switch resp.StatusCode() {
@@ -684,3 +819,126 @@ func (c Client) AppendBlocksToPage(ctx context.Context, pageID Id, blocks ...Blo
 			resp.HTTPResponse.Status, string(resp.Body))
 	}
 }
+
+// immutableBlockKeys are keys of the content of blocks that cannot be sent when updating a block.
+var immutableBlockKeys = []string{"children", "table_width"}
+
+// UpdateNotionBlock updates the content of a block or returns an error.
+// Only the content belonging to the type of the block is sent, without its children
+// and fields that cannot be changed.
+func (c Client) UpdateNotionBlock(ctx context.Context, b Block) (*Block, error) {
+	if err := b.Validate(); err != nil {
+		return nil, fmt.Errorf("validating block to be updated: %w", err)
+	}
+
+	all := map[string]json.RawMessage{}
+	if err := marshalInto(b, &all); err != nil {
+		return nil, err
+	}
+
+	content := map[string]json.RawMessage{}
+	if raw := all[string(b.Type)]; len(raw) > 0 {
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range immutableBlockKeys {
+		delete(content, key)
+	}
+
+	body, err := json.Marshal(map[string]map[string]json.RawMessage{
+		string(b.Type): content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.UpdateablockWithBody(ctx, Id(b.Id), client.MIMEApplicationJSON, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
+
+// DeleteNotionBlock archives the block or returns an error.
+func (c Client) DeleteNotionBlock(ctx context.Context, id Id) (*Block, error) {
+	resp, err := c.DeleteBlock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
+
+// UpdateNotionDatabaseProperties changes the properties of a database.
+// The keys are the names or IDs of existing properties or the names of new properties.
+// Setting the name of a property renames it, a nil value removes the property.
+func (c Client) UpdateNotionDatabaseProperties(
+	ctx context.Context, id Id, props map[string]*PropertyMeta,
+) (*Database, error) {
+	body, err := json.Marshal(map[string]any{"properties": props})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.UpdateDatabaseWithBody(ctx, id, client.MIMEApplicationJSON, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
+
+// marshalInto marshals the value and unmarshals it into dst.
+func marshalInto(v, dst any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, dst)
+}
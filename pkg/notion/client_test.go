@@ -7,15 +7,18 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
 	"time"
 
+	"github.com/faetools/client"
 	"github.com/faetools/go-notion-example/fake"
 	"github.com/faetools/go-notion/pkg/docs"
 	"github.com/faetools/go-notion/pkg/notion"
 	. "github.com/faetools/go-notion/pkg/notion"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var reBlock = regexp.MustCompile("^v1/blocks/[a-z0-9-]+.json$")
@@ -183,3 +186,25 @@ func cleanTimestamps(a any) any {
 		return a
 	}
 }
+
+func TestClient_AppendNotionBlocks(t *testing.T) {
+	t.Parallel()
+
+	srv := &appendServer{}
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	cli, err := NewDefaultClient("", client.WithBaseURL(ts.URL))
+	require.NoError(t, err)
+
+	blocks := Blocks{{Paragraph: NewParagraph("first")}, {Paragraph: NewParagraph("second")}}
+
+	created, err := cli.AppendBlocksToPage(context.Background(), "page", blocks...)
+	require.NoError(t, err)
+	assert.Len(t, created, 2)
+	assert.Equal(t, []string{"page: 2"}, srv.appends)
+
+	// the blocks passed in are not changed
+	assert.Equal(t, Blocks{{Paragraph: NewParagraph("first")}, {Paragraph: NewParagraph("second")}}, blocks)
+}
@@ -0,0 +1,187 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewPropertyValue returns a property value of the given type for a plain value
+// as it is decoded from JSON or YAML, i.e. a string, number, bool, nil or a list of these.
+//
+// Strings are parsed for numbers, checkboxes and dates, which can also be a range in the form "start/end".
+// Lists can also be given as comma separated strings. Relations and people are lists of IDs
+// and files are lists of URLs. A nil value returns an empty property value, which clears the property.
+func NewPropertyValue(tp PropertyType, v any) (PropertyValue, error) {
+	if v == nil {
+		return PropertyValue{Type: tp}, nil
+	}
+
+	if tp.ReadOnly() {
+		return PropertyValue{}, fmt.Errorf("cannot set %s properties", tp)
+	}
+
+	plain, err := coerce(tp, v)
+	if err != nil {
+		return PropertyValue{}, fmt.Errorf("cannot convert %v into %s property: %w", v, tp, err)
+	}
+
+	prop, err := marshalProperty(tp, reflect.ValueOf(plain))
+	if err != nil {
+		return PropertyValue{}, err
+	}
+
+	return *prop, nil
+}
+
+// coerce converts the value into a Go value that can be marshalled into the property type.
+func coerce(tp PropertyType, v any) (any, error) {
+	if n, ok := v.(json.Number); ok {
+		v = n.String()
+	}
+
+	switch tp {
+	case PropertyTypeTitle, PropertyTypeRichText,
+		PropertyTypeSelect, PropertyTypeStatus,
+		PropertyTypeUrl, PropertyTypeEmail, PropertyTypePhoneNumber:
+		switch v.(type) {
+		case bool, int, int64, float64:
+			return fmt.Sprint(v), nil
+		}
+	case PropertyTypeNumber:
+		if s, ok := v.(string); ok {
+			return strconv.ParseFloat(strings.TrimSpace(s), 64)
+		}
+	case PropertyTypeCheckbox:
+		if s, ok := v.(string); ok {
+			return parseCheckbox(s)
+		}
+	case PropertyTypeDate:
+		if s, ok := v.(string); ok {
+			return parseDateRange(s)
+		}
+	case PropertyTypeMultiSelect, PropertyTypeRelation, PropertyTypePeople:
+		return stringList(v)
+	case PropertyTypeFiles:
+		urls, err := stringList(v)
+		if err != nil {
+			return nil, err
+		}
+
+		files := make([]File, len(urls))
+		for i, u := range urls {
+			name := u
+			files[i] = File{Type: FileTypeExternal, Name: &name, External: &ExternalFile{Url: u}}
+		}
+
+		return files, nil
+	}
+
+	return v, nil
+}
+
+func parseCheckbox(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "false", "no", "n", "0", "off":
+		return false, nil
+	case "true", "yes", "y", "1", "on", "x", "✓", "✔":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid checkbox value %q", s)
+	}
+}
+
+func parseDateRange(s string) (Date, error) {
+	return ParseDate(s, nil)
+}
+
+// localLayouts are the layouts of times without a UTC offset.
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// ParseDate parses a date, a time in RFC 3339 or a range in the form "start/end".
+// Times without a UTC offset, e.g. "2006-01-02 15:04", are in the location,
+// which is then also set as the time zone of the date. If loc is nil, they are in UTC.
+func ParseDate(s string, loc *time.Location) (Date, error) {
+	start, end, isRange := strings.Cut(strings.TrimSpace(s), "/")
+
+	d, err := parseDate(strings.TrimSpace(start), loc)
+	if err != nil {
+		return Date{}, err
+	}
+
+	if isRange {
+		e, err := parseDate(strings.TrimSpace(end), loc)
+		if err != nil {
+			return Date{}, err
+		}
+
+		d.End = &e.Start
+		d.DateOnly = d.DateOnly && e.DateOnly
+	}
+
+	return d, nil
+}
+
+func parseDate(s string, loc *time.Location) (Date, error) {
+	if t, err := parseTimeOrDate(s, nil); err == nil {
+		return Date{Start: t, DateOnly: isDateOnly(s)}, nil
+	}
+
+	for _, layout := range localLayouts {
+		if loc == nil {
+			if t, err := time.Parse(layout, s); err == nil {
+				return Date{Start: t}, nil
+			}
+
+			continue
+		}
+
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return NewDate(t), nil
+		}
+	}
+
+	return Date{}, fmt.Errorf("invalid date %q", s)
+}
+
+// stringList returns the elements of a list or of a comma separated string.
+func stringList(v any) ([]string, error) {
+	switch v := v.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return []string{}, nil
+		}
+
+		parts := strings.Split(v, ",")
+		for i, p := range parts {
+			parts[i] = strings.TrimSpace(p)
+		}
+
+		return parts, nil
+	case []string:
+		return v, nil
+	case []any:
+		list := make([]string, len(v))
+
+		for i, elem := range v {
+			s, ok := elem.(string)
+			if !ok {
+				return nil, fmt.Errorf("list element %v is not a string", elem)
+			}
+
+			list[i] = s
+		}
+
+		return list, nil
+	default:
+		return nil, fmt.Errorf("%T is not a list", v)
+	}
+}
@@ -0,0 +1,73 @@
+package notion_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPropertyValue(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewPropertyValue(PropertyTypeNumber, " 3.5")
+	require.NoError(t, err)
+	assert.Equal(t, 3.5, v.GetNumber())
+
+	v, err = NewPropertyValue(PropertyTypeRichText, 42.0)
+	require.NoError(t, err)
+	assert.Equal(t, "42", v.GetRichText().Content())
+
+	v, err = NewPropertyValue(PropertyTypeCheckbox, "Yes")
+	require.NoError(t, err)
+	assert.True(t, v.GetCheckbox())
+
+	v, err = NewPropertyValue(PropertyTypeDate, "2024-01-31/2024-02-02")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-31 - 2024-02-02", v.GetDate().String())
+
+	v, err = NewPropertyValue(PropertyTypeMultiSelect, []any{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, SelectValues{{Name: "a"}, {Name: "b"}}, v.GetMultiSelect())
+
+	v, err = NewPropertyValue(PropertyTypeFiles, "https://example.com/a.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a.pdf", v.GetFiles()[0].External.Url)
+
+	v, err = NewPropertyValue(PropertyTypeSelect, nil)
+	require.NoError(t, err)
+	assert.Nil(t, v.Select)
+
+	_, err = NewPropertyValue(PropertyTypeCheckbox, "maybe")
+	assert.EqualError(t, err, `cannot convert maybe into checkbox property: invalid checkbox value "maybe"`)
+
+	_, err = NewPropertyValue(PropertyTypeFormula, "1")
+	assert.EqualError(t, err, "cannot set formula properties")
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	d, err := ParseDate("2024-01-31 14:30", berlin)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", *d.TimeZone)
+	assert.Equal(t, "2024-01-31T13:30:00Z", d.Start.UTC().Format(time.RFC3339))
+
+	d, err = ParseDate("2024-01-31T14:30:00+01:00/2024-01-31T16:00:00+01:00", berlin)
+	require.NoError(t, err)
+	assert.Nil(t, d.TimeZone)
+	assert.Equal(t, "2024-01-31T14:30:00+01:00 - 2024-01-31T16:00:00+01:00", d.String())
+
+	d, err = ParseDate("2024-01-31", berlin)
+	require.NoError(t, err)
+	assert.Nil(t, d.TimeZone)
+	assert.Equal(t, "2024-01-31", d.String())
+
+	_, err = ParseDate("31.01.2024", nil)
+	assert.EqualError(t, err, `invalid date "31.01.2024"`)
+}
@@ -0,0 +1,173 @@
+package notion
+
+import "time"
+
+const oneDay = 24 * time.Hour
+
+// NewDateOnly returns a date without time information for the given day.
+func NewDateOnly(year int, month time.Month, dayOfMonth int) Date {
+	return Date{Start: time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC), DateOnly: true}
+}
+
+// NewDateRange returns a date spanning from start to end.
+// Both times are converted to loc, which is also used as the time zone of the date.
+// If loc is nil, the location of start is used.
+func NewDateRange(start, end time.Time, loc *time.Location) Date {
+	if loc != nil {
+		start = start.In(loc)
+	}
+
+	d := NewDate(start)
+
+	end = end.In(d.Start.Location())
+	d.End = &end
+
+	return d
+}
+
+// IsDateOnly reports whether the date has no time information.
+func (d Date) IsDateOnly() bool { return d.DateOnly }
+
+// HasTime reports whether the date has time information.
+func (d Date) HasTime() bool { return !d.IsDateOnly() }
+
+// IsRange reports whether the date has an end.
+func (d Date) IsRange() bool { return d.End != nil }
+
+// bounds returns the half-open interval [start, end) the date covers.
+// The end of a date-only value is inclusive in Notion, so a date-only value covers all of its last day.
+func (d Date) bounds() (start, end time.Time) {
+	start, end = d.Start, d.Start
+	if d.End != nil {
+		end = *d.End
+	}
+
+	if d.IsDateOnly() {
+		end = end.Add(oneDay)
+	}
+
+	return start, end
+}
+
+// Duration returns the length of time the date covers.
+// A date-only value covers whole days, a point in time has no duration.
+func (d Date) Duration() time.Duration {
+	start, end := d.bounds()
+	return end.Sub(start)
+}
+
+// ContainsTime reports whether t lies within the date.
+func (d Date) ContainsTime(t time.Time) bool {
+	start, end := d.bounds()
+	if start.Equal(end) {
+		return t.Equal(start)
+	}
+
+	return !t.Before(start) && t.Before(end)
+}
+
+// Contains reports whether other lies completely within the date.
+func (d Date) Contains(other Date) bool {
+	oStart, oEnd := other.bounds()
+	if oStart.Equal(oEnd) {
+		return d.ContainsTime(oStart)
+	}
+
+	start, end := d.bounds()
+
+	return !oStart.Before(start) && !oEnd.After(end)
+}
+
+// Overlaps reports whether the date and other have any point in time in common.
+func (d Date) Overlaps(other Date) bool {
+	start, end := d.bounds()
+	if start.Equal(end) {
+		return other.ContainsTime(start)
+	}
+
+	oStart, oEnd := other.bounds()
+	if oStart.Equal(oEnd) {
+		return d.ContainsTime(oStart)
+	}
+
+	return start.Before(oEnd) && oStart.Before(end)
+}
+
+// Shift returns the date moved by the given duration, keeping its time zone.
+// Shifting a date-only value by anything other than whole days adds time information.
+func (d Date) Shift(dur time.Duration) Date {
+	if dur%oneDay != 0 {
+		d.DateOnly = false
+	}
+
+	return d.mapTimes(func(t time.Time) time.Time { return t.Add(dur) })
+}
+
+// AddDate returns the date moved by the given number of years, months and days, keeping its time zone.
+// Unlike Shift, the wall clock time stays the same across daylight saving time changes,
+// which makes it suitable for recurring dates.
+func (d Date) AddDate(years, months, days int) Date {
+	return d.mapTimes(func(t time.Time) time.Time { return t.AddDate(years, months, days) })
+}
+
+// In returns the date converted to the given time zone.
+// Date-only values have no time zone and are returned unchanged.
+func (d Date) In(loc *time.Location) Date {
+	if d.IsDateOnly() {
+		return d
+	}
+
+	// same as NewDate, the API does not accept "Local" as a time zone
+	tz := loc.String()
+	if tz == "" || loc == time.Local {
+		loc, tz = time.UTC, "UTC"
+	}
+
+	d = d.mapTimes(func(t time.Time) time.Time { return t.In(loc) })
+	d.TimeZone = &tz
+
+	return d
+}
+
+// Normalize returns the date in a canonical form so that equal dates compare equal:
+// start and end are in order, an end equal to the start is dropped,
+// date-only values are at midnight UTC without a time zone and
+// other values are in their time zone, or UTC if they have none.
+func (d Date) Normalize() Date {
+	if d.End != nil && d.End.Before(d.Start) {
+		end := d.Start
+		d.Start, d.End = *d.End, &end
+	}
+
+	if d.IsDateOnly() {
+		d.TimeZone = nil
+		d = d.mapTimes(func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		})
+	} else {
+		loc, err := loadLocation(d.TimeZone)
+		if err != nil || loc == nil {
+			loc = time.UTC
+		}
+
+		d = d.mapTimes(func(t time.Time) time.Time { return t.In(loc).Round(0) })
+	}
+
+	if d.End != nil && d.End.Equal(d.Start) {
+		d.End = nil
+	}
+
+	return d
+}
+
+// mapTimes returns a copy of the date with f applied to start and end.
+func (d Date) mapTimes(f func(time.Time) time.Time) Date {
+	d.Start = f(d.Start)
+
+	if d.End != nil {
+		end := f(*d.End)
+		d.End = &end
+	}
+
+	return d
+}
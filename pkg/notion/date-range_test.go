@@ -0,0 +1,172 @@
+package notion_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDateOnly(t *testing.T) {
+	t.Parallel()
+
+	d := notion.NewDateOnly(2022, time.July, 10)
+
+	assert.True(t, d.IsDateOnly())
+	assert.False(t, d.HasTime())
+	assert.False(t, d.IsRange())
+	assert.Nil(t, d.TimeZone)
+	assert.Equal(t, 24*time.Hour, d.Duration())
+	assert.Equal(t, "2022-07-10", d.String())
+}
+
+func TestNewDateRange(t *testing.T) {
+	t.Parallel()
+
+	shanghai, err := time.LoadLocation(tzName)
+	require.NoError(t, err)
+
+	start := time.Date(2022, 7, 10, 9, 30, 0, 0, time.UTC)
+
+	d := notion.NewDateRange(start, start.Add(90*time.Minute), shanghai)
+	assert.True(t, d.HasTime())
+	assert.True(t, d.IsRange())
+	assert.Equal(t, &tzName, d.TimeZone)
+	assert.Equal(t, shanghai, d.Start.Location())
+	assert.Equal(t, shanghai, d.End.Location())
+	assert.Equal(t, 90*time.Minute, d.Duration())
+
+	d = notion.NewDateRange(start.Local(), start.Add(time.Hour), nil)
+	assert.Equal(t, "UTC", *d.TimeZone)
+	assert.Equal(t, time.UTC, d.End.Location())
+}
+
+func TestDate_Contains(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2022, 7, 10, 9, 30, 0, 0, time.UTC)
+	meeting := notion.NewDateRange(ts, ts.Add(time.Hour), nil)
+	sunday := notion.NewDateOnly(2022, 7, 10).Start
+	week := notion.NewDateOnly(2022, 7, 4)
+	week.End = &sunday
+
+	assert.Equal(t, 7*24*time.Hour, week.Duration())
+
+	assert.True(t, week.Contains(meeting))
+	assert.True(t, week.Contains(notion.NewDateOnly(2022, 7, 10)))
+	assert.False(t, week.Contains(notion.NewDateOnly(2022, 7, 11)))
+	assert.False(t, meeting.Contains(week))
+
+	assert.True(t, meeting.ContainsTime(ts))
+	assert.False(t, meeting.ContainsTime(ts.Add(time.Hour)))
+
+	point := notion.NewDate(ts)
+	assert.Zero(t, point.Duration())
+	assert.True(t, point.ContainsTime(ts))
+	assert.True(t, meeting.Contains(point))
+	assert.False(t, point.Contains(meeting))
+}
+
+func TestDate_Overlaps(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2022, 7, 10, 9, 30, 0, 0, time.UTC)
+	a := notion.NewDateRange(ts, ts.Add(time.Hour), nil)
+
+	for _, tt := range []struct {
+		name     string
+		other    notion.Date
+		overlaps bool
+	}{
+		{"same", a, true},
+		{"later", a.Shift(30 * time.Minute), true},
+		{"adjacent", a.Shift(time.Hour), false},
+		{"point inside", notion.NewDate(ts.Add(time.Minute)), true},
+		{"point at end", notion.NewDate(ts.Add(time.Hour)), false},
+		{"same day", notion.NewDateOnly(2022, 7, 10), true},
+		{"next day", notion.NewDateOnly(2022, 7, 11), false},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.overlaps, a.Overlaps(tt.other))
+			assert.Equal(t, tt.overlaps, tt.other.Overlaps(a))
+		})
+	}
+}
+
+func TestDate_Shift(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// the day before daylight saving time starts
+	ts := time.Date(2022, 3, 26, 9, 0, 0, 0, berlin)
+	d := notion.NewDateRange(ts, ts.Add(time.Hour), nil)
+
+	shifted := d.Shift(24 * time.Hour)
+	assert.Equal(t, d.TimeZone, shifted.TimeZone)
+	assert.Equal(t, 10, shifted.Start.Hour())
+	assert.Equal(t, time.Hour, shifted.Duration())
+
+	next := d.AddDate(0, 0, 1)
+	assert.Equal(t, d.TimeZone, next.TimeZone)
+	assert.Equal(t, 9, next.Start.Hour())
+	assert.Equal(t, 27, next.Start.Day())
+	assert.Equal(t, 9, d.Start.Hour(), "original should be untouched")
+
+	assert.Equal(t, notion.NewDateOnly(2022, 4, 26), notion.NewDateOnly(2022, 3, 26).AddDate(0, 1, 0))
+}
+
+func TestDate_In(t *testing.T) {
+	t.Parallel()
+
+	shanghai, err := time.LoadLocation(tzName)
+	require.NoError(t, err)
+
+	ts := time.Date(2022, 7, 10, 9, 30, 0, 0, time.UTC)
+
+	d := notion.NewDate(ts).In(shanghai)
+	assert.Equal(t, &tzName, d.TimeZone)
+	assert.Equal(t, 17, d.Start.Hour())
+	assert.True(t, d.Start.Equal(ts))
+
+	d = d.In(time.Local)
+	assert.Equal(t, "UTC", *d.TimeZone)
+	assert.Equal(t, time.UTC, d.Start.Location())
+
+	dateOnly := notion.NewDateOnly(2022, 7, 10)
+	assert.Equal(t, dateOnly, dateOnly.In(shanghai))
+}
+
+func TestDate_Normalize(t *testing.T) {
+	t.Parallel()
+
+	shanghai, err := time.LoadLocation(tzName)
+	require.NoError(t, err)
+
+	ts := time.Date(2022, 7, 10, 9, 30, 0, 0, time.UTC)
+
+	a := notion.NewDateRange(ts.Add(time.Hour), ts, shanghai)
+	end := ts.Add(time.Hour)
+	b := notion.Date{Start: ts, End: &end, TimeZone: &tzName}
+
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, a.Normalize(), b.Normalize())
+	assert.Equal(t, shanghai, a.Normalize().Start.Location())
+	assert.True(t, a.Normalize().Start.Equal(ts))
+
+	c := notion.Date{Start: ts.In(shanghai)}
+	assert.Equal(t, time.UTC, c.Normalize().Start.Location())
+
+	single := notion.Date{Start: ts, End: &ts}
+	assert.Nil(t, single.Normalize().End)
+
+	dateOnly := notion.NewDateOnly(2022, 7, 10)
+	dateOnly.TimeZone = &tzName
+	assert.Equal(t, notion.NewDateOnly(2022, 7, 10), dateOnly.Normalize())
+}
@@ -60,6 +60,8 @@ func (d *Date) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	d.DateOnly = isDateOnly(tmp.Start)
+
 	d.Start, err = parseTimeOrDate(tmp.Start, loc)
 	if err != nil {
 		return err
@@ -93,20 +95,23 @@ func (d Date) MarshalJSON() ([]byte, error) {
 	}
 
 	tmp := tmpDate{
-		Start:    formatTime(d.Start, loc),
+		Start:    formatTime(d.Start, loc, d.DateOnly),
 		TimeZone: d.TimeZone,
 	}
 
 	if d.End != nil {
-		endStr := formatTime(*d.End, loc)
+		endStr := formatTime(*d.End, loc, d.DateOnly)
 		tmp.End = &endStr
 	}
 
 	return json.Marshal(tmp)
 }
 
+// isDateOnly reports whether the timestamp is a date without time information.
+func isDateOnly(ts string) bool { return len(ts) == lenLayoutDate }
+
 func parseTimeOrDate(ts string, loc *time.Location) (time.Time, error) {
-	if lenLayoutDate == len(ts) {
+	if isDateOnly(ts) {
 		return time.Parse(layoutDate, ts)
 	}
 
@@ -133,16 +138,16 @@ func parseTimeOrDate(ts string, loc *time.Location) (time.Time, error) {
 
 func (d Date) String() string {
 	if d.End == nil {
-		return formatTime(d.Start, nil)
+		return formatTime(d.Start, nil, d.DateOnly)
 	}
 
 	return fmt.Sprintf("%s - %s",
-		formatTime(d.Start, nil),
-		formatTime(*d.End, nil))
+		formatTime(d.Start, nil, d.DateOnly),
+		formatTime(*d.End, nil, d.DateOnly))
 }
 
-func formatTime(t time.Time, loc *time.Location) string {
-	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+func formatTime(t time.Time, loc *time.Location, dateOnly bool) string {
+	if dateOnly {
 		return t.Format(layoutDate)
 	}
 
@@ -190,3 +190,45 @@ func TestDate_NotLocal(t *testing.T) {
 	tz := "UTC"
 	assert.Equal(t, &tz, d.TimeZone)
 }
+
+func TestDate_DateOnly(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name     string
+		rawJSON  string
+		dateOnly bool
+		str      string
+	}{
+		{
+			"date", `{"end":"2024-02-02","start":"2024-01-31","time_zone":null}`,
+			true, "2024-01-31 - 2024-02-02",
+		},
+		{
+			"time at midnight", `{"end":null,"start":"2024-01-31T00:00:00+08:00","time_zone":"Asia/Shanghai"}`,
+			false, "2024-01-31T00:00:00+08:00",
+		},
+		{
+			"time at midnight in UTC", `{"end":null,"start":"2024-01-31T00:00:00Z","time_zone":null}`,
+			false, "2024-01-31T00:00:00Z",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := notion.Date{}
+			require.NoError(t, json.Unmarshal([]byte(tt.rawJSON), &d))
+			assert.Equal(t, tt.dateOnly, d.DateOnly)
+			assert.Equal(t, tt.str, d.String())
+
+			b, err := json.Marshal(d)
+			require.NoError(t, err)
+			assert.Equal(t, tt.rawJSON, string(b))
+		})
+	}
+
+	d := notion.NewDate(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC))
+	assert.False(t, d.DateOnly)
+	assert.Equal(t, "2024-01-31T00:00:00Z", d.String())
+}
@@ -0,0 +1,319 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path"
+	"strconv"
+)
+
+const (
+	// maxSinglePartSize is the maximum size of a file that is uploaded in a single part.
+	maxSinglePartSize = 20 << 20
+
+	// partSize is the size of the parts of files that are uploaded in multiple parts.
+	partSize = 10 << 20
+)
+
+// Reference returns a reference to the file upload.
+func (u FileUpload) Reference() *FileUploadReference {
+	return &FileUploadReference{Id: u.Id}
+}
+
+// File returns the uploaded file, e.g. for page covers or files properties.
+func (u FileUpload) File() File {
+	return File{Type: FileTypeFileUpload, FileUpload: u.Reference(), Name: u.Filename}
+}
+
+// FileWithCaption returns the uploaded file for image, video, audio, file or PDF blocks.
+func (u FileUpload) FileWithCaption() FileWithCaption {
+	return FileWithCaption{Type: FileWithCaptionTypeFileUpload, FileUpload: u.Reference()}
+}
+
+// Icon returns the uploaded file as an icon.
+func (u FileUpload) Icon() Icon {
+	return Icon{Type: IconTypeFileUpload, FileUpload: u.Reference()}
+}
+
+// UploadFile uploads the content of the reader as a file with the given name.
+// The returned file upload can be attached to blocks and pages until it expires.
+// Files larger than 20 MB are uploaded in multiple parts.
+func (c Client) UploadFile(ctx context.Context, name string, r io.Reader) (*FileUpload, error) {
+	head := &bytes.Buffer{}
+	if _, err := io.CopyN(head, r, maxSinglePartSize+1); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	contentType := detectContentType(name, head.Bytes())
+
+	if head.Len() <= maxSinglePartSize {
+		return c.uploadSinglePart(ctx, name, contentType, head.Bytes())
+	}
+
+	rest, size, cleanup, err := remaining(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	defer cleanup()
+
+	return c.uploadParts(ctx, name, contentType, io.MultiReader(head, rest), int64(head.Len())+size)
+}
+
+// detectContentType returns the MIME type of the file, based on its name or its content.
+func detectContentType(name string, content []byte) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		// drop parameters like the charset
+		if mediaType, _, err := mime.ParseMediaType(t); err == nil {
+			return mediaType
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+
+	return mediaType
+}
+
+// remaining returns the rest of the reader and its size.
+// If the reader cannot seek, the rest is buffered in a temporary file that is removed by cleanup.
+func remaining(r io.Reader) (io.Reader, int64, func(), error) {
+	if s, ok := r.(io.Seeker); ok {
+		cur, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+
+		end, err := s.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+
+		if _, err := s.Seek(cur, io.SeekStart); err != nil {
+			return nil, 0, nil, err
+		}
+
+		return r, end - cur, func() {}, nil
+	}
+
+	f, err := os.CreateTemp("", "notion-upload-*")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	cleanup := func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+
+	size, err := io.Copy(f, r)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, err
+	}
+
+	return f, size, cleanup, nil
+}
+
+func (c Client) uploadSinglePart(ctx context.Context, name, contentType string, content []byte) (*FileUpload, error) {
+	mode := FileUploadRequestModeSinglePart
+
+	u, err := c.CreateNotionFileUpload(ctx, FileUploadRequest{
+		Filename:    &name,
+		ContentType: &contentType,
+		Mode:        &mode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating file upload for %s: %w", name, err)
+	}
+
+	u, err = c.SendNotionFileUpload(ctx, Id(u.Id), name, contentType, bytes.NewReader(content), 0)
+	if err != nil {
+		return nil, fmt.Errorf("sending %s: %w", name, err)
+	}
+
+	return uploaded(u)
+}
+
+func (c Client) uploadParts(
+	ctx context.Context, name, contentType string, r io.Reader, size int64,
+) (*FileUpload, error) {
+	mode := FileUploadRequestModeMultiPart
+	parts := int((size + partSize - 1) / partSize)
+
+	u, err := c.CreateNotionFileUpload(ctx, FileUploadRequest{
+		Filename:      &name,
+		ContentType:   &contentType,
+		Mode:          &mode,
+		NumberOfParts: &parts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating file upload for %s: %w", name, err)
+	}
+
+	id := Id(u.Id)
+	buf := make([]byte, partSize)
+
+	for part := 1; part <= parts; part++ {
+		n, err := io.ReadFull(r, buf)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("reading part %d of %s: %w", part, name, err)
+		}
+
+		if _, err := c.SendNotionFileUpload(ctx, id, name, contentType, bytes.NewReader(buf[:n]), part); err != nil {
+			return nil, fmt.Errorf("sending part %d of %d of %s: %w", part, parts, name, err)
+		}
+	}
+
+	u, err = c.CompleteNotionFileUpload(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("completing upload of %s: %w", name, err)
+	}
+
+	return uploaded(u)
+}
+
+// uploaded returns the file upload or an error if it is not uploaded.
+func uploaded(u *FileUpload) (*FileUpload, error) {
+	if u.Status != FileUploadStatusUploaded {
+		return nil, fmt.Errorf("file upload %s is %s", u.Id, u.Status)
+	}
+
+	return u, nil
+}
+
+// CreateNotionFileUpload creates a file upload or returns an error.
+func (c Client) CreateNotionFileUpload(ctx context.Context, req FileUploadRequest) (*FileUpload, error) {
+	resp, err := c.CreateFileUpload(ctx, CreateFileUploadJSONRequestBody(req))
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
+
+// SendNotionFileUpload sends the content of a file upload or returns an error.
+// For uploads in multiple parts, the part number starts at 1. Otherwise, it is 0.
+func (c Client) SendNotionFileUpload(
+	ctx context.Context, id Id, name, contentType string, r io.Reader, partNumber int,
+) (*FileUpload, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data",
+		map[string]string{"name": "file", "filename": name}))
+	h.Set("Content-Type", contentType)
+
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, err
+	}
+
+	if partNumber > 0 {
+		if err := w.WriteField("part_number", strconv.Itoa(partNumber)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.SendFileUploadWithBody(ctx, id, w.FormDataContentType(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
+
+// CompleteNotionFileUpload completes a file upload in multiple parts or returns an error.
+func (c Client) CompleteNotionFileUpload(ctx context.Context, id Id) (*FileUpload, error) {
+	resp, err := c.CompleteFileUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
+
+// GetNotionFileUpload returns the file upload or an error.
+func (c Client) GetNotionFileUpload(ctx context.Context, id Id) (*FileUpload, error) {
+	resp, err := c.GetFileUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK: // ok
+		return resp.JSON200, nil
+	case http.StatusBadRequest:
+		return nil, resp.JSON400
+	case http.StatusNotFound:
+		return nil, resp.JSON404
+	case http.StatusTooManyRequests:
+		return nil, resp.JSON429
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return nil, fmt.Errorf("%w (%s)", ErrGatewayIssue, resp.HTTPResponse.Status)
+	default:
+		return nil, fmt.Errorf("unknown %s response: %v",
+			resp.HTTPResponse.Status, string(resp.Body))
+	}
+}
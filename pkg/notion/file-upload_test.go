@@ -0,0 +1,167 @@
+package notion_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/faetools/client"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadServer fakes the file upload endpoints.
+type uploadServer struct {
+	mu      sync.Mutex
+	uploads map[string]*notion.FileUpload
+	content map[string][]byte
+	types   map[string][]string
+	numbers map[string][]string
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/file_uploads"), "/")
+	if len(parts) > 1 && s.uploads[parts[1]] == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"object":"error","status":404,"code":"object_not_found","message":"not found"}`)
+
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/file_uploads":
+		req := notion.FileUploadRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id := "upload-" + strconv.Itoa(len(s.uploads)+1)
+		u := &notion.FileUpload{
+			Id: notion.UUID(id), Object: "file_upload", Status: notion.FileUploadStatusPending,
+			Filename: req.Filename, ContentType: req.ContentType,
+		}
+
+		if req.NumberOfParts != nil {
+			u.NumberOfParts = &notion.FileUploadParts{Total: *req.NumberOfParts}
+		}
+
+		s.uploads[id] = u
+		s.respond(w, u)
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "send":
+		u := s.uploads[parts[1]]
+
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		b, _ := io.ReadAll(f)
+		s.content[parts[1]] = append(s.content[parts[1]], b...)
+		s.types[parts[1]] = append(s.types[parts[1]], h.Header.Get("Content-Type"))
+
+		if u.NumberOfParts == nil {
+			u.Status = notion.FileUploadStatusUploaded
+		} else {
+			u.NumberOfParts.SentCount++
+			s.numbers[parts[1]] = append(s.numbers[parts[1]], r.FormValue("part_number"))
+		}
+
+		s.respond(w, u)
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "complete":
+		u := s.uploads[parts[1]]
+		if u.NumberOfParts.SentCount == u.NumberOfParts.Total {
+			u.Status = notion.FileUploadStatusUploaded
+		}
+
+		s.respond(w, u)
+	case r.Method == http.MethodGet && len(parts) == 2:
+		s.respond(w, s.uploads[parts[1]])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *uploadServer) respond(w http.ResponseWriter, u *notion.FileUpload) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(u)
+}
+
+func TestClient_UploadFile(t *testing.T) {
+	t.Parallel()
+
+	srv := &uploadServer{
+		uploads: map[string]*notion.FileUpload{},
+		content: map[string][]byte{},
+		types:   map[string][]string{},
+		numbers: map[string][]string{},
+	}
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	cli, err := notion.NewDefaultClient("secret", client.WithBaseURL(ts.URL))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// small files are uploaded in a single part
+	u, err := cli.UploadFile(ctx, "notes.txt", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, notion.FileUploadStatusUploaded, u.Status)
+	assert.Equal(t, "hello", string(srv.content["upload-1"]))
+	assert.Equal(t, []string{"text/plain"}, srv.types["upload-1"])
+	assert.Nil(t, u.NumberOfParts)
+
+	// large files are uploaded in multiple parts, whether the reader can seek or not
+	large := bytes.Repeat([]byte("0123456789"), 2_500_000)
+
+	for i, r := range []io.Reader{bytes.NewReader(large), io.MultiReader(bytes.NewReader(large))} {
+		u, err = cli.UploadFile(ctx, "data.json", r)
+		require.NoError(t, err)
+
+		id := string(u.Id)
+		assert.Equal(t, notion.FileUploadStatusUploaded, u.Status, "upload %d", i)
+		assert.Equal(t, &notion.FileUploadParts{SentCount: 3, Total: 3}, u.NumberOfParts)
+		assert.True(t, bytes.Equal(large, srv.content[id]), "content of upload %d", i)
+		assert.Equal(t, []string{"application/json", "application/json", "application/json"}, srv.types[id])
+		assert.Equal(t, []string{"1", "2", "3"}, srv.numbers[id])
+	}
+
+	u, err = cli.GetNotionFileUpload(ctx, "upload-1")
+	require.NoError(t, err)
+	assert.Equal(t, "notes.txt", *u.Filename)
+
+	f := u.File()
+	assert.Equal(t, notion.FileTypeFileUpload, f.Type)
+	assert.Equal(t, "notes.txt", f.GetName())
+
+	b, err := json.Marshal(u.FileWithCaption())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"file_upload","file_upload":{"id":"upload-1"}}`, string(b))
+
+	assert.Equal(t, "file_upload:upload-1", u.Icon().URL())
+
+	// uploads can be used like any other file
+	img := u.FileWithCaption()
+	block := notion.Block{Type: notion.BlockTypeImage, Image: &img}
+	assert.Equal(t, "file_upload:upload-1", block.Content())
+	assert.Equal(t, "upload-1", img.GetName())
+	assert.Equal(t, []string{"file_upload:upload-1"}, notion.Files{f}.GetURLs())
+
+	_, err = cli.GetNotionFileUpload(ctx, "missing")
+	assert.EqualError(t, err, "404 Not Found: object_not_found - not found")
+}
@@ -14,6 +14,8 @@ func (f File) URL() string {
 		return f.External.Url
 	case FileTypeFile:
 		return f.File.Url
+	case FileTypeFileUpload:
+		return fileUploadURL(f.FileUpload)
 	default:
 		panic(fmt.Errorf("invalid File of type %q", f.Type))
 	}
@@ -24,6 +26,10 @@ func (f File) GetName() string {
 		return *f.Name
 	}
 
+	if f.Type == FileTypeFileUpload {
+		return string(f.FileUpload.Id)
+	}
+
 	u, err := url.Parse(f.URL())
 	if err != nil {
 		panic(fmt.Errorf("invalid File with unparsable URL: %w", err))
@@ -47,6 +53,8 @@ func (f FileWithCaption) URL() string {
 		return f.External.Url
 	case FileWithCaptionTypeFile:
 		return f.File.Url
+	case FileWithCaptionTypeFileUpload:
+		return fileUploadURL(f.FileUpload)
 	default:
 		panic(fmt.Errorf("invalid FileWithCaption of type %q", f.Type))
 	}
@@ -61,6 +69,10 @@ func (f FileWithCaption) ExpiryTime() *time.Time {
 }
 
 func (f FileWithCaption) GetName() string {
+	if f.Type == FileWithCaptionTypeFileUpload {
+		return string(f.FileUpload.Id)
+	}
+
 	u, err := url.Parse(f.URL())
 	if err != nil {
 		panic(fmt.Errorf("invalid FileWithCaption with unparsable URL: %w", err))
@@ -71,9 +83,10 @@ func (f FileWithCaption) GetName() string {
 
 func (f FileWithCaption) GetFile() File {
 	return File{
-		External: f.External,
-		File:     f.File,
-		Type:     FileType(f.Type),
+		External:   f.External,
+		File:       f.File,
+		FileUpload: f.FileUpload,
+		Type:       FileType(f.Type),
 	}
 }
 
@@ -84,6 +97,8 @@ func (ic Icon) URL() string {
 		return ic.External.Url
 	case IconTypeFile:
 		return ic.File.Url
+	case IconTypeFileUpload:
+		return fileUploadURL(ic.FileUpload)
 	case IconTypeEmoji:
 		panic(fmt.Errorf("Icon of type %q does not have a URL", ic.Type))
 	default:
@@ -98,3 +113,9 @@ func (ic Icon) ExpiryTime() *time.Time {
 
 	return nil
 }
+
+// fileUploadURL returns a URL identifying a file upload, which does not have a real URL
+// until it is attached and returned as a file hosted by Notion.
+func fileUploadURL(ref *FileUploadReference) string {
+	return "file_upload:" + string(ref.Id)
+}
@@ -0,0 +1,140 @@
+package notion
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// flatFilter is a filter with the fields of a timestamp filter on the filter itself,
+// which is where the API expects them.
+type flatFilter struct {
+	filter
+
+	Timestamp      *TimestampFilterTimestamp `json:"timestamp,omitempty"`
+	CreatedTime    *DateFilter               `json:"created_time,omitempty"`
+	LastEditedTime *DateFilter               `json:"last_edited_time,omitempty"`
+}
+
+type filter Filter
+
+// MarshalJSON fulfils json.Marshaler.
+func (f Filter) MarshalJSON() ([]byte, error) {
+	if f.Timestamp == nil {
+		return json.Marshal(filter(f))
+	}
+
+	ts := *f.Timestamp
+	f.Timestamp = nil
+
+	return json.Marshal(flatFilter{
+		filter:         filter(f),
+		Timestamp:      &ts.Timestamp,
+		CreatedTime:    ts.CreatedTime,
+		LastEditedTime: ts.LastEditedTime,
+	})
+}
+
+// UnmarshalJSON fulfils json.Unmarshaler.
+func (f *Filter) UnmarshalJSON(b []byte) error {
+	tmp := flatFilter{}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+
+	*f = Filter(tmp.filter)
+
+	if tmp.Timestamp != nil {
+		f.Timestamp = &TimestampFilter{
+			Timestamp:      *tmp.Timestamp,
+			CreatedTime:    tmp.CreatedTime,
+			LastEditedTime: tmp.LastEditedTime,
+		}
+	}
+
+	return nil
+}
+
+// LastEditedSince returns a filter for pages that were last edited at or after the time.
+func LastEditedSince(t time.Time) *Filter {
+	return &Filter{Timestamp: &TimestampFilter{
+		Timestamp:      TimestampFilterTimestampLastEditedTime,
+		LastEditedTime: &DateFilter{OnOrAfter: &t},
+	}}
+}
+
+type dateFilter DateFilter
+
+// flatDateFilter is a date filter with its times formatted according to DateOnly.
+type flatDateFilter struct {
+	*dateFilter
+
+	After     *string `json:"after,omitempty"`
+	Before    *string `json:"before,omitempty"`
+	Equals    *string `json:"equals,omitempty"`
+	OnOrAfter *string `json:"on_or_after,omitempty"`
+}
+
+// MarshalJSON fulfils json.Marshaler.
+func (f DateFilter) MarshalJSON() ([]byte, error) {
+	format := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+
+		s := formatTime(*t, nil, f.DateOnly)
+
+		return &s
+	}
+
+	return json.Marshal(flatDateFilter{
+		dateFilter: (*dateFilter)(&f),
+		After:      format(f.After),
+		Before:     format(f.Before),
+		Equals:     format(f.Equals),
+		OnOrAfter:  format(f.OnOrAfter),
+	})
+}
+
+// UnmarshalJSON fulfils json.Unmarshaler.
+// DateOnly is set if all times of the filter are dates without time information.
+func (f *DateFilter) UnmarshalJSON(b []byte) error {
+	tmp := flatDateFilter{dateFilter: &dateFilter{}}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+
+	*f = DateFilter(*tmp.dateFilter)
+
+	var hasTime, hasDate bool
+
+	for _, v := range []struct {
+		s *string
+		t **time.Time
+	}{
+		{tmp.After, &f.After},
+		{tmp.Before, &f.Before},
+		{tmp.Equals, &f.Equals},
+		{tmp.OnOrAfter, &f.OnOrAfter},
+	} {
+		if v.s == nil {
+			continue
+		}
+
+		t, err := parseTimeOrDate(*v.s, nil)
+		if err != nil {
+			return err
+		}
+
+		*v.t = &t
+
+		if isDateOnly(*v.s) {
+			hasDate = true
+		} else {
+			hasTime = true
+		}
+	}
+
+	f.DateOnly = hasDate && !hasTime
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_JSON(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	done := "Done"
+
+	f := Filter{And: &Filters{
+		*LastEditedSince(since),
+		{Property: &done, Checkbox: &CheckboxFilter{Equals: true}},
+	}}
+
+	b, err := json.Marshal(f)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"and": [
+		{"timestamp": "last_edited_time", "last_edited_time": {"on_or_after": "2024-01-31T12:00:00Z"}},
+		{"property": "Done", "checkbox": {"equals": true}}
+	]}`, string(b))
+
+	var got Filter
+	require.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, f, got)
+}
+
+func TestDateFilter_JSON(t *testing.T) {
+	t.Parallel()
+
+	midnight := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	due := "Due"
+
+	for _, tt := range []struct {
+		name     string
+		dateOnly bool
+		want     string
+	}{
+		{"date", true, `{"property": "Due", "date": {"on_or_after": "2024-01-31", "is_not_empty": true}}`},
+		{"time at midnight", false, `{"property": "Due", "date": {"on_or_after": "2024-01-31T00:00:00Z", "is_not_empty": true}}`},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			notEmpty := true
+			f := Filter{Property: &due, Date: &DateFilter{
+				OnOrAfter:  &midnight,
+				IsNotEmpty: &notEmpty,
+				DateOnly:   tt.dateOnly,
+			}}
+
+			b, err := json.Marshal(f)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(b))
+
+			var got Filter
+			require.NoError(t, json.Unmarshal(b, &got))
+			assert.Equal(t, f, got)
+		})
+	}
+}
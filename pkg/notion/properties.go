@@ -0,0 +1,734 @@
+package notion
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PropertyMarshaler is implemented by types that can turn themselves into a property value.
+type PropertyMarshaler interface {
+	MarshalProperty() (PropertyValue, error)
+}
+
+// PropertyUnmarshaler is implemented by types that can read themselves from a property value.
+type PropertyUnmarshaler interface {
+	UnmarshalProperty(PropertyValue) error
+}
+
+var (
+	errNilTarget   = errors.New("target must be a non-nil pointer to a struct")
+	errNotAStruct  = errors.New("value must be a struct or a pointer to a struct")
+	errUnsupported = errors.New("unsupported Go type")
+)
+
+var (
+	typeTime        = reflect.TypeOf(time.Time{})
+	typeDate        = reflect.TypeOf(Date{})
+	typeUUID        = reflect.TypeOf(UUID(""))
+	typeUser        = reflect.TypeOf(User{})
+	typeFile        = reflect.TypeOf(File{})
+	typeRichTexts   = reflect.TypeOf(RichTexts{})
+	typeMarshaler   = reflect.TypeOf((*PropertyMarshaler)(nil)).Elem()
+	typeUnmarshaler = reflect.TypeOf((*PropertyUnmarshaler)(nil)).Elem()
+)
+
+// PropertyField describes a struct field that is mapped to a property
+// with a struct tag of the form `notion:"Name,type,omitempty"`.
+//
+// The type is optional. If it is missing, it is inferred from the Go type when marshalling:
+// strings become rich text, numbers become numbers, bools checkboxes, times and dates dates,
+// string slices multi-selects, UUID slices relations, users people and files files.
+type PropertyField struct {
+	// Name is the name of the property.
+	Name string
+	// Type is the type of the property, if given in the tag.
+	Type PropertyType
+	// OmitEmpty defines whether the property is left out when marshalling a zero value.
+	OmitEmpty bool
+
+	// Index is the index sequence of the field for reflect.Value.FieldByIndex.
+	Index []int
+	// GoType is the type of the field.
+	GoType reflect.Type
+}
+
+// PropertyFields returns the fields of the struct type that are mapped to properties.
+// Fields without a notion tag or with the tag "-" are ignored.
+func PropertyFields(t reflect.Type) ([]PropertyField, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, errNotAStruct
+	}
+
+	fields := []PropertyField{}
+	seen := map[string]string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		tag, ok := sf.Tag.Lookup("notion")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		f := PropertyField{Index: sf.Index, GoType: sf.Type}
+
+		parts := strings.Split(tag, ",")
+		f.Name = parts[0]
+
+		if f.Name == "" {
+			f.Name = sf.Name
+		}
+
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "":
+			case "omitempty":
+				f.OmitEmpty = true
+			default:
+				f.Type = PropertyType(opt)
+			}
+		}
+
+		if other, ok := seen[f.Name]; ok {
+			return nil, fmt.Errorf("fields %s and %s both map to property %q", other, sf.Name, f.Name)
+		}
+
+		seen[f.Name] = sf.Name
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// inferPropertyType returns the property type for a Go type or an empty string if there is none.
+func inferPropertyType(t reflect.Type) PropertyType {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case typeTime, typeDate:
+		return PropertyTypeDate
+	case typeRichTexts:
+		return PropertyTypeRichText
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return PropertyTypeRichText
+	case reflect.Bool:
+		return PropertyTypeCheckbox
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return PropertyTypeNumber
+	case reflect.Slice:
+		switch t.Elem() {
+		case typeUUID:
+			return PropertyTypeRelation
+		case typeUser:
+			return PropertyTypePeople
+		case typeFile:
+			return PropertyTypeFiles
+		}
+
+		if t.Elem().Kind() == reflect.String {
+			return PropertyTypeMultiSelect
+		}
+	}
+
+	return ""
+}
+
+// UnmarshalProperties reads the property values into the struct v points to,
+// guided by the notion struct tags of its fields.
+// Properties that are missing from props leave their fields unchanged.
+func UnmarshalProperties(props PropertyValueMap, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errNilTarget
+	}
+
+	fields, err := PropertyFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		prop, ok := props[f.Name]
+		if !ok {
+			continue
+		}
+
+		if err := unmarshalProperty(prop, rv.Elem().FieldByIndex(f.Index)); err != nil {
+			return fmt.Errorf("property %q: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func unmarshalProperty(prop PropertyValue, dst reflect.Value) error {
+	if dst.CanAddr() && dst.Addr().Type().Implements(typeUnmarshaler) {
+		return dst.Addr().Interface().(PropertyUnmarshaler).UnmarshalProperty(prop)
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		if isEmptyProperty(prop) {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+
+		return unmarshalProperty(prop, dst.Elem())
+	}
+
+	switch dst.Type() {
+	case typeTime:
+		t, ok := propertyTime(prop)
+		if !ok {
+			return cannotUnmarshal(prop, dst)
+		}
+
+		dst.Set(reflect.ValueOf(t))
+
+		return nil
+	case typeDate:
+		d, ok := propertyDate(prop)
+		if !ok {
+			return cannotUnmarshal(prop, dst)
+		}
+
+		dst.Set(reflect.ValueOf(d))
+
+		return nil
+	case typeRichTexts:
+		switch prop.Type {
+		case PropertyTypeTitle:
+			dst.Set(reflect.ValueOf(prop.GetTitle()))
+		case PropertyTypeRichText:
+			dst.Set(reflect.ValueOf(prop.GetRichText()))
+		default:
+			return cannotUnmarshal(prop, dst)
+		}
+
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		s, ok := propertyString(prop)
+		if !ok {
+			return cannotUnmarshal(prop, dst)
+		}
+
+		dst.SetString(s)
+	case reflect.Bool:
+		b, ok := propertyBool(prop)
+		if !ok {
+			return cannotUnmarshal(prop, dst)
+		}
+
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := propertyNumber(prop)
+		if !ok || !isInt(n) || n < math.MinInt64 || n >= math.MaxInt64 || dst.OverflowInt(int64(n)) {
+			return cannotUnmarshal(prop, dst)
+		}
+
+		dst.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := propertyNumber(prop)
+		if !ok || !isInt(n) || n < 0 || n >= math.MaxUint64 || dst.OverflowUint(uint64(n)) {
+			return cannotUnmarshal(prop, dst)
+		}
+
+		dst.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := propertyNumber(prop)
+		if !ok {
+			return cannotUnmarshal(prop, dst)
+		}
+
+		dst.SetFloat(n)
+	case reflect.Slice:
+		return unmarshalSlice(prop, dst)
+	default:
+		return cannotUnmarshal(prop, dst)
+	}
+
+	return nil
+}
+
+// isInt reports whether the number has no fractional part.
+func isInt(n float64) bool { return n == math.Trunc(n) }
+
+func unmarshalSlice(prop PropertyValue, dst reflect.Value) error {
+	var elems reflect.Value
+
+	switch {
+	case dst.Type().Elem() == typeUser && prop.Type == PropertyTypePeople:
+		elems = reflect.ValueOf([]User(prop.GetPeople()))
+	case dst.Type().Elem() == typeFile && prop.Type == PropertyTypeFiles:
+		elems = reflect.ValueOf([]File(prop.GetFiles()))
+	case dst.Type().Elem().Kind() == reflect.String:
+		var strs []string
+
+		switch prop.Type {
+		case PropertyTypeMultiSelect:
+			strs = mapSlice(prop.GetMultiSelect(), func(s SelectValue) string { return s.Name })
+		case PropertyTypeRelation:
+			strs = prop.GetRelation().GetUUIDs().Strings()
+		case PropertyTypePeople:
+			strs = mapSlice(prop.GetPeople(), func(u User) string { return string(u.Id) })
+		default:
+			return cannotUnmarshal(prop, dst)
+		}
+
+		elems = reflect.ValueOf(strs)
+	default:
+		return cannotUnmarshal(prop, dst)
+	}
+
+	// convert to the slice type of the field, e.g. []UUID or Users
+	out := reflect.MakeSlice(dst.Type(), elems.Len(), elems.Len())
+	for i := 0; i < elems.Len(); i++ {
+		out.Index(i).Set(elems.Index(i).Convert(dst.Type().Elem()))
+	}
+
+	dst.Set(out)
+
+	return nil
+}
+
+func cannotUnmarshal(prop PropertyValue, dst reflect.Value) error {
+	return fmt.Errorf("cannot unmarshal %s property into Go value of type %s", prop.Type, dst.Type())
+}
+
+// isEmptyProperty returns true if the property has no value.
+func isEmptyProperty(prop PropertyValue) bool {
+	switch prop.Type {
+	case PropertyTypeTitle:
+		return len(prop.GetTitle()) == 0
+	case PropertyTypeRichText:
+		return len(prop.GetRichText()) == 0
+	case PropertyTypeNumber:
+		return prop.Number == nil
+	case PropertyTypeSelect:
+		return prop.Select == nil
+	case PropertyTypeStatus:
+		return prop.Status == nil
+	case PropertyTypeDate:
+		return prop.Date == nil
+	case PropertyTypeUrl:
+		return prop.Url == nil
+	case PropertyTypeEmail:
+		return prop.Email == nil
+	case PropertyTypePhoneNumber:
+		return prop.PhoneNumber == nil
+	case PropertyTypeCheckbox:
+		return prop.Checkbox == nil
+	case PropertyTypeFormula:
+		f := prop.GetFormula()
+		return f.String == nil && f.Number == nil && f.Boolean == nil && f.Date == nil
+	case PropertyTypeRollup:
+		r := prop.GetRollup()
+		return r.String == nil && r.Number == nil && r.Date == nil && r.Array == nil
+	default:
+		return false
+	}
+}
+
+func propertyString(prop PropertyValue) (string, bool) {
+	switch prop.Type {
+	case PropertyTypeTitle:
+		return prop.GetTitle().Content(), true
+	case PropertyTypeRichText:
+		return prop.GetRichText().Content(), true
+	case PropertyTypeSelect:
+		return prop.GetSelect().Name, true
+	case PropertyTypeStatus:
+		return prop.GetStatus().Name, true
+	case PropertyTypeUrl:
+		return prop.GetURL(), true
+	case PropertyTypeEmail:
+		return prop.GetEmail(), true
+	case PropertyTypePhoneNumber:
+		return prop.GetPhoneNumber(), true
+	case PropertyTypeNumber:
+		if prop.Number == nil {
+			return "", true
+		}
+
+		return strconv.FormatFloat(*prop.Number, 'f', -1, 64), true
+	case PropertyTypeDate:
+		if prop.Date == nil {
+			return "", true
+		}
+
+		return prop.Date.String(), true
+	case PropertyTypeFormula:
+		f := prop.GetFormula()
+		if f.String == nil {
+			return "", f.Type == FormulaTypeString
+		}
+
+		return *f.String, true
+	case PropertyTypeRollup:
+		r := prop.GetRollup()
+		if r.String == nil {
+			return "", false
+		}
+
+		return *r.String, true
+	default:
+		return "", false
+	}
+}
+
+func propertyBool(prop PropertyValue) (bool, bool) {
+	switch prop.Type {
+	case PropertyTypeCheckbox:
+		return prop.GetCheckbox(), true
+	case PropertyTypeFormula:
+		f := prop.GetFormula()
+		return f.Boolean != nil && *f.Boolean, f.Type == FormulaTypeBoolean
+	default:
+		return false, false
+	}
+}
+
+func propertyNumber(prop PropertyValue) (float64, bool) {
+	switch prop.Type {
+	case PropertyTypeNumber:
+		return prop.GetNumber(), true
+	case PropertyTypeFormula:
+		f := prop.GetFormula()
+		if f.Number == nil {
+			return 0, f.Type == FormulaTypeNumber
+		}
+
+		return *f.Number, true
+	case PropertyTypeRollup:
+		r := prop.GetRollup()
+		if r.Number == nil {
+			return 0, r.Type == RollupTypeNumber
+		}
+
+		return *r.Number, true
+	default:
+		return 0, false
+	}
+}
+
+func propertyDate(prop PropertyValue) (Date, bool) {
+	switch prop.Type {
+	case PropertyTypeDate:
+		return prop.GetDate(), true
+	case PropertyTypeFormula:
+		f := prop.GetFormula()
+		if f.Date == nil {
+			return Date{}, f.Type == FormulaTypeDate
+		}
+
+		return *f.Date, true
+	case PropertyTypeRollup:
+		r := prop.GetRollup()
+		if r.Date == nil {
+			return Date{}, r.Type == RollupTypeDate
+		}
+
+		return *r.Date, true
+	default:
+		return Date{}, false
+	}
+}
+
+func propertyTime(prop PropertyValue) (time.Time, bool) {
+	switch prop.Type {
+	case PropertyTypeCreatedTime:
+		return prop.GetCreatedTime(), true
+	case PropertyTypeLastEditedTime:
+		return prop.GetLastEditedTime(), true
+	}
+
+	d, ok := propertyDate(prop)
+
+	return d.Start, ok
+}
+
+// MarshalProperties returns the property values of the struct,
+// guided by the notion struct tags of its fields.
+//
+// Fields mapped to properties that can't be set via the API, like formulas or rollups, are skipped.
+func MarshalProperties(v any) (PropertyValueMap, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errNotAStruct
+		}
+
+		rv = rv.Elem()
+	}
+
+	fields, err := PropertyFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalFields(rv, fields)
+}
+
+func marshalFields(rv reflect.Value, fields []PropertyField) (PropertyValueMap, error) {
+	props := PropertyValueMap{}
+
+	for _, f := range fields {
+		fv := rv.FieldByIndex(f.Index)
+
+		if f.OmitEmpty && fv.IsZero() {
+			continue
+		}
+
+		tp := f.Type
+		if tp == "" {
+			tp = inferPropertyType(f.GoType)
+		}
+
+		prop, err := marshalProperty(tp, fv)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", f.Name, err)
+		}
+
+		if prop == nil {
+			continue
+		}
+
+		props[f.Name] = *prop
+	}
+
+	return props, nil
+}
+
+// ReadOnly reports whether the values of properties of this type are computed by Notion
+// and therefore cannot be set.
+func (tp PropertyType) ReadOnly() bool {
+	switch tp {
+	case PropertyTypeFormula, PropertyTypeRollup,
+		PropertyTypeCreatedBy, PropertyTypeCreatedTime,
+		PropertyTypeLastEditedBy, PropertyTypeLastEditedTime,
+		PropertyTypeUniqueId:
+		return true
+	default:
+		return false
+	}
+}
+
+func marshalProperty(tp PropertyType, fv reflect.Value) (*PropertyValue, error) {
+	if fv.Type().Implements(typeMarshaler) {
+		if fv.Kind() == reflect.Pointer && fv.IsNil() {
+			return &PropertyValue{Type: tp}, nil
+		}
+
+		prop, err := fv.Interface().(PropertyMarshaler).MarshalProperty()
+		if err != nil {
+			return nil, err
+		}
+
+		return &prop, nil
+	}
+
+	if fv.CanAddr() && fv.Addr().Type().Implements(typeMarshaler) {
+		return marshalProperty(tp, fv.Addr())
+	}
+
+	if tp.ReadOnly() {
+		return nil, nil
+	}
+
+	if tp == "" {
+		return nil, fmt.Errorf("%w %s, please specify the property type", errUnsupported, fv.Type())
+	}
+
+	prop := &PropertyValue{Type: tp}
+
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			// clears the property
+			return prop, nil
+		}
+
+		fv = fv.Elem()
+	}
+
+	if err := setPropertyValue(prop, fv); err != nil {
+		return nil, err
+	}
+
+	return prop, nil
+}
+
+func setPropertyValue(prop *PropertyValue, fv reflect.Value) error {
+	switch prop.Type {
+	case PropertyTypeTitle, PropertyTypeRichText:
+		var rts RichTexts
+
+		switch {
+		case fv.Type() == typeRichTexts:
+			rts = fv.Interface().(RichTexts)
+		case fv.Kind() == reflect.String:
+			rts = NewRichTexts(fv.String())
+		default:
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		if prop.Type == PropertyTypeTitle {
+			prop.Title = &rts
+		} else {
+			prop.RichText = &rts
+		}
+	case PropertyTypeNumber:
+		var n float64
+
+		switch {
+		case fv.CanInt():
+			n = float64(fv.Int())
+		case fv.CanUint():
+			n = float64(fv.Uint())
+		case fv.CanFloat():
+			n = fv.Float()
+		default:
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		prop.Number = &n
+	case PropertyTypeCheckbox:
+		if fv.Kind() != reflect.Bool {
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		b := fv.Bool()
+		prop.Checkbox = &b
+	case PropertyTypeSelect, PropertyTypeStatus:
+		if fv.Kind() != reflect.String {
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		if fv.String() == "" {
+			return nil
+		}
+
+		if prop.Type == PropertyTypeSelect {
+			prop.Select = &SelectValue{Name: fv.String()}
+		} else {
+			prop.Status = &SelectValue{Name: fv.String()}
+		}
+	case PropertyTypeUrl, PropertyTypeEmail, PropertyTypePhoneNumber:
+		if fv.Kind() != reflect.String {
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		if fv.String() == "" {
+			return nil
+		}
+
+		s := fv.String()
+
+		switch prop.Type {
+		case PropertyTypeUrl:
+			prop.Url = &s
+		case PropertyTypeEmail:
+			prop.Email = &s
+		default:
+			prop.PhoneNumber = &s
+		}
+	case PropertyTypeDate:
+		switch fv.Type() {
+		case typeTime:
+			if t := fv.Interface().(time.Time); !t.IsZero() {
+				prop.Date = &Date{Start: t}
+			}
+		case typeDate:
+			// like a zero time, a zero date is empty
+			if d := fv.Interface().(Date); !d.Start.IsZero() {
+				prop.Date = &d
+			}
+		default:
+			return cannotMarshal(prop.Type, fv)
+		}
+	case PropertyTypeMultiSelect:
+		if fv.Kind() != reflect.Slice || fv.Type().Elem().Kind() != reflect.String {
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		vals := make(SelectValues, fv.Len())
+		for i := range vals {
+			vals[i] = SelectValue{Name: fv.Index(i).String()}
+		}
+
+		prop.MultiSelect = &vals
+	case PropertyTypeRelation:
+		if fv.Kind() != reflect.Slice || fv.Type().Elem().Kind() != reflect.String {
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		refs := make(References, fv.Len())
+		for i := range refs {
+			refs[i] = Reference{Id: UUID(fv.Index(i).String())}
+		}
+
+		prop.Relation = &refs
+	case PropertyTypePeople:
+		if fv.Kind() != reflect.Slice {
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		users := make([]User, fv.Len())
+
+		switch {
+		case fv.Type().Elem() == typeUser:
+			for i := range users {
+				users[i] = fv.Index(i).Interface().(User)
+			}
+		case fv.Type().Elem().Kind() == reflect.String:
+			for i := range users {
+				users[i] = User{Object: "user", Id: UUID(fv.Index(i).String())}
+			}
+		default:
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		prop.People = &users
+	case PropertyTypeFiles:
+		if fv.Kind() != reflect.Slice || fv.Type().Elem() != typeFile {
+			return cannotMarshal(prop.Type, fv)
+		}
+
+		files := make(Files, fv.Len())
+		for i := range files {
+			files[i] = fv.Index(i).Interface().(File)
+		}
+
+		prop.Files = &files
+	default:
+		return fmt.Errorf("cannot marshal %s properties", prop.Type)
+	}
+
+	return nil
+}
+
+func cannotMarshal(tp PropertyType, fv reflect.Value) error {
+	return fmt.Errorf("cannot marshal Go value of type %s into %s property", fv.Type(), tp)
+}
@@ -0,0 +1,221 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type priority int
+
+func (p priority) MarshalProperty() (PropertyValue, error) {
+	names := map[priority]string{1: "Low", 2: "High"}
+
+	name, ok := names[p]
+	if !ok {
+		return PropertyValue{}, errors.New("invalid priority")
+	}
+
+	return PropertyValue{Type: PropertyTypeSelect, Select: &SelectValue{Name: name}}, nil
+}
+
+func (p *priority) UnmarshalProperty(v PropertyValue) error {
+	switch v.GetSelect().Name {
+	case "Low":
+		*p = 1
+	case "High":
+		*p = 2
+	default:
+		return errors.New("unknown priority")
+	}
+
+	return nil
+}
+
+type task struct {
+	Name      string    `notion:"Name,title"`
+	Status    string    `notion:"Status,status"`
+	Priority  priority  `notion:"Priority"`
+	Estimate  float64   `notion:"Estimate"`
+	Points    *int      `notion:"Points"`
+	Done      bool      `notion:"Done"`
+	Due       *Date     `notion:"Due Date,date"`
+	Started   time.Time `notion:"Started"`
+	Tags      []string  `notion:"Tags"`
+	Blocks    []UUID    `notion:"Blocked by"`
+	Assignees Users     `notion:"Assignees"`
+	Files     Files     `notion:"Attachments"`
+	Link      string    `notion:"Link,url,omitempty"`
+	Score     float64   `notion:"Score,formula"`
+
+	Internal string
+	Ignored  string `notion:"-"`
+}
+
+func TestMarshalProperties(t *testing.T) {
+	t.Parallel()
+
+	due := Date{Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	started := time.Date(2024, 2, 1, 10, 30, 0, 0, time.UTC)
+
+	in := task{
+		Name:      "Write report",
+		Status:    "In progress",
+		Priority:  2,
+		Estimate:  2.5,
+		Done:      true,
+		Due:       &due,
+		Started:   started,
+		Tags:      []string{"work", "writing"},
+		Blocks:    []UUID{"a", "b"},
+		Assignees: Users{{Id: "u1"}},
+		Files:     Files{{Type: FileTypeExternal, External: &ExternalFile{Url: "https://example.com/a.pdf"}}},
+		Score:     10,
+		Internal:  "not a property",
+	}
+
+	props, err := MarshalProperties(in)
+	require.NoError(t, err)
+
+	// read-only, empty and untagged fields are skipped
+	assert.NotContains(t, props, "Score")
+	assert.NotContains(t, props, "Link")
+	assert.NotContains(t, props, "Internal")
+	assert.NotContains(t, props, "Ignored")
+
+	assert.Equal(t, "Write report", props["Name"].GetTitle().Content())
+	assert.Equal(t, "In progress", props["Status"].GetStatus().Name)
+	assert.Equal(t, "High", props["Priority"].GetSelect().Name)
+	assert.Equal(t, 2.5, props["Estimate"].GetNumber())
+	assert.Equal(t, PropertyValue{Type: PropertyTypeNumber}, props["Points"])
+	assert.True(t, props["Done"].GetCheckbox())
+	assert.Equal(t, due, props["Due Date"].GetDate())
+	assert.Equal(t, started, props["Started"].GetDate().Start)
+	assert.Equal(t, SelectValues{{Name: "work"}, {Name: "writing"}}, props["Tags"].GetMultiSelect())
+	assert.Equal(t, UUIDs{"a", "b"}, props["Blocked by"].GetRelation().GetUUIDs())
+	assert.Equal(t, Users{{Id: "u1"}}, props["Assignees"].GetPeople())
+	assert.Equal(t, in.Files, props["Attachments"].GetFiles())
+
+	var out task
+	require.NoError(t, UnmarshalProperties(props, &out))
+
+	in.Score, in.Internal = 0, ""
+	assert.Equal(t, in, out)
+
+	_, err = MarshalProperties(task{Priority: 3})
+	assert.EqualError(t, err, `property "Priority": invalid priority`)
+}
+
+func TestUnmarshalProperties(t *testing.T) {
+	t.Parallel()
+
+	five, str := 5.0, "computed"
+
+	props := PropertyValueMap{
+		"Name":   {Type: PropertyTypeTitle, Title: NewRichTextsP("Task")},
+		"Points": {Type: PropertyTypeNumber, Number: &five},
+		"Score":  {Type: PropertyTypeFormula, Formula: &Formula{Type: FormulaTypeNumber, Number: &five}},
+		"Tags":   {Type: PropertyTypeMultiSelect, MultiSelect: &SelectValues{{Name: "a"}}},
+		"Due Date": {
+			Type: PropertyTypeDate,
+		},
+	}
+
+	points := 1
+	out := task{Status: "unchanged", Points: &points, Due: &Date{}}
+	require.NoError(t, UnmarshalProperties(props, &out))
+
+	assert.Equal(t, "Task", out.Name)
+	assert.Equal(t, "unchanged", out.Status)
+	assert.Equal(t, 5, *out.Points)
+	assert.Equal(t, 5.0, out.Score)
+	assert.Equal(t, []string{"a"}, out.Tags)
+	assert.Nil(t, out.Due)
+
+	var s struct {
+		Name  RichTexts `notion:"Name"`
+		Score string    `notion:"Score"`
+	}
+
+	props["Score"] = PropertyValue{Type: PropertyTypeFormula, Formula: &Formula{Type: FormulaTypeString, String: &str}}
+	require.NoError(t, UnmarshalProperties(props, &s))
+	assert.Equal(t, "Task", s.Name.Content())
+	assert.Equal(t, "computed", s.Score)
+
+	err := UnmarshalProperties(PropertyValueMap{"Done": props["Name"]}, &out)
+	assert.EqualError(t, err, `property "Done": cannot unmarshal title property into Go value of type bool`)
+
+	assert.Error(t, UnmarshalProperties(props, out))
+	assert.Error(t, UnmarshalProperties(props, (*task)(nil)))
+
+	var withoutType struct {
+		Data map[string]string `notion:"Data"`
+	}
+
+	_, err = MarshalProperties(withoutType)
+	assert.ErrorContains(t, err, "please specify the property type")
+}
+
+func TestUnmarshalProperties_Numbers(t *testing.T) {
+	t.Parallel()
+
+	number := func(n float64) PropertyValueMap {
+		return PropertyValueMap{"N": {Type: PropertyTypeNumber, Number: &n}}
+	}
+
+	var i struct {
+		N int8 `notion:"N"`
+	}
+
+	var u struct {
+		N uint8 `notion:"N"`
+	}
+
+	require.NoError(t, UnmarshalProperties(number(100), &i))
+	assert.EqualValues(t, 100, i.N)
+
+	require.NoError(t, UnmarshalProperties(number(200), &u))
+	assert.EqualValues(t, 200, u.N)
+
+	for _, n := range []float64{1.5, 200, -200, 1e20} {
+		assert.Error(t, UnmarshalProperties(number(n), &i), n)
+	}
+
+	for _, n := range []float64{1.5, 300, -1, 1e20} {
+		assert.Error(t, UnmarshalProperties(number(n), &u), n)
+	}
+}
+
+func TestMarshalProperties_ZeroDate(t *testing.T) {
+	t.Parallel()
+
+	props, err := MarshalProperties(struct {
+		Due Date `notion:"Due"`
+	}{})
+	require.NoError(t, err)
+
+	assert.Equal(t, PropertyValue{Type: PropertyTypeDate}, props["Due"])
+}
+
+func TestPropertyValue_UniqueID(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		`{"id":"a","type":"unique_id","unique_id":{"prefix":null,"number":3}}`:    "3",
+		`{"id":"a","type":"unique_id","unique_id":{"prefix":"TASK","number":42}}`: "TASK-42",
+	} {
+		var v PropertyValue
+		require.NoError(t, json.Unmarshal([]byte(in), &v))
+		assert.Equal(t, want, v.GetUniqueID().String())
+		assert.True(t, v.Type.ReadOnly())
+
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		assert.JSONEq(t, in, string(b))
+	}
+}
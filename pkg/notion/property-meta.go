@@ -1,5 +1,24 @@
 package notion
 
+// WithConfig returns the property with a configuration for its type,
+// which the API needs even if there is nothing to configure.
+func (v PropertyMeta) WithConfig() (PropertyMeta, error) {
+	all := map[string]any{}
+	if err := marshalInto(v, &all); err != nil {
+		return v, err
+	}
+
+	if all[string(v.Type)] != nil {
+		return v, nil
+	}
+
+	all[string(v.Type)] = map[string]any{}
+
+	var withConf PropertyMeta
+
+	return withConf, marshalInto(all, &withConf)
+}
+
 // GetFormula returns the formula configuration.
 func (v PropertyMeta) GetFormula() FormulaConfig {
 	if v.Formula == nil {
@@ -2,6 +2,7 @@ package notion
 
 import (
 	"encoding/json"
+	"strconv"
 	"time"
 )
 
@@ -80,6 +81,12 @@ type (
 		CreatedTime *time.Time   `json:"created_time,omitempty"`
 	}
 
+	propertyValueLastEditedTime struct {
+		ID             string       `json:"id"`
+		Type           PropertyType `json:"type"`
+		LastEditedTime *time.Time   `json:"last_edited_time,omitempty"`
+	}
+
 	propertyValueRichText struct {
 		ID       string       `json:"id"`
 		Type     PropertyType `json:"type"`
@@ -110,6 +117,12 @@ type (
 		Formula *Formula     `json:"formula,omitempty"`
 	}
 
+	propertyValueUniqueID struct {
+		ID       string       `json:"id"`
+		Type     PropertyType `json:"type"`
+		UniqueID *UniqueId    `json:"unique_id,omitempty"`
+	}
+
 	propertyValueFiles struct {
 		ID    string       `json:"id"`
 		Type  PropertyType `json:"type"`
@@ -192,6 +205,12 @@ func (v PropertyValue) MarshalJSON() ([]byte, error) {
 			Type:        v.Type,
 			CreatedTime: v.CreatedTime,
 		})
+	case PropertyTypeLastEditedTime:
+		return json.Marshal(propertyValueLastEditedTime{
+			ID:             v.Id,
+			Type:           v.Type,
+			LastEditedTime: v.LastEditedTime,
+		})
 	case PropertyTypeRichText:
 		return json.Marshal(propertyValueRichText{
 			ID:       v.Id,
@@ -222,6 +241,12 @@ func (v PropertyValue) MarshalJSON() ([]byte, error) {
 			Type:    v.Type,
 			Formula: v.Formula,
 		})
+	case PropertyTypeUniqueId:
+		return json.Marshal(propertyValueUniqueID{
+			ID:       v.Id,
+			Type:     v.Type,
+			UniqueID: v.UniqueId,
+		})
 	case PropertyTypeFiles:
 		// otherwise we get the error: files should be an array, instead was `null`.
 		files := v.Files
@@ -262,6 +287,15 @@ func (v PropertyValue) GetCreatedTime() time.Time {
 	return *v.CreatedTime
 }
 
+// GetLastEditedTime returns the time the object was last edited.
+func (v PropertyValue) GetLastEditedTime() time.Time {
+	if v.LastEditedTime == nil {
+		return time.Time{}
+	}
+
+	return *v.LastEditedTime
+}
+
 // GetDate returns the date value.
 func (v PropertyValue) GetDate() Date {
 	if v.Date == nil {
@@ -405,3 +439,21 @@ func (v PropertyValue) GetURL() string {
 
 	return *v.Url
 }
+
+// GetUniqueID returns the unique ID value.
+func (v PropertyValue) GetUniqueID() UniqueId {
+	if v.UniqueId == nil {
+		return UniqueId{}
+	}
+
+	return *v.UniqueId
+}
+
+// String returns the ID as shown in Notion, e.g. TASK-42.
+func (id UniqueId) String() string {
+	if id.Prefix == nil || *id.Prefix == "" {
+		return strconv.Itoa(id.Number)
+	}
+
+	return *id.Prefix + "-" + strconv.Itoa(id.Number)
+}
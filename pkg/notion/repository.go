@@ -0,0 +1,265 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+var _ RepositoryClient = (*Client)(nil)
+
+// RepositoryClient is any client that can read and write the entries of a database.
+type RepositoryClient interface {
+	GetNotionDatabase(ctx context.Context, id Id) (*Database, error)
+	GetNotionPage(ctx context.Context, id Id) (*Page, error)
+	GetDatabaseEntries(ctx context.Context, id Id, filter *Filter, sorts *Sorts) (Pages, error)
+	QueryNotionDatabase(ctx context.Context, id Id, query DatabaseQuery) (Pages, *NextCursor, error)
+	CreateNotionPage(ctx context.Context, p Page) (*Page, error)
+	UpdateNotionPage(ctx context.Context, p Page) (*Page, error)
+}
+
+// Entry is an entry of a database together with its properties mapped to T.
+type Entry[T any] struct {
+	// Page is the page of the entry as returned by the API.
+	Page Page
+	// Value contains the properties of the page.
+	Value T
+}
+
+// ID returns the ID of the page of the entry.
+func (e Entry[T]) ID() UUID { return e.Page.Id }
+
+// Repository reads and writes the entries of a database as structs of type T.
+// The properties are mapped to the fields of T via struct tags, see PropertyField.
+type Repository[T any] struct {
+	cli    RepositoryClient
+	dbID   Id
+	fields []PropertyField
+}
+
+// NewRepository returns a repository for the database with the given ID.
+// It fetches the database and returns an error if the struct tags of T don't match its schema.
+func NewRepository[T any](ctx context.Context, cli RepositoryClient, dbID Id) (*Repository[T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("repository needs a struct type, got %s", t)
+	}
+
+	fields, err := PropertyFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := cli.GetNotionDatabase(ctx, dbID)
+	if err != nil {
+		return nil, fmt.Errorf("getting database %s: %w", dbID, err)
+	}
+
+	for i, f := range fields {
+		meta, ok := db.Properties[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("database %s has no property %q", dbID, f.Name)
+		}
+
+		if err := checkFieldType(f, meta.Type); err != nil {
+			return nil, fmt.Errorf("property %q: %w", f.Name, err)
+		}
+
+		// strings could be titles, selects, URLs, etc.
+		fields[i].Type = meta.Type
+	}
+
+	return &Repository[T]{cli: cli, dbID: dbID, fields: fields}, nil
+}
+
+// checkFieldType checks that the field can hold values of the property type.
+func checkFieldType(f PropertyField, tp PropertyType) error {
+	if f.GoType.Implements(typeMarshaler) || reflect.PointerTo(f.GoType).Implements(typeUnmarshaler) {
+		// custom types know best
+		return nil
+	}
+
+	if f.Type != "" && f.Type != tp {
+		return fmt.Errorf("field has type %s but the property is of type %s", f.Type, tp)
+	}
+
+	if tp == PropertyTypeFormula || tp == PropertyTypeRollup {
+		// we can't tell the type of the result without a value
+		return nil
+	}
+
+	// try to read an empty property into the field
+	if err := unmarshalProperty(PropertyValue{Type: tp}, reflect.New(f.GoType).Elem()); err != nil {
+		return err
+	}
+
+	if tp.ReadOnly() {
+		return nil
+	}
+
+	_, err := marshalProperty(tp, reflect.New(f.GoType).Elem())
+
+	return err
+}
+
+// List returns all entries that match the filter, which may be nil.
+func (r *Repository[T]) List(ctx context.Context, filter *Filter) ([]Entry[T], error) {
+	pages, err := r.cli.GetDatabaseEntries(ctx, r.dbID, filter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry[T], len(pages))
+
+	for i, p := range pages {
+		e, err := r.entry(p)
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = *e
+	}
+
+	return entries, nil
+}
+
+// Iterate calls fn for every entry that matches the filter, fetching one batch of entries at a time.
+// It stops at the first error returned by fn.
+func (r *Repository[T]) Iterate(ctx context.Context, filter *Filter, fn func(Entry[T]) error) error {
+	query := DatabaseQuery{Filter: filter, PageSize: maxPageSizeInt}
+
+	for {
+		pages, next, err := r.cli.QueryNotionDatabase(ctx, r.dbID, query)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range pages {
+			e, err := r.entry(p)
+			if err != nil {
+				return err
+			}
+
+			if err := fn(*e); err != nil {
+				return err
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		query.StartCursor = (*UUID)(next)
+	}
+}
+
+// Get returns the entry with the given ID.
+func (r *Repository[T]) Get(ctx context.Context, id Id) (*Entry[T], error) {
+	p, err := r.cli.GetNotionPage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.entry(*p)
+}
+
+// Create adds a new entry to the database.
+func (r *Repository[T]) Create(ctx context.Context, v T) (*Entry[T], error) {
+	props, err := r.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dbID := UUID(r.dbID)
+
+	p, err := r.cli.CreateNotionPage(ctx, Page{
+		Parent:     &Parent{Type: ParentTypeDatabaseId, DatabaseId: &dbID},
+		Properties: props,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.entry(*p)
+}
+
+// Update sets the properties of the entry with the given ID to the values of v.
+// Only the properties that changed are sent.
+func (r *Repository[T]) Update(ctx context.Context, id Id, v T) (*Entry[T], error) {
+	current, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := r.marshal(current.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := r.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := PropertyValueMap{}
+
+	for name, prop := range after {
+		if !samePropertyValue(before[name], prop) {
+			changed[name] = prop
+		}
+	}
+
+	if len(changed) == 0 {
+		return current, nil
+	}
+
+	p := current.Page
+	p.Properties = changed
+
+	updated, err := r.cli.UpdateNotionPage(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.entry(*updated)
+}
+
+// Archive archives the entry with the given ID.
+func (r *Repository[T]) Archive(ctx context.Context, id Id) error {
+	p, err := r.cli.GetNotionPage(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	p.Archived = true
+	p.Properties = PropertyValueMap{}
+
+	_, err = r.cli.UpdateNotionPage(ctx, *p)
+
+	return err
+}
+
+func (r *Repository[T]) entry(p Page) (*Entry[T], error) {
+	e := &Entry[T]{Page: p}
+	if err := UnmarshalProperties(p.Properties, &e.Value); err != nil {
+		return nil, fmt.Errorf("reading entry %s: %w", p.Id, err)
+	}
+
+	return e, nil
+}
+
+func (r *Repository[T]) marshal(v T) (PropertyValueMap, error) {
+	// an addressable copy, so fields whose marshaler has a pointer receiver are marshalled by it
+	rv := reflect.New(reflect.TypeOf(v)).Elem()
+	rv.Set(reflect.ValueOf(v))
+
+	return marshalFields(rv, r.fields)
+}
+
+func samePropertyValue(a, b PropertyValue) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && string(rawA) == string(rawB)
+}
@@ -0,0 +1,169 @@
+package notion_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/faetools/go-notion-example/fake"
+	. "github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const childDatabaseID Id = "7a3c647e-4c1e-4c27-bf1d-cfb0105e55ce"
+
+type entry struct {
+	Name    string   `notion:"Name"`
+	Number  *float64 `notion:"A number"`
+	Tags    []string `notion:"tags"`
+	Select  string   `notion:"select"`
+	Status  string   `notion:"Status"`
+	Checked bool     `notion:"yes or no?"`
+	Formula float64  `notion:"formula number"`
+	Date    *Date    `notion:"some date"`
+}
+
+// writeRecorder records pages that are created or updated instead of sending them.
+type writeRecorder struct {
+	*Client
+	written []Page
+}
+
+func (r *writeRecorder) CreateNotionPage(_ context.Context, p Page) (*Page, error) {
+	r.written = append(r.written, p)
+	p.Id = "new"
+
+	return &p, nil
+}
+
+func (r *writeRecorder) UpdateNotionPage(_ context.Context, p Page) (*Page, error) {
+	r.written = append(r.written, p)
+	return &p, nil
+}
+
+func TestRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cli := &writeRecorder{Client: fake.NotionClient}
+
+	repo, err := NewRepository[entry](ctx, cli, childDatabaseID)
+	require.NoError(t, err)
+
+	entries, err := repo.List(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 9)
+
+	var names []string
+
+	require.NoError(t, repo.Iterate(ctx, nil, func(e Entry[entry]) error {
+		names = append(names, e.Value.Name)
+		return nil
+	}))
+	assert.Len(t, names, 9)
+	assert.Contains(t, names, "entry 1")
+
+	e, err := repo.Get(ctx, "20c5eed2-59ea-476e-af69-da0ae68a084d")
+	require.NoError(t, err)
+
+	assert.Equal(t, UUID("20c5eed2-59ea-476e-af69-da0ae68a084d"), e.ID())
+	assert.Equal(t, "entry 1", e.Value.Name)
+	assert.Equal(t, 31.3, *e.Value.Number)
+	assert.Equal(t, []string{"tag 1", "tag 2", "tag 3"}, e.Value.Tags)
+	assert.Equal(t, "foo", e.Value.Select)
+	assert.Equal(t, 31.3, e.Value.Formula)
+	assert.Equal(t, "2022-07-30", e.Value.Date.String())
+
+	// only changed properties are sent
+	changed := e.Value
+	changed.Tags = []string{"tag 1"}
+	changed.Checked = true
+	changed.Formula = 1 // read-only
+
+	_, err = repo.Update(ctx, Id(e.ID()), changed)
+	require.NoError(t, err)
+	require.Len(t, cli.written, 1)
+	assert.Len(t, cli.written[0].Properties, 2)
+	assert.Equal(t, SelectValues{{Name: "tag 1"}}, cli.written[0].Properties["tags"].GetMultiSelect())
+	assert.True(t, cli.written[0].Properties["yes or no?"].GetCheckbox())
+
+	// nothing to update
+	_, err = repo.Update(ctx, Id(e.ID()), e.Value)
+	require.NoError(t, err)
+	assert.Len(t, cli.written, 1)
+
+	created, err := repo.Create(ctx, entry{Name: "new entry"})
+	require.NoError(t, err)
+	assert.Equal(t, "new entry", created.Value.Name)
+	assert.Equal(t, PropertyTypeTitle, cli.written[1].Properties["Name"].Type)
+	assert.Equal(t, UUID(childDatabaseID), *cli.written[1].Parent.DatabaseId)
+
+	require.NoError(t, repo.Archive(ctx, Id(e.ID())))
+	assert.True(t, cli.written[2].Archived)
+	assert.Empty(t, cli.written[2].Properties)
+}
+
+// choice is a select option whose marshaler has a pointer receiver.
+type choice string
+
+func (c *choice) MarshalProperty() (PropertyValue, error) {
+	return PropertyValue{Type: PropertyTypeSelect, Select: &SelectValue{Name: strings.ToLower(string(*c))}}, nil
+}
+
+func (c *choice) UnmarshalProperty(v PropertyValue) error {
+	*c = choice(strings.ToUpper(v.GetSelect().Name))
+	return nil
+}
+
+func TestRepository_PointerMarshaler(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cli := &writeRecorder{Client: fake.NotionClient}
+
+	repo, err := NewRepository[struct {
+		Name   string `notion:"Name"`
+		Select choice `notion:"select"`
+	}](ctx, cli, childDatabaseID)
+	require.NoError(t, err)
+
+	e, err := repo.Get(ctx, "20c5eed2-59ea-476e-af69-da0ae68a084d")
+	require.NoError(t, err)
+	assert.Equal(t, choice("FOO"), e.Value.Select)
+
+	changed := e.Value
+	changed.Select = "BAR"
+
+	_, err = repo.Update(ctx, Id(e.ID()), changed)
+	require.NoError(t, err)
+	require.Len(t, cli.written, 1)
+	assert.Equal(t, "bar", cli.written[0].Properties["select"].GetSelect().Name)
+
+	// unchanged values are marshalled the same way
+	_, err = repo.Update(ctx, Id(e.ID()), e.Value)
+	require.NoError(t, err)
+	assert.Len(t, cli.written, 1)
+}
+
+func TestNewRepository_InvalidSchema(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, err := NewRepository[struct {
+		Missing string `notion:"Missing"`
+	}](ctx, fake.NotionClient, childDatabaseID)
+	assert.ErrorContains(t, err, `has no property "Missing"`)
+
+	_, err = NewRepository[struct {
+		Name string `notion:"Name,rich_text"`
+	}](ctx, fake.NotionClient, childDatabaseID)
+	assert.EqualError(t, err, `property "Name": field has type rich_text but the property is of type title`)
+
+	_, err = NewRepository[struct {
+		Tags bool `notion:"tags"`
+	}](ctx, fake.NotionClient, childDatabaseID)
+	assert.EqualError(t, err,
+		`property "tags": cannot unmarshal multi_select property into Go value of type bool`)
+}
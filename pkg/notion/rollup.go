@@ -2,6 +2,10 @@ package notion
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
 )
 
 type (
@@ -70,3 +74,456 @@ func (r RollupArrayItem) MarshalJSON() ([]byte, error) {
 		return json.Marshal(rollupArrayItem(r))
 	}
 }
+
+// ErrPageNotFound is returned by lookups if a page is not known.
+var ErrPageNotFound = errors.New("page not found")
+
+// PageLookup returns the page with the ID.
+type PageLookup func(id UUID) (*Page, error)
+
+// LookupPages returns a lookup of the pages.
+func LookupPages(pages Pages) PageLookup {
+	byID := make(map[UUID]*Page, len(pages))
+	for i := range pages {
+		byID[pages[i].Id] = &pages[i]
+	}
+
+	return func(id UUID) (*Page, error) {
+		p, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPageNotFound, id)
+		}
+
+		return p, nil
+	}
+}
+
+// ComputeRollup computes the rollup of the page locally, looking up all related pages.
+// Unlike the rollups returned by the API, it is not limited to 25 related pages.
+func ComputeRollup(conf RollupConfig, p Page, lookup PageLookup) (Rollup, error) {
+	rel, ok := findProperty(p.Properties, conf.RelationPropertyName, conf.RelationPropertyId)
+	if !ok {
+		return Rollup{}, fmt.Errorf("page %s has no relation property %q", p.Id, conf.RelationPropertyName)
+	}
+
+	if rel.Type != PropertyTypeRelation {
+		return Rollup{}, fmt.Errorf("property %q is of type %s, not a relation", conf.RelationPropertyName, rel.Type)
+	}
+
+	values := make([]PropertyValue, 0, len(rel.GetRelation()))
+
+	for _, ref := range rel.GetRelation() {
+		related, err := lookup(ref.Id)
+		if err != nil {
+			return Rollup{}, err
+		}
+
+		v, ok := findProperty(related.Properties, conf.RollupPropertyName, conf.RollupPropertyId)
+		if !ok {
+			return Rollup{}, fmt.Errorf("related page %s has no property %q", ref.Id, conf.RollupPropertyName)
+		}
+
+		values = append(values, v)
+	}
+
+	r, err := rollupOf(conf.Function, values)
+	if err != nil {
+		return Rollup{}, fmt.Errorf("rollup %s of %q: %w", conf.Function, conf.RollupPropertyName, err)
+	}
+
+	r.Function = string(conf.Function)
+
+	return r, nil
+}
+
+// findProperty returns the property by name or, if it was renamed, by ID.
+func findProperty(props PropertyValueMap, name, id string) (PropertyValue, bool) {
+	if v, ok := props[name]; ok {
+		return v, true
+	}
+
+	for _, v := range props {
+		if id != "" && v.Id == id {
+			return v, true
+		}
+	}
+
+	return PropertyValue{}, false
+}
+
+// rollupOf applies the function to the values of the related pages.
+func rollupOf(fn RollupConfigFunction, values []PropertyValue) (Rollup, error) {
+	switch fn {
+	case RollupConfigFunctionShowOriginal, RollupConfigFunctionShowUnique:
+		return arrayRollup(values, fn == RollupConfigFunctionShowUnique)
+	case RollupConfigFunctionCount, RollupConfigFunctionCountAll:
+		return numberRollup(float64(len(values))), nil
+	case RollupConfigFunctionCountValues:
+		return numberRollup(float64(len(elements(values)))), nil
+	case RollupConfigFunctionUnique, RollupConfigFunctionCountUniqueValues:
+		return numberRollup(float64(len(uniqueStrings(elements(values))))), nil
+	case RollupConfigFunctionEmpty, RollupConfigFunctionCountEmpty:
+		return numberRollup(float64(countEmpty(values))), nil
+	case RollupConfigFunctionNotEmpty, RollupConfigFunctionCountNotEmpty:
+		return numberRollup(float64(len(values) - countEmpty(values))), nil
+	case RollupConfigFunctionPercentEmpty:
+		return percentRollup(countEmpty(values), len(values)), nil
+	case RollupConfigFunctionPercentNotEmpty:
+		return percentRollup(len(values)-countEmpty(values), len(values)), nil
+	case RollupConfigFunctionChecked, RollupConfigFunctionUnchecked,
+		RollupConfigFunctionPercentChecked, RollupConfigFunctionPercentUnchecked:
+		return checkboxRollup(fn, values)
+	case RollupConfigFunctionSum, RollupConfigFunctionAverage, RollupConfigFunctionMedian,
+		RollupConfigFunctionMin, RollupConfigFunctionMax, RollupConfigFunctionRange:
+		return mathRollup(fn, values)
+	case RollupConfigFunctionEarliestDate, RollupConfigFunctionLatestDate, RollupConfigFunctionDateRange:
+		return dateRollup(fn, values)
+	default:
+		return Rollup{}, fmt.Errorf("unsupported rollup function")
+	}
+}
+
+func numberRollup(n float64) Rollup {
+	return Rollup{Type: RollupTypeNumber, Number: &n}
+}
+
+// percentRollup returns the share as a number between 0 and 1.
+func percentRollup(n, total int) Rollup {
+	if total == 0 {
+		return numberRollup(0)
+	}
+
+	return numberRollup(float64(n) / float64(total))
+}
+
+func countEmpty(values []PropertyValue) int {
+	n := 0
+
+	for _, v := range values {
+		if isEmptyProperty(v) {
+			n++
+		}
+	}
+
+	return n
+}
+
+func arrayRollup(values []PropertyValue, unique bool) (Rollup, error) {
+	arr := RollupArray{}
+	seen := map[string]bool{}
+
+	for _, v := range values {
+		item, err := NewRollupArrayItem(v)
+		if err != nil {
+			return Rollup{}, err
+		}
+
+		if unique {
+			b, err := json.Marshal(item)
+			if err != nil {
+				return Rollup{}, err
+			}
+
+			if seen[string(b)] {
+				continue
+			}
+
+			seen[string(b)] = true
+		}
+
+		arr = append(arr, item)
+	}
+
+	return Rollup{Type: RollupTypeArray, Array: &arr}, nil
+}
+
+// NewRollupArrayItem returns the property value as an element of a rollup array.
+func NewRollupArrayItem(v PropertyValue) (RollupArrayItem, error) {
+	item := RollupArrayItem{
+		Type:        RollupArrayItemType(v.Type),
+		Checkbox:    v.Checkbox,
+		Date:        v.Date,
+		Email:       v.Email,
+		Files:       v.Files,
+		Formula:     v.Formula,
+		MultiSelect: v.MultiSelect,
+		Number:      v.Number,
+		People:      v.People,
+		PhoneNumber: v.PhoneNumber,
+		Relation:    v.Relation,
+		RichText:    v.RichText,
+		Select:      v.Select,
+		Status:      v.Status,
+		Title:       v.Title,
+		Url:         v.Url,
+	}
+
+	switch v.Type {
+	case PropertyTypeTitle:
+		if item.Title == nil {
+			item.Title = &RichTexts{}
+		}
+	case PropertyTypeRichText:
+		if item.RichText == nil {
+			item.RichText = &RichTexts{}
+		}
+	case PropertyTypeCheckbox:
+		if item.Checkbox == nil {
+			item.Checkbox = new(bool)
+		}
+	case PropertyTypeMultiSelect:
+		if item.MultiSelect == nil {
+			item.MultiSelect = &SelectValues{}
+		}
+	case PropertyTypePeople:
+		if item.People == nil {
+			item.People = &[]User{}
+		}
+	case PropertyTypeRelation:
+		if item.Relation == nil {
+			item.Relation = &References{}
+		}
+	case PropertyTypeFiles:
+		if item.Files == nil {
+			item.Files = &Files{}
+		}
+	case PropertyTypeNumber, PropertyTypeDate, PropertyTypeSelect, PropertyTypeStatus,
+		PropertyTypeUrl, PropertyTypeEmail, PropertyTypePhoneNumber, PropertyTypeFormula:
+	default:
+		return RollupArrayItem{}, fmt.Errorf("cannot show %s properties", v.Type)
+	}
+
+	return item, nil
+}
+
+// PropertyValue returns the element of a rollup array as a property value.
+// String elements are returned as rich text.
+func (i RollupArrayItem) PropertyValue() PropertyValue {
+	if i.Type == RollupArrayItemTypeString {
+		rts := RichTexts{}
+		if i.String != nil {
+			rts = NewRichTexts(*i.String)
+		}
+
+		return PropertyValue{Type: PropertyTypeRichText, RichText: &rts}
+	}
+
+	return PropertyValue{
+		Type:        PropertyType(i.Type),
+		Checkbox:    i.Checkbox,
+		Date:        i.Date,
+		Email:       i.Email,
+		Files:       i.Files,
+		Formula:     i.Formula,
+		MultiSelect: i.MultiSelect,
+		Number:      i.Number,
+		People:      i.People,
+		PhoneNumber: i.PhoneNumber,
+		Relation:    i.Relation,
+		RichText:    i.RichText,
+		Select:      i.Select,
+		Status:      i.Status,
+		Title:       i.Title,
+		Url:         i.Url,
+	}
+}
+
+// elements returns the non-empty values, with lists being split into their elements.
+func elements(values []PropertyValue) []string {
+	var elems []string
+
+	for _, v := range values {
+		switch v.Type {
+		case PropertyTypeMultiSelect:
+			for _, o := range v.GetMultiSelect() {
+				elems = append(elems, o.Name)
+			}
+		case PropertyTypePeople:
+			for _, u := range v.GetPeople() {
+				elems = append(elems, string(u.Id))
+			}
+		case PropertyTypeRelation:
+			for _, ref := range v.GetRelation() {
+				elems = append(elems, string(ref.Id))
+			}
+		case PropertyTypeFiles:
+			for _, f := range v.GetFiles() {
+				elems = append(elems, fileURL(f))
+			}
+		default:
+			if isEmptyProperty(v) {
+				continue
+			}
+
+			s, ok := propertyString(v)
+			if !ok {
+				b, _ := json.Marshal(v)
+				s = string(b)
+			}
+
+			elems = append(elems, s)
+		}
+	}
+
+	return elems
+}
+
+func fileURL(f File) string {
+	switch {
+	case f.External != nil:
+		return f.External.Url
+	case f.File != nil:
+		return WithoutQuery(f.File.Url)
+	case f.Name != nil:
+		return *f.Name
+	default:
+		return ""
+	}
+}
+
+func uniqueStrings(elems []string) map[string]bool {
+	uniq := make(map[string]bool, len(elems))
+	for _, e := range elems {
+		uniq[e] = true
+	}
+
+	return uniq
+}
+
+func checkboxRollup(fn RollupConfigFunction, values []PropertyValue) (Rollup, error) {
+	checked := 0
+
+	for _, v := range values {
+		b, ok := propertyBool(v)
+		if !ok {
+			return Rollup{}, fmt.Errorf("cannot use %s properties", v.Type)
+		}
+
+		if b {
+			checked++
+		}
+	}
+
+	switch fn {
+	case RollupConfigFunctionChecked:
+		return numberRollup(float64(checked)), nil
+	case RollupConfigFunctionUnchecked:
+		return numberRollup(float64(len(values) - checked)), nil
+	case RollupConfigFunctionPercentChecked:
+		return percentRollup(checked, len(values)), nil
+	default:
+		return percentRollup(len(values)-checked, len(values)), nil
+	}
+}
+
+func mathRollup(fn RollupConfigFunction, values []PropertyValue) (Rollup, error) {
+	nums := make([]float64, 0, len(values))
+
+	for _, v := range values {
+		if isEmptyProperty(v) {
+			continue
+		}
+
+		n, ok := propertyNumber(v)
+		if !ok {
+			return Rollup{}, fmt.Errorf("cannot use %s properties", v.Type)
+		}
+
+		nums = append(nums, n)
+	}
+
+	if fn == RollupConfigFunctionSum {
+		sum := 0.0
+		for _, n := range nums {
+			sum += n
+		}
+
+		return numberRollup(sum), nil
+	}
+
+	if len(nums) == 0 {
+		return Rollup{Type: RollupTypeNumber}, nil
+	}
+
+	sort.Float64s(nums)
+
+	switch fn {
+	case RollupConfigFunctionAverage:
+		sum := 0.0
+		for _, n := range nums {
+			sum += n
+		}
+
+		return numberRollup(sum / float64(len(nums))), nil
+	case RollupConfigFunctionMedian:
+		mid := len(nums) / 2
+		if len(nums)%2 == 0 {
+			return numberRollup((nums[mid-1] + nums[mid]) / 2), nil
+		}
+
+		return numberRollup(nums[mid]), nil
+	case RollupConfigFunctionMin:
+		return numberRollup(nums[0]), nil
+	case RollupConfigFunctionMax:
+		return numberRollup(nums[len(nums)-1]), nil
+	default: // range
+		return numberRollup(nums[len(nums)-1] - nums[0]), nil
+	}
+}
+
+func dateRollup(fn RollupConfigFunction, values []PropertyValue) (Rollup, error) {
+	var earliest, latest *time.Time
+
+	// the result only has time information if any of the values has
+	dateOnly := true
+
+	for _, v := range values {
+		if isEmptyProperty(v) {
+			continue
+		}
+
+		d, ok := propertyRange(v)
+		if !ok {
+			return Rollup{}, fmt.Errorf("cannot use %s properties", v.Type)
+		}
+
+		start, end := d.Start, d.Start
+		if d.End != nil {
+			end = *d.End
+		}
+
+		if earliest == nil || start.Before(*earliest) {
+			earliest = &start
+		}
+
+		if latest == nil || end.After(*latest) {
+			latest = &end
+		}
+
+		dateOnly = dateOnly && d.DateOnly
+	}
+
+	if earliest == nil {
+		return Rollup{Type: RollupTypeDate}, nil
+	}
+
+	switch fn {
+	case RollupConfigFunctionEarliestDate:
+		return Rollup{Type: RollupTypeDate, Date: &Date{Start: *earliest, DateOnly: dateOnly}}, nil
+	case RollupConfigFunctionLatestDate:
+		return Rollup{Type: RollupTypeDate, Date: &Date{Start: *latest, DateOnly: dateOnly}}, nil
+	default: // date range
+		return Rollup{Type: RollupTypeDate, Date: &Date{Start: *earliest, End: latest, DateOnly: dateOnly}}, nil
+	}
+}
+
+// propertyRange returns the value of a date or timestamp as a date.
+func propertyRange(v PropertyValue) (Date, bool) {
+	if d, ok := propertyDate(v); ok {
+		return d, true
+	}
+
+	t, ok := propertyTime(v)
+
+	return Date{Start: t}, ok
+}
@@ -0,0 +1,153 @@
+package notion_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/faetools/go-notion-example/fake"
+	. "github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeRollup_Example(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db, err := fake.NotionClient.GetNotionDatabase(ctx, childDatabaseID)
+	require.NoError(t, err)
+
+	entries, err := fake.NotionClient.GetAllDatabaseEntries(ctx, childDatabaseID)
+	require.NoError(t, err)
+
+	lookup := LookupPages(entries)
+
+	// the rollups of the example database give the same results as notion
+	for _, p := range entries {
+		for _, name := range []string{"count of relations", "rollup date", "rollup string"} {
+			got, err := ComputeRollup(db.Properties[name].GetRollup(), p, lookup)
+			require.NoError(t, err)
+
+			want, err := json.Marshal(p.Properties[name].GetRollup())
+			require.NoError(t, err)
+
+			b, err := json.Marshal(got)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(b), "%s of %s", name, p.Title())
+		}
+	}
+}
+
+func TestComputeRollup(t *testing.T) {
+	t.Parallel()
+
+	num := func(f float64) PropertyValue { return PropertyValue{Type: PropertyTypeNumber, Number: &f} }
+	date := func(day int) PropertyValue {
+		d := NewDateOnly(2024, time.January, day)
+		return PropertyValue{Type: PropertyTypeDate, Date: &d}
+	}
+	checkbox := func(b bool) PropertyValue { return PropertyValue{Type: PropertyTypeCheckbox, Checkbox: &b} }
+	tags := func(names ...string) PropertyValue {
+		opts := SelectValues{}
+		for _, n := range names {
+			opts = append(opts, SelectValue{Name: n})
+		}
+
+		return PropertyValue{Type: PropertyTypeMultiSelect, MultiSelect: &opts}
+	}
+
+	related := Pages{
+		{Id: "a", Properties: PropertyValueMap{
+			"Estimate": num(3), "Due": date(10), "Done": checkbox(true), "Tags": tags("x", "y"),
+		}},
+		{Id: "b", Properties: PropertyValueMap{
+			"Estimate": num(5), "Due": date(3), "Done": checkbox(false), "Tags": tags("y"),
+		}},
+		{Id: "c", Properties: PropertyValueMap{
+			"Estimate": {Type: PropertyTypeNumber}, "Due": {Type: PropertyTypeDate}, "Done": checkbox(true), "Tags": tags(),
+		}},
+	}
+
+	p := Page{Id: "p", Properties: PropertyValueMap{
+		"Tasks": {Type: PropertyTypeRelation, Relation: &References{{Id: "a"}, {Id: "b"}, {Id: "c"}}},
+	}}
+
+	compute := func(prop string, fn RollupConfigFunction) Rollup {
+		t.Helper()
+
+		r, err := ComputeRollup(RollupConfig{
+			Function: fn, RelationPropertyName: "Tasks", RollupPropertyName: prop,
+		}, p, LookupPages(related))
+		require.NoError(t, err)
+		assert.Equal(t, string(fn), r.Function)
+
+		return r
+	}
+
+	for fn, want := range map[RollupConfigFunction]float64{
+		RollupConfigFunctionSum:             8,
+		RollupConfigFunctionAverage:         4,
+		RollupConfigFunctionMin:             3,
+		RollupConfigFunctionMax:             5,
+		RollupConfigFunctionRange:           2,
+		RollupConfigFunctionMedian:          4,
+		RollupConfigFunctionCountAll:        3,
+		RollupConfigFunctionCountEmpty:      1,
+		RollupConfigFunctionNotEmpty:        2,
+		RollupConfigFunctionPercentNotEmpty: 2. / 3,
+	} {
+		assert.Equal(t, want, *compute("Estimate", fn).Number, fn)
+	}
+
+	assert.Equal(t, 2.0, *compute("Done", RollupConfigFunctionChecked).Number)
+	assert.Equal(t, 1./3, *compute("Done", RollupConfigFunctionPercentUnchecked).Number)
+	assert.Equal(t, 3.0, *compute("Tags", RollupConfigFunctionCountValues).Number)
+	assert.Equal(t, 2.0, *compute("Tags", RollupConfigFunctionCountUniqueValues).Number)
+
+	assert.Equal(t, "2024-01-03", compute("Due", RollupConfigFunctionEarliestDate).Date.String())
+	assert.Equal(t, "2024-01-10", compute("Due", RollupConfigFunctionLatestDate).Date.String())
+	assert.Equal(t, "2024-01-03 - 2024-01-10", compute("Due", RollupConfigFunctionDateRange).Date.String())
+
+	arr := compute("Estimate", RollupConfigFunctionShowOriginal)
+	require.Equal(t, RollupTypeArray, arr.Type)
+	require.Len(t, *arr.Array, 3)
+	assert.Equal(t, RollupArrayItemTypeNumber, (*arr.Array)[0].Type)
+	assert.Equal(t, 3.0, *(*arr.Array)[0].Number)
+
+	_, err := ComputeRollup(RollupConfig{
+		Function: RollupConfigFunctionSum, RelationPropertyName: "Tasks", RollupPropertyName: "Tags",
+	}, p, LookupPages(related))
+	assert.EqualError(t, err, `rollup sum of "Tags": cannot use multi_select properties`)
+
+	_, err = ComputeRollup(RollupConfig{
+		Function: RollupConfigFunctionCount, RelationPropertyName: "Tasks", RollupPropertyName: "Tags",
+	}, p, LookupPages(related[:1]))
+	assert.ErrorIs(t, err, ErrPageNotFound)
+}
+
+func TestRollup_JSON(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		rollup Rollup
+		json   string
+	}{
+		{Rollup{Type: RollupTypeNumber, Function: "sum"}, `{"type":"number","number":null,"function":"sum"}`},
+		{Rollup{Type: RollupTypeDate, Function: "earliest_date"}, `{"type":"date","date":null,"function":"earliest_date"}`},
+		{
+			Rollup{Type: RollupTypeArray, Function: "show_original", Array: &RollupArray{{Type: RollupArrayItemTypeDate}}},
+			`{"type":"array","array":[{"type":"date","date":null}],"function":"show_original"}`,
+		},
+	} {
+		b, err := json.Marshal(tc.rollup)
+		require.NoError(t, err)
+		assert.JSONEq(t, tc.json, string(b))
+
+		var got Rollup
+		require.NoError(t, json.Unmarshal(b, &got))
+		assert.Equal(t, tc.rollup, got)
+	}
+}
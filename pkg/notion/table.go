@@ -0,0 +1,178 @@
+package notion
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// TableData is the content of a table block.
+type TableData struct {
+	// Header are the cells of the first row if the table has a column header.
+	Header []RichTexts
+	// Rows are the cells of all other rows.
+	Rows [][]RichTexts
+
+	// HasRowHeader defines whether the first column of the table is shown as header.
+	HasRowHeader bool
+}
+
+// TableOptions define how a table is created.
+type TableOptions struct {
+	// HasColumnHeader shows the first row as header.
+	HasColumnHeader bool
+	// HasRowHeader shows the first column as header.
+	HasRowHeader bool
+
+	// After is the block after which the table is inserted.
+	// If nil, the table is appended at the end.
+	After *UUID
+}
+
+// NewTableData returns the content of the table block, given its rows.
+func NewTableData(table Block, rows Blocks) (*TableData, error) {
+	if table.Type != BlockTypeTable {
+		return nil, fmt.Errorf("block %s is of type %q, not a table", table.Id, table.Type)
+	}
+
+	data := &TableData{HasRowHeader: table.Table.HasRowHeader}
+
+	for i, row := range rows {
+		if row.Type != BlockTypeTableRow {
+			return nil, fmt.Errorf("child %d of table %s is of type %q, not a table row", i, table.Id, row.Type)
+		}
+
+		if len(row.TableRow.Cells) != table.Table.TableWidth {
+			return nil, fmt.Errorf("row %d of table %s has %d cells, expected %d",
+				i, table.Id, len(row.TableRow.Cells), table.Table.TableWidth)
+		}
+
+		if i == 0 && table.Table.HasColumnHeader {
+			data.Header = row.TableRow.Cells
+			continue
+		}
+
+		data.Rows = append(data.Rows, row.TableRow.Cells)
+	}
+
+	return data, nil
+}
+
+// Headers returns the text of the header cells or nil if the table has no column header.
+func (t TableData) Headers() []string {
+	if t.Header == nil {
+		return nil
+	}
+
+	return mapSlice(t.Header, RichTexts.Content)
+}
+
+// Strings returns the text of all cells, excluding the header.
+func (t TableData) Strings() [][]string {
+	return mapSlice(t.Rows, func(row []RichTexts) []string {
+		return mapSlice(row, RichTexts.Content)
+	})
+}
+
+// WriteCSV writes the text of all cells, including the header, as CSV.
+func (t TableData) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if t.Header != nil {
+		if err := cw.Write(t.Headers()); err != nil {
+			return err
+		}
+	}
+
+	if err := cw.WriteAll(t.Strings()); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+// ReadTableCSV reads the rows of a table from CSV.
+// All rows need to have the same number of cells.
+func ReadTableCSV(r io.Reader) ([][]string, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("CSV is empty")
+	}
+
+	return rows, nil
+}
+
+// GetTable returns the content of the table block with the given ID.
+func (c Client) GetTable(ctx context.Context, id Id) (*TableData, error) {
+	table, err := c.GetNotionBlock(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting table %s: %w", id, err)
+	}
+
+	rows, err := c.GetAllBlocks(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting rows of table %s: %w", id, err)
+	}
+
+	return NewTableData(*table, rows)
+}
+
+// CreateTable adds a table with the given rows to the page or block.
+// The first row is shown as header if the options say so.
+func (c Client) CreateTable(ctx context.Context, parent Id, rows [][]string, opts TableOptions) (*Block, error) {
+	table, err := NewTable(rows, opts.HasColumnHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	table.Table.HasRowHeader = opts.HasRowHeader
+
+	// the table can only be created with a limited number of rows, the rest is appended afterwards
+	rest := table.Table.Children[min(MaxBlockChildren, len(rows)):]
+	table.Table.Children = table.Table.Children[:min(MaxBlockChildren, len(rows))]
+
+	created, err := c.AppendNotionBlocks(ctx, parent, opts.After, table)
+	if err != nil {
+		return nil, fmt.Errorf("creating table: %w", err)
+	}
+
+	if len(created) != 1 {
+		return nil, fmt.Errorf("created a table but got %d blocks back", len(created))
+	}
+
+	for start := 0; start < len(rest); start += MaxBlockChildren {
+		chunk := rest[start:min(start+MaxBlockChildren, len(rest))]
+
+		if _, err := c.AppendNotionBlocks(ctx, Id(created[0].Id), nil, chunk...); err != nil {
+			return nil, fmt.Errorf("appending rows to table %s: %w", created[0].Id, err)
+		}
+	}
+
+	return &created[0], nil
+}
+
+// ImportTableCSV creates a table from the CSV in the page or block.
+func (c Client) ImportTableCSV(ctx context.Context, parent Id, r io.Reader, opts TableOptions) (*Block, error) {
+	rows, err := ReadTableCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.CreateTable(ctx, parent, rows, opts)
+}
+
+// ExportTableCSV writes the content of the table block with the given ID as CSV.
+func (c Client) ExportTableCSV(ctx context.Context, id Id, w io.Writer) error {
+	t, err := c.GetTable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return t.WriteCSV(w)
+}
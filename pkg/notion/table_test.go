@@ -0,0 +1,169 @@
+package notion_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/faetools/client"
+	"github.com/faetools/go-notion-example/fake"
+	. "github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTable(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	table, err := fake.NotionClient.GetTable(ctx, "edf66def-c7ba-4945-8eac-15a6f77ffc98")
+	require.NoError(t, err)
+
+	assert.True(t, table.HasRowHeader)
+	assert.Equal(t, []string{"Person", "Wortform"}, table.Headers())
+	assert.Equal(t, [][]string{
+		{"ich", "bin"},
+		{"du", "bist"},
+		{"er/sie/es", "ist"},
+	}, table.Strings())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, table.WriteCSV(buf))
+	assert.Equal(t, "Person,Wortform\nich,bin\ndu,bist\ner/sie/es,ist\n", buf.String())
+
+	// a paragraph
+	_, err = fake.NotionClient.GetTable(ctx, "180df4ec-bdf2-4f2c-a489-9aefec8a400d")
+	assert.ErrorContains(t, err, "not a table")
+}
+
+func TestTableData(t *testing.T) {
+	t.Parallel()
+
+	rows, err := ReadTableCSV(strings.NewReader("a,b\n\"1,5\",2\n"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"1,5", "2"}}, rows)
+
+	table, err := NewTable(rows, false)
+	require.NoError(t, err)
+
+	data, err := NewTableData(table, table.Table.Children)
+	require.NoError(t, err)
+
+	assert.Nil(t, data.Headers())
+	assert.Equal(t, rows, data.Strings())
+
+	_, err = NewTableData(table, Blocks{NewTableRow("only one cell")})
+	assert.ErrorContains(t, err, "row 0 of table  has 1 cells, expected 2")
+
+	_, err = ReadTableCSV(strings.NewReader(""))
+	assert.Error(t, err)
+
+	_, err = ReadTableCSV(strings.NewReader("a,b\nc\n"))
+	assert.Error(t, err)
+}
+
+// appendServer records the number of blocks appended to each block and returns them.
+type appendServer struct {
+	mu      sync.Mutex
+	appends []string
+}
+
+func (s *appendServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var req struct {
+		Children []json.RawMessage `json:"children"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/blocks/"), "/children")
+	call := fmt.Sprintf("%s: %d", id, len(req.Children))
+
+	if len(req.Children) == 1 {
+		var table struct {
+			Table *struct {
+				Children []json.RawMessage `json:"children"`
+			} `json:"table"`
+		}
+
+		if err := json.Unmarshal(req.Children[0], &table); err == nil && table.Table != nil {
+			call += fmt.Sprintf(" with %d rows", len(table.Table.Children))
+		}
+	}
+
+	s.appends = append(s.appends, call)
+
+	res, _ := json.Marshal(map[string]any{"object": "list", "results": req.Children})
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(res)
+}
+
+func TestCreateTable(t *testing.T) {
+	t.Parallel()
+
+	srv := &appendServer{}
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	cli, err := NewDefaultClient("", client.WithBaseURL(ts.URL))
+	require.NoError(t, err)
+
+	rows := make([][]string, 250)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprint(i), "cell"}
+	}
+
+	table, err := cli.CreateTable(context.Background(), "page", rows, TableOptions{HasColumnHeader: true})
+	require.NoError(t, err)
+	assert.Equal(t, BlockTypeTable, table.Type)
+
+	// the table is created with as many rows as possible, the others are appended in chunks
+	assert.Equal(t, []string{
+		"page: 1 with 100 rows",
+		string(table.Id) + ": 100",
+		string(table.Id) + ": 50",
+	}, srv.appends)
+}
+
+func TestUpdateNotionBlock_Table(t *testing.T) {
+	t.Parallel()
+
+	var body string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"object":"block","id":"table","type":"table","table":{"table_width":2,"has_column_header":true}}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	cli, err := NewDefaultClient("", client.WithBaseURL(ts.URL))
+	require.NoError(t, err)
+
+	table, err := NewTable([][]string{{"a", "b"}, {"1", "2"}}, true)
+	require.NoError(t, err)
+
+	table.Id = "table"
+
+	// rows and the width cannot be changed by updating the table
+	_, err = cli.UpdateNotionBlock(context.Background(), table)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"table":{"has_column_header":true,"has_row_header":false}}`, body)
+}
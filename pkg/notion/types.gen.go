@@ -147,14 +147,31 @@ const (
 
 // Defines values for FileType.
 const (
-	FileTypeExternal FileType = "external"
-	FileTypeFile     FileType = "file"
+	FileTypeExternal   FileType = "external"
+	FileTypeFile       FileType = "file"
+	FileTypeFileUpload FileType = "file_upload"
+)
+
+// Defines values for FileUploadStatus.
+const (
+	FileUploadStatusExpired  FileUploadStatus = "expired"
+	FileUploadStatusFailed   FileUploadStatus = "failed"
+	FileUploadStatusPending  FileUploadStatus = "pending"
+	FileUploadStatusUploaded FileUploadStatus = "uploaded"
+)
+
+// Defines values for FileUploadRequestMode.
+const (
+	FileUploadRequestModeExternalUrl FileUploadRequestMode = "external_url"
+	FileUploadRequestModeMultiPart   FileUploadRequestMode = "multi_part"
+	FileUploadRequestModeSinglePart  FileUploadRequestMode = "single_part"
 )
 
 // Defines values for FileWithCaptionType.
 const (
-	FileWithCaptionTypeExternal FileWithCaptionType = "external"
-	FileWithCaptionTypeFile     FileWithCaptionType = "file"
+	FileWithCaptionTypeExternal   FileWithCaptionType = "external"
+	FileWithCaptionTypeFile       FileWithCaptionType = "file"
+	FileWithCaptionTypeFileUpload FileWithCaptionType = "file_upload"
 )
 
 // Defines values for FormulaType.
@@ -167,9 +184,10 @@ const (
 
 // Defines values for IconType.
 const (
-	IconTypeEmoji    IconType = "emoji"
-	IconTypeExternal IconType = "external"
-	IconTypeFile     IconType = "file"
+	IconTypeEmoji      IconType = "emoji"
+	IconTypeExternal   IconType = "external"
+	IconTypeFile       IconType = "file"
+	IconTypeFileUpload IconType = "file_upload"
 )
 
 // Defines values for LinkToPageType.
@@ -256,6 +274,7 @@ const (
 	PropertyTypeSelect         PropertyType = "select"
 	PropertyTypeStatus         PropertyType = "status"
 	PropertyTypeTitle          PropertyType = "title"
+	PropertyTypeUniqueId       PropertyType = "unique_id"
 	PropertyTypeUrl            PropertyType = "url"
 )
 
@@ -282,28 +301,52 @@ const (
 
 // Defines values for RollupArrayItemType.
 const (
-	RollupArrayItemTypeDate   RollupArrayItemType = "date"
-	RollupArrayItemTypeNumber RollupArrayItemType = "number"
-	RollupArrayItemTypeString RollupArrayItemType = "string"
-	RollupArrayItemTypeTitle  RollupArrayItemType = "title"
+	RollupArrayItemTypeCheckbox    RollupArrayItemType = "checkbox"
+	RollupArrayItemTypeDate        RollupArrayItemType = "date"
+	RollupArrayItemTypeEmail       RollupArrayItemType = "email"
+	RollupArrayItemTypeFiles       RollupArrayItemType = "files"
+	RollupArrayItemTypeFormula     RollupArrayItemType = "formula"
+	RollupArrayItemTypeMultiSelect RollupArrayItemType = "multi_select"
+	RollupArrayItemTypeNumber      RollupArrayItemType = "number"
+	RollupArrayItemTypePeople      RollupArrayItemType = "people"
+	RollupArrayItemTypePhoneNumber RollupArrayItemType = "phone_number"
+	RollupArrayItemTypeRelation    RollupArrayItemType = "relation"
+	RollupArrayItemTypeRichText    RollupArrayItemType = "rich_text"
+	RollupArrayItemTypeSelect      RollupArrayItemType = "select"
+	RollupArrayItemTypeStatus      RollupArrayItemType = "status"
+	RollupArrayItemTypeString      RollupArrayItemType = "string"
+	RollupArrayItemTypeTitle       RollupArrayItemType = "title"
+	RollupArrayItemTypeUrl         RollupArrayItemType = "url"
 )
 
 // Defines values for RollupConfigFunction.
 const (
 	RollupConfigFunctionAverage           RollupConfigFunction = "average"
+	RollupConfigFunctionChecked           RollupConfigFunction = "checked"
+	RollupConfigFunctionCount             RollupConfigFunction = "count"
 	RollupConfigFunctionCountAll          RollupConfigFunction = "count_all"
 	RollupConfigFunctionCountEmpty        RollupConfigFunction = "count_empty"
 	RollupConfigFunctionCountNotEmpty     RollupConfigFunction = "count_not_empty"
 	RollupConfigFunctionCountUniqueValues RollupConfigFunction = "count_unique_values"
 	RollupConfigFunctionCountValues       RollupConfigFunction = "count_values"
+	RollupConfigFunctionDateRange         RollupConfigFunction = "date_range"
+	RollupConfigFunctionEarliestDate      RollupConfigFunction = "earliest_date"
+	RollupConfigFunctionEmpty             RollupConfigFunction = "empty"
+	RollupConfigFunctionLatestDate        RollupConfigFunction = "latest_date"
 	RollupConfigFunctionMax               RollupConfigFunction = "max"
 	RollupConfigFunctionMedian            RollupConfigFunction = "median"
 	RollupConfigFunctionMin               RollupConfigFunction = "min"
+	RollupConfigFunctionNotEmpty          RollupConfigFunction = "not_empty"
+	RollupConfigFunctionPercentChecked    RollupConfigFunction = "percent_checked"
 	RollupConfigFunctionPercentEmpty      RollupConfigFunction = "percent_empty"
 	RollupConfigFunctionPercentNotEmpty   RollupConfigFunction = "percent_not_empty"
+	RollupConfigFunctionPercentUnchecked  RollupConfigFunction = "percent_unchecked"
 	RollupConfigFunctionRange             RollupConfigFunction = "range"
 	RollupConfigFunctionShowOriginal      RollupConfigFunction = "show_original"
+	RollupConfigFunctionShowUnique        RollupConfigFunction = "show_unique"
 	RollupConfigFunctionSum               RollupConfigFunction = "sum"
+	RollupConfigFunctionUnchecked         RollupConfigFunction = "unchecked"
+	RollupConfigFunctionUnique            RollupConfigFunction = "unique"
 )
 
 // Defines values for SearchFilterProperty.
@@ -317,6 +360,17 @@ const (
 	SearchFilterValuePage     SearchFilterValue = "page"
 )
 
+// Defines values for SearchSortDirection.
+const (
+	SearchSortDirectionAscending  SearchSortDirection = "ascending"
+	SearchSortDirectionDescending SearchSortDirection = "descending"
+)
+
+// Defines values for SearchSortTimestamp.
+const (
+	SearchSortTimestampLastEditedTime SearchSortTimestamp = "last_edited_time"
+)
+
 // Defines values for SearchResultObject.
 const (
 	SearchResultObjectList SearchResultObject = "list"
@@ -502,6 +556,8 @@ type Blocks []Block
 
 // BlocksChildren defines model for BlocksChildren.
 type BlocksChildren struct {
+	// A unique identifier for a page, block, database, user, or option.
+	After    *UUID  `json:"after,omitempty"`
 	Children Blocks `json:"children"`
 }
 
@@ -529,7 +585,7 @@ type Callout struct {
 	Color Color `json:"color"`
 
 	// Page or database icon. It is either an emoji or a file.
-	Icon     Icon      `json:"icon"`
+	Icon     *Icon     `json:"icon,omitempty"`
 	RichText RichTexts `json:"rich_text"`
 }
 
@@ -614,6 +670,9 @@ type DatabaseQuery struct {
 
 // Date defines model for Date.
 type Date struct {
+	// Whether start and end are dates without time information. Not part of the API, it decides how start and end are formatted.
+	DateOnly bool `json:"-"`
+
 	// An ISO 8601 formatted date, with optional time. Represents the end of a date range.
 	//
 	// If `null`, this property's date value is not a range.
@@ -638,6 +697,9 @@ type DateFilter struct {
 	// Returns database entries where the `date` property value is before the provided date.
 	Before *time.Time `json:"before,omitempty"`
 
+	// Whether the dates of the filter are sent without time information. Not part of the API.
+	DateOnly bool `json:"-"`
+
 	// Returns database entries where the `date` property value is the provided date.
 	Equals *time.Time `json:"equals,omitempty"`
 
@@ -717,6 +779,9 @@ type File struct {
 	// File objects contain this information within the `file` property.
 	File *NotionFile `json:"file,omitempty"`
 
+	// A reference to a file upload to attach it to a block or page.
+	FileUpload *FileUploadReference `json:"file_upload,omitempty"`
+
 	// A string value corresponding to a filename of the original file upload
 	Name *string `json:"name,omitempty"`
 
@@ -727,6 +792,84 @@ type File struct {
 // Type of this file object.
 type FileType string
 
+// A file upload receives the content of a file that can then be attached to blocks and pages.
+type FileUpload struct {
+	// URL to complete an upload in multiple parts.
+	CompleteUrl *string `json:"complete_url,omitempty"`
+
+	// Size of the file in bytes once it is uploaded.
+	ContentLength *int `json:"content_length,omitempty"`
+
+	// MIME type of the file.
+	ContentType *string `json:"content_type,omitempty"`
+
+	// Date and time when this file upload was created.
+	CreatedTime time.Time `json:"created_time"`
+
+	// Date and time when the file upload expires if it is not attached.
+	ExpiryTime *time.Time `json:"expiry_time,omitempty"`
+
+	// Name of the file.
+	Filename *string `json:"filename,omitempty"`
+
+	// A unique identifier for a page, block, database, user, or option.
+	Id UUID `json:"id"`
+
+	// Date and time when this file upload was updated.
+	LastEditedTime time.Time `json:"last_edited_time"`
+
+	// Progress of an upload in multiple parts.
+	NumberOfParts *FileUploadParts `json:"number_of_parts,omitempty"`
+
+	// Always "file_upload".
+	Object string `json:"object"`
+
+	// Status of the file upload.
+	Status FileUploadStatus `json:"status"`
+
+	// URL to send the content of the file to.
+	UploadUrl *string `json:"upload_url,omitempty"`
+}
+
+// Status of the file upload.
+type FileUploadStatus string
+
+// Progress of an upload in multiple parts.
+type FileUploadParts struct {
+	// Number of parts that were sent.
+	SentCount int `json:"sent_count"`
+
+	// Number of parts of the file.
+	Total int `json:"total"`
+}
+
+// A reference to a file upload to attach it to a block or page.
+type FileUploadReference struct {
+	// A unique identifier for a page, block, database, user, or option.
+	Id UUID `json:"id"`
+}
+
+// Creates a file upload.
+type FileUploadRequest struct {
+	// MIME type of the file.
+	ContentType *string `json:"content_type,omitempty"`
+
+	// URL of the file to import for uploads from an external URL.
+	ExternalUrl *string `json:"external_url,omitempty"`
+
+	// Name of the file. Required for uploads in multiple parts.
+	Filename *string `json:"filename,omitempty"`
+
+	// How the file is uploaded. Defaults to single_part.
+	Mode *FileUploadRequestMode `json:"mode,omitempty"`
+
+	// Number of parts for uploads in multiple parts.
+	NumberOfParts *int `json:"number_of_parts,omitempty"`
+}
+
+// How the file is uploaded. Defaults to single_part.
+type FileUploadRequestMode string
+
 // File objects contain data about files uploaded to Notion as well as external files linked in Notion. A PDF can also have a caption.
 type FileWithCaption struct {
 	Caption *RichTexts `json:"caption,omitempty"`
@@ -737,6 +880,9 @@ type FileWithCaption struct {
 	// File objects contain this information within the `file` property.
 	File *NotionFile `json:"file,omitempty"`
 
+	// A reference to a file upload to attach it to a block or page.
+	FileUpload *FileUploadReference `json:"file_upload,omitempty"`
+
 	// Type of this file object.
 	Type FileWithCaptionType `json:"type"`
 }
@@ -845,6 +991,9 @@ type Icon struct {
 	// File objects contain this information within the `file` property.
 	File *NotionFile `json:"file,omitempty"`
 
+	// A reference to a file upload to attach it to a block or page.
+	FileUpload *FileUploadReference `json:"file_upload,omitempty"`
+
 	// Type of icon.
 	Type IconType `json:"type"`
 }
@@ -1181,7 +1330,8 @@ type PropertyValue struct {
 	Id string `json:"id"`
 
 	// The User object represents a user in a Notion workspace. Users include full workspace members, and bots. Guests are not included.
-	LastEditedBy *User `json:"last_edited_by,omitempty"`
+	LastEditedBy   *User      `json:"last_edited_by,omitempty"`
+	LastEditedTime *time.Time `json:"last_edited_time,omitempty"`
 
 	// An array of multi-select or select option values.
 	MultiSelect *SelectValues `json:"multi_select,omitempty"`
@@ -1210,6 +1360,9 @@ type PropertyValue struct {
 	// Type of the property.
 	Type PropertyType `json:"type"`
 
+	// Unique ID property values contain an automatically incremented number and an optional prefix.
+	UniqueId *UniqueId `json:"unique_id,omitempty"`
+
 	// URL property value objects contain a non-empty string within the url property. The string describes a web address.
 	Url *string `json:"url,omitempty"`
 }
@@ -1341,15 +1494,33 @@ type RollupArray []RollupArrayItem
 
 // RollupArrayItem defines model for RollupArrayItem.
 type RollupArrayItem struct {
-	Date *Date `json:"date,omitempty"`
+	Checkbox *bool   `json:"checkbox,omitempty"`
+	Date     *Date   `json:"date,omitempty"`
+	Email    *string `json:"email,omitempty"`
+	Files    *Files  `json:"files,omitempty"`
+
+	// Formula property value objects represent the result of evaluating a formula described in the database's properties. These objects contain a type key and a key corresponding with the value of type. The value of a formula cannot be updated directly.
+	//
+	// ## Formula values may not match the Notion UI.
+	//
+	// Formulas returned in page objects are subject to a 25 page reference limitation. The Retrieve a page property endpoint should be used to get an accurate formula value.
+	Formula     *Formula      `json:"formula,omitempty"`
+	MultiSelect *SelectValues `json:"multi_select,omitempty"`
 
 	// Number rollup property values contain a number within the number property.
-	Number *float64 `json:"number,omitempty"`
+	Number      *float64     `json:"number,omitempty"`
+	People      *[]User      `json:"people,omitempty"`
+	PhoneNumber *string      `json:"phone_number,omitempty"`
+	Relation    *References  `json:"relation,omitempty"`
+	RichText    *RichTexts   `json:"rich_text,omitempty"`
+	Select      *SelectValue `json:"select,omitempty"`
+	Status      *SelectValue `json:"status,omitempty"`
 
 	// String rollup property values contain an optional string within the string property.
 	String *string             `json:"string,omitempty"`
 	Title  *RichTexts          `json:"title,omitempty"`
 	Type   RollupArrayItemType `json:"type"`
+	Url    *string             `json:"url,omitempty"`
 }
 
 // RollupArrayItemType defines model for RollupArrayItem.Type.
@@ -1384,10 +1555,13 @@ type RollupConfigFunction string
 //
 // The response may contain fewer than `page_size` of results.
 type Search struct {
-	Filter      *SearchFilter `json:"filter,omitempty"`
-	PageSize    *int          `json:"page_size,omitempty"`
-	Query       *string       `json:"query,omitempty"`
-	StartCursor *string       `json:"start_cursor,omitempty"`
+	Filter   *SearchFilter `json:"filter,omitempty"`
+	PageSize *int          `json:"page_size,omitempty"`
+	Query    *string       `json:"query,omitempty"`
+
+	// Sorts the results by the time they were last edited.
+	Sort        *SearchSort `json:"sort,omitempty"`
+	StartCursor *string     `json:"start_cursor,omitempty"`
 }
 
 // SearchFilter defines model for SearchFilter.
@@ -1417,6 +1591,18 @@ type SearchResult struct {
 // SearchResultObject defines model for SearchResult.Object.
 type SearchResultObject string
 
+// Sorts the results by the time they were last edited.
+type SearchSort struct {
+	Direction SearchSortDirection `json:"direction"`
+	Timestamp SearchSortTimestamp `json:"timestamp"`
+}
+
+// SearchSortDirection defines model for SearchSort.Direction.
+type SearchSortDirection string
+
+// SearchSortTimestamp defines model for SearchSort.Timestamp.
+type SearchSortTimestamp string
+
 // SelectFilter defines model for SelectFilter.
 type SelectFilter struct {
 	// Returns database entries where the select property value does not match the provided string.
@@ -1534,6 +1720,7 @@ type StatusOptions []StatusOption
 
 // SyncedBlock defines model for SyncedBlock.
 type SyncedBlock struct {
+	Children   Blocks      `json:"children,omitempty"`
 	SyncedFrom *SyncedFrom `json:"synced_from"`
 }
 
@@ -1549,6 +1736,8 @@ type SyncedFromType string
 
 // Table defines model for Table.
 type Table struct {
+	Children Blocks `json:"children,omitempty"`
+
 	// Whether or not the table has a column header. If true, the first row in the table will appear visually distinct from the other rows.
 	HasColumnHeader bool `json:"has_column_header"`
 
@@ -1620,6 +1809,15 @@ type ToDo struct {
 // A unique identifier for a page, block, database, user, or option.
 type UUID string
 
+// Unique ID property values contain an automatically incremented number and an optional prefix.
+type UniqueId struct {
+	// The number, which is incremented for every new page of the database.
+	Number int `json:"number"`
+
+	// The prefix of the ID, if the database defines one.
+	Prefix *string `json:"prefix"`
+}
+
 // The User object represents a user in a Notion workspace. Users include full workspace members, and bots. Guests are not included.
 type User struct {
 	// Chosen avatar image.
@@ -1715,6 +1913,9 @@ type AppendBlocksJSONBody BlocksChildren
 // QueryDatabaseJSONBody defines parameters for QueryDatabase.
 type QueryDatabaseJSONBody DatabaseQuery
 
+// CreateFileUploadJSONBody defines parameters for CreateFileUpload.
+type CreateFileUploadJSONBody FileUploadRequest
+
 // CreatePageJSONBody defines parameters for CreatePage.
 type CreatePageJSONBody Page
 
@@ -1745,6 +1946,9 @@ type UpdateDatabaseJSONRequestBody DatabaseRequestBody
 // QueryDatabaseJSONRequestBody defines body for QueryDatabase for application/json ContentType.
 type QueryDatabaseJSONRequestBody QueryDatabaseJSONBody
 
+// CreateFileUploadJSONRequestBody defines body for CreateFileUpload for application/json ContentType.
+type CreateFileUploadJSONRequestBody CreateFileUploadJSONBody
+
 // CreatePageJSONRequestBody defines body for CreatePage for application/json ContentType.
 type CreatePageJSONRequestBody CreatePageJSONBody
 
@@ -0,0 +1,358 @@
+package notion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRefreshInterval is the default time after which the users are listed again.
+const defaultRefreshInterval = 10 * time.Minute
+
+var (
+	// ErrUserNotFound is returned when no user matches.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrAmbiguousUser is returned when more than one user matches.
+	ErrAmbiguousUser = errors.New("ambiguous user")
+)
+
+var _ UserDirectoryClient = (*Client)(nil)
+
+// UserDirectoryClient is any client that can read users.
+type UserDirectoryClient interface {
+	// ListAllUsers returns all users in the workspace.
+	ListAllUsers(ctx context.Context) (Users, error)
+	// GetNotionUser returns the user.
+	GetNotionUser(ctx context.Context, id Id) (*User, error)
+	// GetNotionMe returns the bot user of the integration.
+	GetNotionMe(ctx context.Context) (*User, error)
+}
+
+// UserDirectoryOptions define how users are cached.
+type UserDirectoryOptions struct {
+	// RefreshInterval is how long the users are cached before they are listed again.
+	// Defaults to 10 minutes.
+	RefreshInterval time.Duration
+}
+
+// IsBot reports whether the user is a bot.
+func (u User) IsBot() bool { return u.Type != nil && *u.Type == UserTypeBot }
+
+// IsPerson reports whether the user is a person.
+func (u User) IsPerson() bool { return u.Type != nil && *u.Type == UserTypePerson }
+
+// GetName returns the name of the user or an empty string.
+func (u User) GetName() string {
+	if u.Name == nil {
+		return ""
+	}
+
+	return *u.Name
+}
+
+// GetEmail returns the email address of a person or an empty string.
+func (u User) GetEmail() string {
+	if u.Person == nil {
+		return ""
+	}
+
+	return string(u.Person.Email)
+}
+
+// isPartial reports whether only the ID of the user is known.
+func (u User) isPartial() bool { return u.Type == nil && u.Name == nil }
+
+// UserDirectory caches the users of a workspace and finds them by ID, email address or name.
+// It is safe for concurrent use.
+type UserDirectory struct {
+	cli  UserDirectoryClient
+	opts UserDirectoryOptions
+
+	mu      sync.Mutex
+	users   Users
+	fetched time.Time
+	me      *User
+	byID    map[string]User
+	byEmail map[string]User
+	byName  map[string]Users
+	// missing contains the IDs of users that could not be found since the last refresh
+	missing map[string]bool
+}
+
+// NewUserDirectory returns a new user directory.
+// The users are listed on first use.
+func NewUserDirectory(cli UserDirectoryClient, opts UserDirectoryOptions) *UserDirectory {
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = defaultRefreshInterval
+	}
+
+	return &UserDirectory{cli: cli, opts: opts}
+}
+
+// Refresh lists the users again.
+func (d *UserDirectory) Refresh(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.refresh(ctx)
+}
+
+func (d *UserDirectory) refresh(ctx context.Context) error {
+	users, err := d.cli.ListAllUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("listing users: %w", err)
+	}
+
+	d.users, d.fetched = users, time.Now()
+	d.byID = make(map[string]User, len(users))
+	d.byEmail = map[string]User{}
+	d.byName = map[string]Users{}
+	d.missing = map[string]bool{}
+
+	for _, u := range users {
+		d.add(u)
+	}
+
+	return nil
+}
+
+// add indexes the user.
+func (d *UserDirectory) add(u User) {
+	d.byID[idKey(string(u.Id))] = u
+
+	if email := u.GetEmail(); email != "" {
+		d.byEmail[strings.ToLower(email)] = u
+	}
+
+	if name := u.GetName(); name != "" {
+		key := strings.ToLower(name)
+		d.byName[key] = append(d.byName[key], u)
+	}
+}
+
+// ensure lists the users if they were never listed or the refresh interval passed.
+func (d *UserDirectory) ensure(ctx context.Context) error {
+	if d.byID != nil && time.Since(d.fetched) < d.opts.RefreshInterval {
+		return nil
+	}
+
+	return d.refresh(ctx)
+}
+
+// All returns all users of the workspace.
+func (d *UserDirectory) All(ctx context.Context) (Users, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	return append(Users{}, d.users...), nil
+}
+
+// People returns all users of the workspace that are people.
+func (d *UserDirectory) People(ctx context.Context) (Users, error) {
+	return d.filter(ctx, User.IsPerson)
+}
+
+// Bots returns all users of the workspace that are bots.
+func (d *UserDirectory) Bots(ctx context.Context) (Users, error) {
+	return d.filter(ctx, User.IsBot)
+}
+
+func (d *UserDirectory) filter(ctx context.Context, keep func(User) bool) (Users, error) {
+	all, err := d.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users := Users{}
+
+	for _, u := range all {
+		if keep(u) {
+			users = append(users, u)
+		}
+	}
+
+	return users, nil
+}
+
+// Me returns the bot user of the integration.
+func (d *UserDirectory) Me(ctx context.Context) (*User, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.me == nil {
+		me, err := d.cli.GetNotionMe(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting bot user: %w", err)
+		}
+
+		d.me = me
+	}
+
+	me := *d.me
+
+	return &me, nil
+}
+
+// ByID returns the user with the ID.
+// Users that are not listed, e.g. because they left the workspace, are fetched individually.
+func (d *UserDirectory) ByID(ctx context.Context, id UUID) (*User, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	key := idKey(string(id))
+
+	if u, ok := d.byID[key]; ok {
+		return &u, nil
+	}
+
+	if d.missing[key] {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, id)
+	}
+
+	u, err := d.cli.GetNotionUser(ctx, Id(id))
+	if err != nil {
+		var notionErr *Error
+		if !errors.As(err, &notionErr) || notionErr.Status != http.StatusNotFound {
+			return nil, fmt.Errorf("getting user %s: %w", id, err)
+		}
+
+		d.missing[key] = true
+
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, id)
+	}
+
+	d.byID[key] = *u
+
+	return u, nil
+}
+
+// ByEmail returns the person with the email address, ignoring case.
+func (d *UserDirectory) ByEmail(ctx context.Context, email string) (*User, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	u, ok := d.byEmail[strings.ToLower(strings.TrimSpace(email))]
+	if !ok {
+		return nil, fmt.Errorf("%w: no person with email %q", ErrUserNotFound, email)
+	}
+
+	return &u, nil
+}
+
+// ByName returns the only user with the name, ignoring case.
+func (d *UserDirectory) ByName(ctx context.Context, name string) (*User, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	users := d.byName[strings.ToLower(strings.TrimSpace(name))]
+
+	switch len(users) {
+	case 0:
+		return nil, fmt.Errorf("%w: no user named %q", ErrUserNotFound, name)
+	case 1:
+		u := users[0]
+		return &u, nil
+	default:
+		return nil, fmt.Errorf("%w: %d users are named %q", ErrAmbiguousUser, len(users), name)
+	}
+}
+
+// Resolve returns the user identified by an ID, an email address or a name.
+func (d *UserDirectory) Resolve(ctx context.Context, s string) (*User, error) {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case IsUUID(s):
+		return d.ByID(ctx, UUID(s))
+	case strings.Contains(s, "@"):
+		return d.ByEmail(ctx, s)
+	default:
+		return d.ByName(ctx, s)
+	}
+}
+
+// Hydrate replaces the user with the full user if only its ID is known.
+func (d *UserDirectory) Hydrate(ctx context.Context, u *User) error {
+	if u == nil || !u.isPartial() {
+		return nil
+	}
+
+	full, err := d.ByID(ctx, u.Id)
+	if err != nil {
+		return err
+	}
+
+	*u = *full
+
+	return nil
+}
+
+// HydratePage replaces the users that created and last edited the page
+// as well as the users in its created by, last edited by and people properties
+// with the full users if only their IDs are known.
+// Users that cannot be found are left as they are.
+func (d *UserDirectory) HydratePage(ctx context.Context, p *Page) error {
+	users := []*User{p.CreatedBy, p.LastEditedBy}
+
+	for _, prop := range p.Properties {
+		switch prop.Type {
+		case PropertyTypeCreatedBy:
+			users = append(users, prop.CreatedBy)
+		case PropertyTypeLastEditedBy:
+			users = append(users, prop.LastEditedBy)
+		case PropertyTypePeople:
+			if prop.People == nil {
+				continue
+			}
+
+			for i := range *prop.People {
+				users = append(users, &(*prop.People)[i])
+			}
+		}
+	}
+
+	for _, u := range users {
+		if err := d.Hydrate(ctx, u); err != nil && !errors.Is(err, ErrUserNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HydratePages hydrates the users of all pages, see HydratePage.
+func (d *UserDirectory) HydratePages(ctx context.Context, pages Pages) error {
+	for i := range pages {
+		if err := d.HydratePage(ctx, &pages[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// idKey returns the ID in a canonical form, so IDs with and without dashes match.
+func idKey(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
@@ -0,0 +1,160 @@
+package notion_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userClient has a fixed set of users.
+type userClient struct {
+	mu      sync.Mutex
+	users   notion.Users
+	former  map[notion.Id]notion.User
+	lists   int
+	fetches int
+}
+
+func (c *userClient) ListAllUsers(context.Context) (notion.Users, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lists++
+
+	return c.users, nil
+}
+
+func (c *userClient) GetNotionUser(_ context.Context, id notion.Id) (*notion.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fetches++
+
+	if u, ok := c.former[id]; ok {
+		return &u, nil
+	}
+
+	return nil, &notion.Error{Status: http.StatusNotFound, Code: "object_not_found", Message: "not found"}
+}
+
+func (c *userClient) GetNotionMe(context.Context) (*notion.User, error) {
+	return &c.users[2], nil
+}
+
+func newUser(id notion.UUID, name, email string, typ notion.UserType) notion.User {
+	u := notion.User{Id: id, Name: &name, Type: &typ}
+	if email != "" {
+		u.Person = &notion.Person{Email: openapi_types.Email(email)}
+	}
+
+	return u
+}
+
+func TestUserDirectory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	ada := newUser("6f1e2a4c-1d2b-4c3a-9b8e-7f6a5b4c3d2e", "Ada", "Ada@example.com", notion.UserTypePerson)
+	grace := newUser("7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d", "Grace", "grace@example.com", notion.UserTypePerson)
+	bot := newUser("8b9c0d1e-2f3a-4b4c-9d5e-6f7a8b9c0d1e", "Importer", "", notion.UserTypeBot)
+	twin := newUser("9c0d1e2f-3a4b-4c5d-8e6f-7a8b9c0d1e2f", "grace", "grace.twin@example.com", notion.UserTypePerson)
+	former := newUser("0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3a", "Former", "", notion.UserTypePerson)
+
+	cli := &userClient{
+		users:  notion.Users{ada, grace, bot, twin},
+		former: map[notion.Id]notion.User{notion.Id(former.Id): former},
+	}
+
+	dir := notion.NewUserDirectory(cli, notion.UserDirectoryOptions{RefreshInterval: 50 * time.Millisecond})
+
+	people, err := dir.People(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, notion.Users{ada, grace, twin}, people)
+
+	bots, err := dir.Bots(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, notion.Users{bot}, bots)
+
+	me, err := dir.Me(ctx)
+	require.NoError(t, err)
+	assert.True(t, me.IsBot())
+
+	for _, s := range []string{
+		"ada@EXAMPLE.com",
+		"6f1e2a4c1d2b4c3a9b8e7f6a5b4c3d2e",
+		" ada ",
+	} {
+		u, err := dir.Resolve(ctx, s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, ada, *u, s)
+		}
+	}
+
+	_, err = dir.Resolve(ctx, "Grace")
+	assert.True(t, errors.Is(err, notion.ErrAmbiguousUser))
+	assert.EqualError(t, err, `ambiguous user: 2 users are named "Grace"`)
+
+	_, err = dir.Resolve(ctx, "nobody@example.com")
+	assert.True(t, errors.Is(err, notion.ErrUserNotFound))
+
+	// users that are not listed anymore are fetched
+	u, err := dir.ByID(ctx, former.Id)
+	require.NoError(t, err)
+	assert.Equal(t, former, *u)
+
+	_, err = dir.ByID(ctx, "1e2f3a4b-5c6d-4e7f-8a9b-0c1d2e3f4a5b")
+	assert.True(t, errors.Is(err, notion.ErrUserNotFound))
+
+	_, err = dir.ByID(ctx, "1e2f3a4b-5c6d-4e7f-8a9b-0c1d2e3f4a5b")
+	assert.True(t, errors.Is(err, notion.ErrUserNotFound))
+
+	assert.Equal(t, 1, cli.lists, "users should be cached")
+	assert.Equal(t, 2, cli.fetches, "missing users should be cached")
+
+	time.Sleep(60 * time.Millisecond)
+
+	_, err = dir.ByEmail(ctx, "grace@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cli.lists, "users should be listed again after the refresh interval")
+}
+
+func TestUserDirectory_HydratePage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	ada := newUser("6f1e2a4c-1d2b-4c3a-9b8e-7f6a5b4c3d2e", "Ada", "ada@example.com", notion.UserTypePerson)
+	bot := newUser("8b9c0d1e-2f3a-4b4c-9d5e-6f7a8b9c0d1e", "Importer", "", notion.UserTypeBot)
+
+	dir := notion.NewUserDirectory(&userClient{users: notion.Users{ada, bot}}, notion.UserDirectoryOptions{})
+
+	partial := func(id notion.UUID) *notion.User { return &notion.User{Id: id, Object: "user"} }
+
+	p := notion.Page{
+		CreatedBy:    partial(ada.Id),
+		LastEditedBy: partial(bot.Id),
+		Properties: notion.PropertyValueMap{
+			"Created by": {Type: notion.PropertyTypeCreatedBy, CreatedBy: partial(ada.Id)},
+			"Assignees": {Type: notion.PropertyTypePeople, People: &[]notion.User{
+				*partial(bot.Id), *partial("0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3a"),
+			}},
+		},
+	}
+
+	require.NoError(t, dir.HydratePage(ctx, &p))
+
+	assert.Equal(t, ada, *p.CreatedBy)
+	assert.Equal(t, bot, *p.LastEditedBy)
+	assert.Equal(t, ada, p.Properties["Created by"].GetCreatedBy())
+	assert.Equal(t, notion.Users{bot, *partial("0d1e2f3a-4b5c-4d6e-9f7a-8b9c0d1e2f3a")},
+		p.Properties["Assignees"].GetPeople(), "unknown users should be left as they are")
+}
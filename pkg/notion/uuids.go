@@ -1,5 +1,24 @@
 package notion
 
+import "github.com/google/uuid"
+
+// IsUUID reports whether s is a UUID, with or without dashes.
+func IsUUID(s string) bool {
+	if len(s) != 32 && len(s) != 36 {
+		return false
+	}
+
+	_, err := uuid.Parse(s)
+
+	return err == nil
+}
+
+// Equal reports whether both IDs refer to the same object,
+// regardless of dashes and case.
+func (id UUID) Equal(other UUID) bool {
+	return idKey(string(id)) == idKey(string(other))
+}
+
 type UUIDs []UUID
 
 func (ids UUIDs) Strings() []string {
@@ -0,0 +1,45 @@
+package notion_test
+
+import (
+	"testing"
+
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUUID(t *testing.T) {
+	t.Parallel()
+
+	for s, want := range map[string]bool{
+		"20c5eed2-59ea-476e-af69-da0ae68a084d":   true,
+		"20c5eed259ea476eaf69da0ae68a084d":       true,
+		"20C5EED259EA476EAF69DA0AE68A084D":       true,
+		"20c5eed2-59ea-476e-af69-da0ae68a084":    false,
+		"20c5eed259ea476eaf69da0ae68a084z":       false,
+		"{20c5eed2-59ea-476e-af69-da0ae68a084d}": false,
+		"Write docs":                             false,
+		"":                                       false,
+	} {
+		assert.Equal(t, want, notion.IsUUID(s), s)
+	}
+}
+
+func TestPropertyType_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, notion.PropertyTypeFormula.ReadOnly())
+	assert.True(t, notion.PropertyTypeLastEditedTime.ReadOnly())
+	assert.False(t, notion.PropertyTypeTitle.ReadOnly())
+	assert.False(t, notion.PropertyTypeRelation.ReadOnly())
+}
+
+func TestUUID_Equal(t *testing.T) {
+	t.Parallel()
+
+	id := notion.UUID("20c5eed2-59ea-476e-af69-da0ae68a084d")
+
+	assert.True(t, id.Equal(id))
+	assert.True(t, id.Equal("20c5eed259ea476eaf69da0ae68a084d"))
+	assert.True(t, id.Equal("20C5EED2-59EA-476E-AF69-DA0AE68A084D"))
+	assert.False(t, id.Equal("20c5eed2-59ea-476e-af69-da0ae68a084e"))
+}
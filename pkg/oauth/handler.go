@@ -0,0 +1,153 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultStateCookie is the default name of the cookie holding the state.
+	defaultStateCookie = "notion_oauth_state"
+
+	// stateTTL is how long users have to authorize the integration.
+	stateTTL = 10 * time.Minute
+)
+
+var (
+	// ErrInvalidState is passed to OnError if the state of the callback does not match the cookie.
+	ErrInvalidState = errors.New("invalid state")
+
+	// ErrAccessDenied is passed to OnError if the user did not authorize the integration.
+	ErrAccessDenied = errors.New("access denied")
+)
+
+// HandlerOptions define how the callback is handled.
+type HandlerOptions struct {
+	// StateCookie is the name of the cookie holding the state. Defaults to "notion_oauth_state".
+	StateCookie string
+
+	// OnSuccess is called with the token after it was stored.
+	// By default, a short confirmation is written.
+	OnSuccess func(w http.ResponseWriter, r *http.Request, tok *Token)
+
+	// OnError is called if the callback fails, together with the suggested status code.
+	// By default, the error is written with the status code.
+	OnError func(w http.ResponseWriter, r *http.Request, err error, code int)
+}
+
+// Handler is an http.Handler for the redirect URI of the integration.
+// It verifies the state, exchanges the code for a token and stores it.
+type Handler struct {
+	cfg   Config
+	store TokenStore
+	opts  HandlerOptions
+}
+
+// NewHandler returns a handler for the redirect URI.
+func NewHandler(cfg Config, store TokenStore, opts HandlerOptions) *Handler {
+	if opts.StateCookie == "" {
+		opts.StateCookie = defaultStateCookie
+	}
+
+	if opts.OnSuccess == nil {
+		opts.OnSuccess = func(w http.ResponseWriter, _ *http.Request, tok *Token) {
+			name := tok.WorkspaceID
+			if tok.WorkspaceName != nil {
+				name = *tok.WorkspaceName
+			}
+
+			fmt.Fprintf(w, "Connected to %s.\n", name)
+		}
+	}
+
+	if opts.OnError == nil {
+		opts.OnError = func(w http.ResponseWriter, _ *http.Request, err error, code int) {
+			http.Error(w, err.Error(), code)
+		}
+	}
+
+	return &Handler{cfg: cfg, store: store, opts: opts}
+}
+
+// Authorize redirects to Notion so the user can authorize the integration.
+// The state is stored in a cookie to verify the callback.
+func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
+	state, err := newState()
+	if err != nil {
+		h.opts.OnError(w, r, fmt.Errorf("creating state: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.opts.StateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   int(stateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, h.cfg.AuthorizationURL(state), http.StatusFound)
+}
+
+// ServeHTTP fulfils http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	q := r.URL.Query()
+
+	cookie, err := r.Cookie(h.opts.StateCookie)
+	if err != nil || cookie.Value == "" ||
+		subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(q.Get("state"))) != 1 {
+		h.opts.OnError(w, r, ErrInvalidState, http.StatusBadRequest)
+		return
+	}
+
+	// the state can only be used once
+	http.SetCookie(w, &http.Cookie{Name: h.opts.StateCookie, Path: "/", MaxAge: -1})
+
+	if e := q.Get("error"); e != "" {
+		h.opts.OnError(w, r, fmt.Errorf("%w: %s", ErrAccessDenied, e), http.StatusForbidden)
+		return
+	}
+
+	code := q.Get("code")
+	if code == "" {
+		h.opts.OnError(w, r, errors.New("missing code"), http.StatusBadRequest)
+		return
+	}
+
+	tok, err := h.cfg.Exchange(r.Context(), code)
+	if err != nil {
+		h.opts.OnError(w, r, err, http.StatusBadGateway)
+		return
+	}
+
+	if err := h.store.Put(r.Context(), *tok); err != nil {
+		h.opts.OnError(w, r, fmt.Errorf("storing token: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.opts.OnSuccess(w, r, tok)
+}
+
+// newState returns a random state.
+func newState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
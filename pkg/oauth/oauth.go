@@ -0,0 +1,201 @@
+// Package oauth implements the OAuth 2.0 flow of public Notion integrations.
+//
+// Users are redirected to Notion to authorize the integration, see Handler.Authorize
+// and Config.AuthorizationURL. Notion then redirects them back with a temporary code,
+// which the Handler exchanges for an access token and saves in a TokenStore. A ClientFactory returns clients for a workspace
+// with the token of that workspace.
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/faetools/client"
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Config is the configuration of a public integration.
+type Config struct {
+	// ClientID is the OAuth client ID of the integration.
+	ClientID string
+	// ClientSecret is the OAuth client secret of the integration.
+	ClientSecret string
+	// RedirectURI is the URI Notion redirects to after the user authorized the integration.
+	RedirectURI string
+
+	// BaseURL is the URL of the Notion API. Defaults to notion.DefaultServer.
+	BaseURL string
+	// HTTPClient sends the requests. Defaults to http.DefaultClient.
+	HTTPClient client.HTTPRequestDoer
+}
+
+// Owner is the owner of a bot.
+type Owner struct {
+	// Type is either "user" or "workspace".
+	Type string `json:"type"`
+	// User is the user who authorized the integration, if the type is "user".
+	User *notion.User `json:"user,omitempty"`
+	// Workspace is true if the type is "workspace".
+	Workspace bool `json:"workspace,omitempty"`
+}
+
+// Token is the access token of a workspace that authorized the integration.
+type Token struct {
+	AccessToken  string  `json:"access_token"`
+	TokenType    string  `json:"token_type"`
+	RefreshToken *string `json:"refresh_token,omitempty"`
+
+	// BotID is the ID of the bot user of the integration in the workspace.
+	BotID         notion.UUID `json:"bot_id"`
+	WorkspaceID   string      `json:"workspace_id"`
+	WorkspaceName *string     `json:"workspace_name,omitempty"`
+	WorkspaceIcon *string     `json:"workspace_icon,omitempty"`
+	Owner         Owner       `json:"owner"`
+
+	// DuplicatedTemplateID is the ID of the page the user duplicated into the workspace, if any.
+	DuplicatedTemplateID *notion.UUID `json:"duplicated_template_id,omitempty"`
+}
+
+// Introspection describes an access token.
+type Introspection struct {
+	// Active reports whether the token can be used.
+	Active bool `json:"active"`
+	// Scope are the capabilities of the token, separated by spaces.
+	Scope string `json:"scope,omitempty"`
+	// IssuedAt is when the token was issued.
+	IssuedAt int64 `json:"iat,omitempty"`
+}
+
+func (c Config) baseURL() string {
+	if c.BaseURL == "" {
+		return notion.DefaultServer
+	}
+
+	return c.BaseURL
+}
+
+// AuthorizationURL returns the URL to redirect users to so they can authorize the integration.
+// The state is passed back to the redirect URI unchanged.
+func (c Config) AuthorizationURL(state string) string {
+	q := url.Values{
+		"client_id":     {c.ClientID},
+		"response_type": {"code"},
+		"owner":         {"user"},
+	}
+
+	if c.RedirectURI != "" {
+		q.Set("redirect_uri", c.RedirectURI)
+	}
+
+	if state != "" {
+		q.Set("state", state)
+	}
+
+	return c.baseURL() + "/v1/oauth/authorize?" + q.Encode()
+}
+
+// Exchange exchanges the code Notion passed to the redirect URI for an access token.
+func (c Config) Exchange(ctx context.Context, code string) (*Token, error) {
+	body := map[string]string{"grant_type": "authorization_code", "code": code}
+	if c.RedirectURI != "" {
+		body["redirect_uri"] = c.RedirectURI
+	}
+
+	tok := &Token{}
+	if err := c.post(ctx, "/v1/oauth/token", body, tok); err != nil {
+		return nil, fmt.Errorf("exchanging code: %w", err)
+	}
+
+	return tok, nil
+}
+
+// Introspect returns whether the access token is active and its scope.
+func (c Config) Introspect(ctx context.Context, token string) (*Introspection, error) {
+	res := &Introspection{}
+	if err := c.post(ctx, "/v1/oauth/introspect", map[string]string{"token": token}, res); err != nil {
+		return nil, fmt.Errorf("introspecting token: %w", err)
+	}
+
+	return res, nil
+}
+
+// Revoke revokes the access token.
+func (c Config) Revoke(ctx context.Context, token string) error {
+	if err := c.post(ctx, "/v1/oauth/revoke", map[string]string{"token": token}, nil); err != nil {
+		return fmt.Errorf("revoking token: %w", err)
+	}
+
+	return nil
+}
+
+// post sends the body to the endpoint, authenticated with the client credentials,
+// and decodes the response into dst unless it is nil.
+func (c Config) post(ctx context.Context, path string, body, dst any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL()+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(c.ClientID, c.ClientSecret)
+	req.Header.Set(client.ContentType, client.MIMEApplicationJSON)
+	req.Header.Set(notion.VersionHeader, notion.Version)
+
+	doer := c.HTTPClient
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, respBody)
+	}
+
+	if dst == nil {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, dst)
+}
+
+// responseError returns the error of an unsuccessful response,
+// which is either a Notion error or an OAuth error as defined by RFC 6749.
+func responseError(resp *http.Response, body []byte) error {
+	var res struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+
+		OAuthError  string `json:"error"`
+		Description string `json:"error_description"`
+	}
+
+	if err := json.Unmarshal(body, &res); err == nil {
+		switch {
+		case res.Code != "":
+			return &notion.Error{Object: "error", Status: resp.StatusCode, Code: res.Code, Message: res.Message}
+		case res.OAuthError != "":
+			return &notion.Error{Object: "error", Status: resp.StatusCode, Code: res.OAuthError, Message: res.Description}
+		}
+	}
+
+	return fmt.Errorf("unknown %s response: %s", resp.Status, body)
+}
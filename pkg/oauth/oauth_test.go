@@ -0,0 +1,201 @@
+package oauth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/faetools/go-notion/pkg/oauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// server fakes the OAuth endpoints of Notion.
+type server struct {
+	t       *testing.T
+	revoked []string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != "client" || secret != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"bad credentials"}`))
+
+		return
+	}
+
+	assert.Equal(s.t, notion.Version, r.Header.Get(notion.VersionHeader))
+
+	body := map[string]string{}
+	require.NoError(s.t, json.NewDecoder(r.Body).Decode(&body))
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.URL.Path {
+	case "/v1/oauth/token":
+		assert.Equal(s.t, "authorization_code", body["grant_type"])
+		assert.Equal(s.t, "https://example.com/callback", body["redirect_uri"])
+
+		if body["code"] != "valid" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"object":"error","status":400,"code":"invalid_grant","message":"Invalid code."}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{
+			"access_token": "secret_workspace",
+			"token_type": "bearer",
+			"bot_id": "b1",
+			"workspace_id": "w1",
+			"workspace_name": "Acme",
+			"owner": {"type": "user", "user": {"object": "user", "id": "u1"}}
+		}`))
+	case "/v1/oauth/introspect":
+		_, _ = w.Write([]byte(`{"active": true, "scope": "read_content", "iat": 1727554061617}`))
+	case "/v1/oauth/revoke":
+		s.revoked = append(s.revoked, body["token"])
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newConfig(t *testing.T) (oauth.Config, *server) {
+	t.Helper()
+
+	srv := &server{t: t}
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	return oauth.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURI:  "https://example.com/callback",
+		BaseURL:      ts.URL,
+	}, srv
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg, srv := newConfig(t)
+
+	u, err := url.Parse(cfg.AuthorizationURL("xyz"))
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/oauth/authorize", u.Path)
+	assert.Equal(t, url.Values{
+		"client_id":     {"client"},
+		"owner":         {"user"},
+		"redirect_uri":  {"https://example.com/callback"},
+		"response_type": {"code"},
+		"state":         {"xyz"},
+	}, u.Query())
+
+	tok, err := cfg.Exchange(ctx, "valid")
+	require.NoError(t, err)
+	assert.Equal(t, "secret_workspace", tok.AccessToken)
+	assert.Equal(t, "w1", tok.WorkspaceID)
+	assert.Equal(t, notion.UUID("u1"), tok.Owner.User.Id)
+
+	_, err = cfg.Exchange(ctx, "invalid")
+	assert.EqualError(t, err, "exchanging code: 400 Bad Request: invalid_grant - Invalid code.")
+
+	info, err := cfg.Introspect(ctx, tok.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, &oauth.Introspection{Active: true, Scope: "read_content", IssuedAt: 1727554061617}, info)
+
+	require.NoError(t, cfg.Revoke(ctx, tok.AccessToken))
+	assert.Equal(t, []string{"secret_workspace"}, srv.revoked)
+
+	cfg.ClientSecret = "wrong"
+	err = cfg.Revoke(ctx, tok.AccessToken)
+	assert.EqualError(t, err, "revoking token: 401 Unauthorized: invalid_client - bad credentials")
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	cfg, _ := newConfig(t)
+	store := oauth.NewMemoryStore()
+	h := oauth.NewHandler(cfg, store, oauth.HandlerOptions{})
+
+	// start the flow
+	rec := httptest.NewRecorder()
+	h.Authorize(rec, httptest.NewRequest(http.MethodGet, "/connect", nil))
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+
+	state := loc.Query().Get("state")
+	require.Equal(t, cookies[0].Value, state)
+
+	callback := func(query string, withCookie bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/callback?"+query, nil)
+		if withCookie {
+			req.AddCookie(cookies[0])
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec = callback("code=valid&state=other", true)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid state\n", rec.Body.String())
+
+	rec = callback("code=valid&state="+state, false)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callback("error=access_denied&state="+state, true)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = callback("code=invalid&state="+state, true)
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+
+	rec = callback("code=valid&state="+state, true)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Connected to Acme.\n", rec.Body.String())
+
+	ctx := context.Background()
+
+	tok, err := store.Get(ctx, "w1")
+	require.NoError(t, err)
+	assert.Equal(t, "secret_workspace", tok.AccessToken)
+
+	// clients are created per workspace
+	f := oauth.NewClientFactory(store)
+
+	cli, err := f.Client(ctx, "w1")
+	require.NoError(t, err)
+
+	again, err := f.Client(ctx, "w1")
+	require.NoError(t, err)
+	assert.Same(t, cli, again)
+
+	tok.AccessToken = "secret_renewed"
+	require.NoError(t, store.Put(ctx, *tok))
+
+	renewed, err := f.Client(ctx, "w1")
+	require.NoError(t, err)
+	assert.NotSame(t, cli, renewed, "client should use the new token")
+
+	require.NoError(t, store.Delete(ctx, "w1"))
+
+	_, err = f.Client(ctx, "w1")
+	assert.True(t, errors.Is(err, oauth.ErrTokenNotFound))
+}
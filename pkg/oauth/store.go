@@ -0,0 +1,116 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/faetools/client"
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// ErrTokenNotFound is returned by token stores when there is no token for a workspace.
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenStore stores the access tokens of workspaces.
+type TokenStore interface {
+	// Get returns the token of the workspace or ErrTokenNotFound.
+	Get(ctx context.Context, workspaceID string) (*Token, error)
+	// Put stores the token, replacing any token of the same workspace.
+	Put(ctx context.Context, tok Token) error
+	// Delete removes the token of the workspace.
+	Delete(ctx context.Context, workspaceID string) error
+}
+
+var _ TokenStore = (*MemoryStore)(nil)
+
+// MemoryStore is a TokenStore that keeps the tokens in memory.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tokens map[string]Token
+}
+
+// NewMemoryStore returns a new, empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: map[string]Token{}}
+}
+
+// Get fulfils TokenStore.
+func (s *MemoryStore) Get(_ context.Context, workspaceID string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tok, ok := s.tokens[workspaceID]
+	if !ok {
+		return nil, fmt.Errorf("%w for workspace %s", ErrTokenNotFound, workspaceID)
+	}
+
+	return &tok, nil
+}
+
+// Put fulfils TokenStore.
+func (s *MemoryStore) Put(_ context.Context, tok Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[tok.WorkspaceID] = tok
+
+	return nil
+}
+
+// Delete fulfils TokenStore.
+func (s *MemoryStore) Delete(_ context.Context, workspaceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, workspaceID)
+
+	return nil
+}
+
+// ClientFactory returns clients that use the access token of a workspace.
+// It is safe for concurrent use.
+type ClientFactory struct {
+	store TokenStore
+	opts  []client.Option
+
+	mu      sync.Mutex
+	clients map[string]cachedClient
+}
+
+type cachedClient struct {
+	token string
+	cli   *notion.Client
+}
+
+// NewClientFactory returns a factory creating clients with the tokens of the store.
+// The options are passed to every client.
+func NewClientFactory(store TokenStore, opts ...client.Option) *ClientFactory {
+	return &ClientFactory{store: store, opts: opts, clients: map[string]cachedClient{}}
+}
+
+// Client returns a client for the workspace.
+// Clients are reused until the token of the workspace changes.
+func (f *ClientFactory) Client(ctx context.Context, workspaceID string) (*notion.Client, error) {
+	tok, err := f.store.Get(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if c, ok := f.clients[workspaceID]; ok && c.token == tok.AccessToken {
+		return c.cli, nil
+	}
+
+	cli, err := notion.NewDefaultClient(tok.AccessToken, f.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	f.clients[workspaceID] = cachedClient{token: tok.AccessToken, cli: cli}
+
+	return cli, nil
+}
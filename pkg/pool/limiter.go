@@ -0,0 +1,66 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter limits the rate of requests of one workspace,
+// allowing bursts of up to burst requests.
+type limiter struct {
+	interval  time.Duration
+	tolerance time.Duration
+
+	mu sync.Mutex
+	// next is the theoretical time of the next request if requests were evenly spaced
+	next time.Time
+}
+
+func newLimiter(perSecond float64, burst int) *limiter {
+	interval := time.Duration(float64(time.Second) / perSecond)
+
+	return &limiter{interval: interval, tolerance: time.Duration(burst-1) * interval}
+}
+
+// wait blocks until the request may be sent and returns how long it waited.
+func (l *limiter) wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+
+	now := time.Now()
+
+	t := l.next
+	if t.Before(now) {
+		t = now
+	}
+
+	l.next = t.Add(l.interval)
+	delay := t.Add(-l.tolerance).Sub(now)
+
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// pause delays all requests until the time passed, e.g. after being rate limited by Notion.
+func (l *limiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// burst requests must not be sent right away either
+	if until := time.Now().Add(d + l.tolerance); until.After(l.next) {
+		l.next = until
+	}
+}
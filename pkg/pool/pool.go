@@ -0,0 +1,253 @@
+// Package pool provides clients for many workspaces, each with its own token.
+//
+// The clients of all workspaces share one transport, so connections are reused,
+// but every workspace has its own rate limiter, response cache and metrics,
+// so a workspace sending many requests does not slow down the others.
+// Clients that were not used for a while are evicted.
+package pool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/faetools/client"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/faetools/go-notion/pkg/oauth"
+)
+
+const (
+	// defaultRequestsPerSecond is the average rate Notion allows per integration.
+	defaultRequestsPerSecond = 3
+
+	// defaultConcurrency is the default number of concurrent requests per workspace.
+	defaultConcurrency = 4
+
+	// defaultIdleTimeout is the default time after which unused clients are evicted.
+	defaultIdleTimeout = 30 * time.Minute
+)
+
+// TokenSource returns the token of a workspace or bot.
+type TokenSource interface {
+	Token(ctx context.Context, key string) (string, error)
+}
+
+// TokenSourceFunc is a function that fulfils TokenSource.
+type TokenSourceFunc func(ctx context.Context, key string) (string, error)
+
+// Token fulfils TokenSource.
+func (f TokenSourceFunc) Token(ctx context.Context, key string) (string, error) { return f(ctx, key) }
+
+// StoreTokens returns a token source for the tokens of an OAuth token store, keyed by workspace ID.
+func StoreTokens(store oauth.TokenStore) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context, workspaceID string) (string, error) {
+		tok, err := store.Get(ctx, workspaceID)
+		if err != nil {
+			return "", err
+		}
+
+		return tok.AccessToken, nil
+	})
+}
+
+// Options define how the clients of a pool behave.
+type Options struct {
+	// Transport is shared by the clients of all workspaces. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// RequestsPerSecond is the average number of requests per second of each workspace. Defaults to 3.
+	RequestsPerSecond float64
+	// Burst is the number of requests a workspace can send at once, exceeding the rate. Defaults to 1.
+	Burst int
+	// Concurrency is the maximum number of concurrent requests of each workspace. Defaults to 4.
+	Concurrency int
+
+	// CacheTTL is how long successful GET responses are cached per workspace.
+	// Other requests of a workspace clear its cache. No responses are cached by default.
+	CacheTTL time.Duration
+
+	// IdleTimeout is the time after which a client that was not used is evicted. Defaults to 30 minutes.
+	IdleTimeout time.Duration
+
+	// ClientOptions are passed to each client, e.g. to change the base URL.
+	ClientOptions []client.Option
+}
+
+// ClientPool lazily creates a client for each workspace and reuses it.
+// It is safe for concurrent use.
+type ClientPool struct {
+	tokens TokenSource
+	opts   Options
+
+	mu         sync.Mutex
+	workspaces map[string]*workspace
+	swept      time.Time
+}
+
+// New returns a new client pool getting the tokens of workspaces from the token source.
+func New(tokens TokenSource, opts Options) *ClientPool {
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+
+	if opts.RequestsPerSecond <= 0 {
+		opts.RequestsPerSecond = defaultRequestsPerSecond
+	}
+
+	if opts.Burst <= 0 {
+		opts.Burst = 1
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = defaultIdleTimeout
+	}
+
+	return &ClientPool{
+		tokens:     tokens,
+		opts:       opts,
+		workspaces: map[string]*workspace{},
+		swept:      time.Now(),
+	}
+}
+
+// Client returns the client of the workspace or bot, creating it if needed.
+// If the token of a client was rejected, a new client is created with a fresh token.
+func (p *ClientPool) Client(ctx context.Context, key string) (*notion.Client, error) {
+	p.mu.Lock()
+	p.sweep()
+	ws, ok := p.usable(key)
+	p.mu.Unlock()
+
+	if ok {
+		return ws.cli, nil
+	}
+
+	// get the token without blocking the other workspaces
+	token, err := p.tokens.Token(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("getting token of %s: %w", key, err)
+	}
+
+	ws, err = p.newWorkspace(token)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// another goroutine might have been faster
+	if existing, ok := p.usable(key); ok {
+		return existing.cli, nil
+	}
+
+	p.workspaces[key] = ws
+
+	return ws.cli, nil
+}
+
+// usable returns the workspace if it exists and its token was not rejected.
+func (p *ClientPool) usable(key string) (*workspace, bool) {
+	ws, ok := p.workspaces[key]
+	if !ok {
+		return nil, false
+	}
+
+	_, valid := ws.snapshot()
+
+	return ws, valid
+}
+
+func (p *ClientPool) newWorkspace(token string) (*workspace, error) {
+	now := time.Now()
+
+	ws := &workspace{
+		http:     &http.Client{Transport: p.opts.Transport},
+		limiter:  newLimiter(p.opts.RequestsPerSecond, p.opts.Burst),
+		slots:    make(chan struct{}, p.opts.Concurrency),
+		cacheTTL: p.opts.CacheTTL,
+		cache:    map[string]cachedResponse{},
+		metrics:  Metrics{Created: now, LastUsed: now},
+	}
+
+	// the doer of the pool comes last so it cannot be overridden
+	opts := append(append([]client.Option{}, p.opts.ClientOptions...), client.WithHTTPClient(ws))
+
+	cli, err := notion.NewDefaultClient(token, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.cli = cli
+
+	return ws, nil
+}
+
+// sweep evicts the workspaces whose clients were not used within the idle timeout.
+// To keep getting clients cheap, it only checks every now and then.
+func (p *ClientPool) sweep() {
+	now := time.Now()
+	if now.Sub(p.swept) < p.opts.IdleTimeout/4 {
+		return
+	}
+
+	p.swept = now
+
+	for key, ws := range p.workspaces {
+		if m, _ := ws.snapshot(); now.Sub(m.LastUsed) >= p.opts.IdleTimeout {
+			delete(p.workspaces, key)
+		}
+	}
+}
+
+// Evict removes the client of the workspace or bot, e.g. after its token was revoked.
+func (p *ClientPool) Evict(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.workspaces, key)
+}
+
+// Len returns the number of clients in the pool.
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.workspaces)
+}
+
+// Metrics returns the metrics of the workspace or bot.
+// The metrics are reset when its client is evicted or recreated.
+func (p *ClientPool) Metrics(key string) (Metrics, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ws, ok := p.workspaces[key]
+	if !ok {
+		return Metrics{}, false
+	}
+
+	m, _ := ws.snapshot()
+
+	return m, true
+}
+
+// AllMetrics returns the metrics of all workspaces and bots in the pool.
+func (p *ClientPool) AllMetrics() map[string]Metrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	all := make(map[string]Metrics, len(p.workspaces))
+
+	for key, ws := range p.workspaces {
+		all[key], _ = ws.snapshot()
+	}
+
+	return all
+}
@@ -0,0 +1,229 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/faetools/client"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/faetools/go-notion/pkg/oauth"
+	"github.com/faetools/go-notion/pkg/pool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// server answers requests for blocks and counts them per token.
+type server struct {
+	mu       sync.Mutex
+	requests map[string]int
+	revoked  map[string]bool
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.requests[token]++
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case s.revoked[token]:
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"object":"error","status":401,"code":"unauthorized","message":"API token is invalid."}`)
+	case strings.HasSuffix(r.URL.Path, "/limited"):
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"object":"error","status":429,"code":"rate_limited","message":"slow down"}`)
+	default:
+		fmt.Fprintf(w, `{"object":"block","id":%q,"type":"paragraph","paragraph":{"rich_text":[]}}`,
+			strings.TrimPrefix(r.URL.Path, "/v1/blocks/"))
+	}
+}
+
+func (s *server) count(token string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[token]
+}
+
+func newPool(t *testing.T, opts pool.Options) (*pool.ClientPool, *server, *oauth.MemoryStore) {
+	t.Helper()
+
+	srv := &server{requests: map[string]int{}, revoked: map[string]bool{}}
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	store := oauth.NewMemoryStore()
+
+	for _, ws := range []string{"noisy", "quiet"} {
+		require.NoError(t, store.Put(ctx, oauth.Token{WorkspaceID: ws, AccessToken: "token-" + ws}))
+	}
+
+	opts.ClientOptions = []client.Option{client.WithBaseURL(ts.URL)}
+
+	return pool.New(pool.StoreTokens(store), opts), srv, store
+}
+
+func TestClientPool(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	p, srv, store := newPool(t, pool.Options{RequestsPerSecond: 20, CacheTTL: time.Minute})
+
+	noisy, err := p.Client(ctx, "noisy")
+	require.NoError(t, err)
+
+	again, err := p.Client(ctx, "noisy")
+	require.NoError(t, err)
+	assert.Same(t, noisy, again)
+
+	quiet, err := p.Client(ctx, "quiet")
+	require.NoError(t, err)
+	assert.NotSame(t, noisy, quiet)
+
+	// caches are separate
+	for i := 0; i < 3; i++ {
+		_, err := noisy.GetNotionBlock(ctx, "a")
+		require.NoError(t, err)
+	}
+
+	b, err := quiet.GetNotionBlock(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, notion.UUID("a"), b.Id)
+
+	assert.Equal(t, 1, srv.count("token-noisy"))
+	assert.Equal(t, 1, srv.count("token-quiet"))
+
+	// writes clear the cache
+	_, err = noisy.DeleteNotionBlock(ctx, "b")
+	require.NoError(t, err)
+
+	_, err = noisy.GetNotionBlock(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 3, srv.count("token-noisy"))
+
+	// the noisy workspace is rate limited on its own
+	start := time.Now()
+
+	for i := 0; i < 4; i++ {
+		_, err := noisy.GetNotionBlock(ctx, notion.Id(fmt.Sprintf("noisy-%d", i)))
+		require.NoError(t, err)
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	start = time.Now()
+	_, err = quiet.GetNotionBlock(ctx, "b")
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 40*time.Millisecond, "quiet workspace should not wait for the noisy one")
+
+	_, err = noisy.GetNotionBlock(ctx, "limited")
+	assert.Error(t, err)
+
+	m, ok := p.Metrics("noisy")
+	require.True(t, ok)
+	assert.Equal(t, 8, m.Requests)
+	assert.Equal(t, 2, m.CacheHits)
+	assert.Equal(t, 1, m.RateLimited)
+	assert.Greater(t, m.Waiting, 100*time.Millisecond)
+
+	all := p.AllMetrics()
+	assert.Len(t, all, 2)
+	assert.Equal(t, 2, all["quiet"].Requests)
+
+	// a rejected token is fetched again
+	srv.mu.Lock()
+	srv.revoked["token-quiet"] = true
+	srv.mu.Unlock()
+
+	_, err = quiet.GetNotionBlock(ctx, "c")
+	assert.Error(t, err)
+
+	require.NoError(t, store.Put(ctx, oauth.Token{WorkspaceID: "quiet", AccessToken: "token-renewed"}))
+
+	renewed, err := p.Client(ctx, "quiet")
+	require.NoError(t, err)
+	assert.NotSame(t, quiet, renewed)
+
+	_, err = renewed.GetNotionBlock(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, 1, srv.count("token-renewed"))
+
+	_, err = p.Client(ctx, "unknown")
+	assert.True(t, errors.Is(err, oauth.ErrTokenNotFound))
+}
+
+func TestClientPool_CacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	p, srv, _ := newPool(t, pool.Options{RequestsPerSecond: 100, CacheTTL: 20 * time.Millisecond})
+
+	cli, err := p.Client(ctx, "noisy")
+	require.NoError(t, err)
+
+	for _, id := range []notion.Id{"a", "b", "a"} {
+		_, err := cli.GetNotionBlock(ctx, id)
+		require.NoError(t, err)
+	}
+
+	m, _ := p.Metrics("noisy")
+	assert.Equal(t, 2, m.Cached)
+	assert.Equal(t, 1, m.CacheHits)
+
+	// expired responses are fetched again and removed once another response is stored
+	time.Sleep(30 * time.Millisecond)
+
+	_, err = cli.GetNotionBlock(ctx, "c")
+	require.NoError(t, err)
+
+	m, _ = p.Metrics("noisy")
+	assert.Equal(t, 1, m.Cached)
+
+	_, err = cli.GetNotionBlock(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 4, srv.count("token-noisy"))
+}
+
+func TestClientPool_Evict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	p, _, _ := newPool(t, pool.Options{IdleTimeout: 40 * time.Millisecond})
+
+	noisy, err := p.Client(ctx, "noisy")
+	require.NoError(t, err)
+
+	_, err = p.Client(ctx, "quiet")
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.Len())
+
+	p.Evict("quiet")
+	assert.Equal(t, 1, p.Len())
+
+	_, ok := p.Metrics("quiet")
+	assert.False(t, ok)
+
+	// idle clients are evicted
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = p.Client(ctx, "quiet")
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.Len())
+
+	recreated, err := p.Client(ctx, "noisy")
+	require.NoError(t, err)
+	assert.NotSame(t, noisy, recreated)
+}
@@ -0,0 +1,228 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// defaultRetryAfter is how long requests are paused after a rate limited response without a Retry-After header.
+const defaultRetryAfter = time.Second
+
+// Metrics are the metrics of a workspace.
+type Metrics struct {
+	// Requests is the number of requests sent to Notion.
+	Requests int
+	// CacheHits is the number of requests answered from the cache.
+	CacheHits int
+	// Cached is the number of responses in the cache.
+	Cached int
+	// RateLimited is the number of responses that were rate limited by Notion.
+	RateLimited int
+	// Errors is the number of requests that failed or got a server error.
+	Errors int
+	// Waiting is the total time requests waited for the rate limiter or other requests of the workspace.
+	Waiting time.Duration
+	// Created is when the client of the workspace was created.
+	Created time.Time
+	// LastUsed is when the client of the workspace was last used.
+	LastUsed time.Time
+}
+
+// workspace holds the client of a workspace together with its rate limiter, cache and metrics.
+// It fulfils client.HTTPRequestDoer and is used by the client to send its requests.
+type workspace struct {
+	cli      *notion.Client
+	http     *http.Client
+	limiter  *limiter
+	slots    chan struct{}
+	cacheTTL time.Duration
+
+	mu      sync.Mutex
+	cache   map[string]cachedResponse
+	swept   time.Time
+	metrics Metrics
+	// invalid is set once the token was rejected
+	invalid bool
+}
+
+type cachedResponse struct {
+	resp    *http.Response
+	body    []byte
+	expires time.Time
+}
+
+func (r cachedResponse) clone(req *http.Request) *http.Response {
+	resp := *r.resp
+	resp.Body = io.NopCloser(bytes.NewReader(r.body))
+	resp.Request = req
+
+	return &resp
+}
+
+func (ws *workspace) update(f func(m *Metrics)) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	f(&ws.metrics)
+}
+
+// Do fulfils client.HTTPRequestDoer.
+func (ws *workspace) Do(req *http.Request) (*http.Response, error) {
+	ws.update(func(m *Metrics) { m.LastUsed = time.Now() })
+
+	key := req.URL.String()
+	cacheable := ws.cacheTTL > 0 && req.Method == http.MethodGet
+
+	if resp, ok := ws.cached(req, key, cacheable); ok {
+		return resp, nil
+	}
+
+	waited, err := ws.acquire(req.Context())
+	ws.update(func(m *Metrics) { m.Waiting += waited })
+
+	if err != nil {
+		return nil, err
+	}
+	defer func() { <-ws.slots }()
+
+	resp, err := ws.http.Do(req)
+	if err != nil {
+		ws.update(func(m *Metrics) { m.Requests++; m.Errors++ })
+		return nil, err
+	}
+
+	ws.update(func(m *Metrics) {
+		m.Requests++
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			m.RateLimited++
+		case resp.StatusCode >= http.StatusInternalServerError:
+			m.Errors++
+		}
+	})
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		ws.limiter.pause(retryAfter(resp))
+	case resp.StatusCode == http.StatusUnauthorized:
+		ws.mu.Lock()
+		ws.invalid = true
+		ws.mu.Unlock()
+	case cacheable && resp.StatusCode == http.StatusOK:
+		return ws.store(req, key, resp)
+	}
+
+	return resp, nil
+}
+
+// cached returns the cached response of a GET request.
+// Other requests might change what is returned, so they clear the cache.
+func (ws *workspace) cached(req *http.Request, key string, cacheable bool) (*http.Response, bool) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if !cacheable {
+		if len(ws.cache) > 0 {
+			ws.cache = map[string]cachedResponse{}
+		}
+
+		return nil, false
+	}
+
+	cached, ok := ws.cache[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(cached.expires) {
+		delete(ws.cache, key)
+		return nil, false
+	}
+
+	ws.metrics.CacheHits++
+
+	return cached.clone(req), true
+}
+
+func (ws *workspace) store(req *http.Request, key string, resp *http.Response) (*http.Response, error) {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+
+	now := time.Now()
+	cached := cachedResponse{resp: resp, body: body, expires: now.Add(ws.cacheTTL)}
+
+	ws.mu.Lock()
+	ws.sweepCache(now)
+	ws.cache[key] = cached
+	ws.mu.Unlock()
+
+	return cached.clone(req), nil
+}
+
+// sweepCache removes expired responses, which would otherwise be kept until they are requested again.
+// To keep storing responses cheap, it only checks once per TTL.
+func (ws *workspace) sweepCache(now time.Time) {
+	if now.Sub(ws.swept) < ws.cacheTTL {
+		return
+	}
+
+	ws.swept = now
+
+	for key, cached := range ws.cache {
+		if now.After(cached.expires) {
+			delete(ws.cache, key)
+		}
+	}
+}
+
+// acquire waits for a free slot and for the rate limiter and returns how long it waited.
+func (ws *workspace) acquire(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+
+	select {
+	case ws.slots <- struct{}{}:
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	}
+
+	if _, err := ws.limiter.wait(ctx); err != nil {
+		<-ws.slots
+		return time.Since(start), err
+	}
+
+	return time.Since(start), nil
+}
+
+// snapshot returns the metrics and whether the workspace can still be used.
+func (ws *workspace) snapshot() (Metrics, bool) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	m := ws.metrics
+	m.Cached = len(ws.cache)
+
+	return m, !ws.invalid
+}
+
+// retryAfter returns how long to wait according to the Retry-After header of the response.
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs <= 0 {
+		return defaultRetryAfter
+	}
+
+	return time.Duration(secs) * time.Second
+}
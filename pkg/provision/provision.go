@@ -0,0 +1,465 @@
+package provision
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+var _ Client = (*notion.Client)(nil)
+
+// Client is any client that can find and create pages and databases.
+type Client interface {
+	// GetNotionPagesByTitle returns the pages with the title.
+	GetNotionPagesByTitle(ctx context.Context, title string) (notion.Pages, error)
+	// GetNotionDatabasesByTitle returns the databases with the title.
+	GetNotionDatabasesByTitle(ctx context.Context, title string) (notion.Databases, error)
+	// GetAllDatabaseEntries returns all entries of a database.
+	GetAllDatabaseEntries(ctx context.Context, id notion.Id) (notion.Pages, error)
+
+	// CreateNotionPage creates a page.
+	CreateNotionPage(ctx context.Context, p notion.Page) (*notion.Page, error)
+	// UpdateNotionPage updates a page.
+	UpdateNotionPage(ctx context.Context, p notion.Page) (*notion.Page, error)
+	// CreateNotionDatabase creates a database.
+	CreateNotionDatabase(ctx context.Context, db notion.Database) (*notion.Database, error)
+	// UpdateNotionDatabaseProperties changes the properties of a database.
+	UpdateNotionDatabaseProperties(ctx context.Context, id notion.Id, props map[string]*notion.PropertyMeta) (
+		*notion.Database, error)
+}
+
+// IDs maps the keys of provisioned objects to their IDs.
+// Entries are keyed by the key of their database and their title, separated by a slash.
+type IDs map[string]notion.UUID
+
+// Result is the result of provisioning a specification.
+type Result struct {
+	// IDs are the IDs of all objects of the specification.
+	IDs IDs `json:"ids"`
+	// Created are the keys of the objects that were created, all other objects already existed.
+	Created []string `json:"created"`
+}
+
+// Provisioner provisions specifications.
+type Provisioner struct {
+	cli Client
+}
+
+// New returns a new provisioner.
+func New(cli Client) *Provisioner {
+	return &Provisioner{cli: cli}
+}
+
+// Provision creates all objects of the specification within the root page that don't exist yet.
+//
+// Pages and databases are identified by their title and parent, entries by their title.
+// Existing objects are not changed, except that missing properties are added to existing databases.
+func (p *Provisioner) Provision(ctx context.Context, root notion.Id, spec Spec) (*Result, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &run{
+		cli:      p.cli,
+		root:     notion.UUID(root),
+		res:      &Result{IDs: IDs{}},
+		schemas:  map[string]notion.PropertyMetaMap{},
+		existing: map[string]bool{},
+	}
+
+	pages, err := spec.sortedPages()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ps := range pages {
+		if err := r.page(ctx, ps); err != nil {
+			return r.res, fmt.Errorf("page %q: %w", ps.Key, err)
+		}
+	}
+
+	// databases are created before their relations so they can relate to each other in any order
+	for _, ds := range spec.Databases {
+		if err := r.database(ctx, ds); err != nil {
+			return r.res, fmt.Errorf("database %q: %w", ds.Key, err)
+		}
+	}
+
+	for _, ds := range spec.Databases {
+		if err := r.properties(ctx, ds); err != nil {
+			return r.res, fmt.Errorf("properties of database %q: %w", ds.Key, err)
+		}
+	}
+
+	for _, ds := range spec.Databases {
+		if err := r.entries(ctx, ds); err != nil {
+			return r.res, fmt.Errorf("entries of database %q: %w", ds.Key, err)
+		}
+	}
+
+	if err := r.resolveDeferred(ctx); err != nil {
+		return r.res, err
+	}
+
+	return r.res, nil
+}
+
+// run holds the state of provisioning a single specification.
+type run struct {
+	cli  Client
+	root notion.UUID
+	res  *Result
+
+	// schemas are the live properties of the databases by key
+	schemas map[string]notion.PropertyMetaMap
+	// existing is true for databases that existed before
+	existing map[string]bool
+	// deferred are relations of new entries that could not be resolved when the entry was created
+	deferred []deferredRelation
+}
+
+type deferredRelation struct {
+	entry    string
+	id       notion.UUID
+	property string
+	target   notion.UUID
+	refs     notion.References
+}
+
+func (r *run) parent(key string) notion.UUID {
+	if key == "" {
+		return r.root
+	}
+
+	return r.res.IDs[key]
+}
+
+func (r *run) created(key string, id notion.UUID) {
+	r.res.IDs[key] = id
+	r.res.Created = append(r.res.Created, key)
+}
+
+func (r *run) page(ctx context.Context, ps PageSpec) error {
+	parent := r.parent(ps.Parent)
+
+	pages, err := r.cli.GetNotionPagesByTitle(ctx, ps.Title)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pages {
+		if !p.Archived && p.Title() == ps.Title && isChildOf(p.Parent, parent) {
+			r.res.IDs[ps.Key] = p.Id
+			return nil
+		}
+	}
+
+	p := notion.NewPage(ps.Title, &notion.Parent{Type: notion.ParentTypePageId, PageId: &parent})
+	p.Icon = emojiIcon(ps.Icon)
+
+	created, err := r.cli.CreateNotionPage(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	r.created(ps.Key, created.Id)
+
+	return nil
+}
+
+func (r *run) database(ctx context.Context, ds DatabaseSpec) error {
+	parent := r.parent(ds.Parent)
+
+	dbs, err := r.cli.GetNotionDatabasesByTitle(ctx, ds.Title)
+	if err != nil {
+		return err
+	}
+
+	for _, db := range dbs {
+		if !db.Archived && db.Title.Content() == ds.Title && isChildOf(db.Parent, parent) {
+			r.res.IDs[ds.Key] = db.Id
+			r.schemas[ds.Key] = db.Properties
+			r.existing[ds.Key] = true
+
+			return nil
+		}
+	}
+
+	props := notion.PropertyMetaMap{}
+
+	for name, prop := range ds.Properties {
+		if prop.Type == notion.PropertyTypeRelation {
+			// added once all databases exist
+			continue
+		}
+
+		withConf, err := prop.WithConfig()
+		if err != nil {
+			return fmt.Errorf("property %q: %w", name, err)
+		}
+
+		props[name] = withConf
+	}
+
+	created, err := r.cli.CreateNotionDatabase(ctx, notion.Database{
+		Parent:     &notion.Parent{Type: notion.ParentTypePageId, PageId: &parent},
+		Title:      notion.NewRichTexts(ds.Title),
+		Icon:       emojiIcon(ds.Icon),
+		IsInline:   ds.Inline,
+		Properties: props,
+	})
+	if err != nil {
+		return err
+	}
+
+	r.created(ds.Key, created.Id)
+	r.schemas[ds.Key] = created.Properties
+
+	return nil
+}
+
+// properties adds the declared properties that the database is missing, including all relations of new databases.
+func (r *run) properties(ctx context.Context, ds DatabaseSpec) error {
+	schema := r.schemas[ds.Key]
+	missing := map[string]*notion.PropertyMeta{}
+
+	for _, name := range sortedNames(ds.Properties) {
+		if _, ok := schema[name]; ok {
+			continue
+		}
+
+		prop := ds.Properties[name]
+		if prop.Type == notion.PropertyTypeRelation {
+			prop.Relation = r.relation(prop.GetRelation())
+		}
+
+		withConf, err := prop.WithConfig()
+		if err != nil {
+			return fmt.Errorf("property %q: %w", name, err)
+		}
+
+		missing[name] = &withConf
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	db, err := r.cli.UpdateNotionDatabaseProperties(ctx, notion.Id(r.res.IDs[ds.Key]), missing)
+	if err != nil {
+		return err
+	}
+
+	r.schemas[ds.Key] = db.Properties
+
+	return nil
+}
+
+// relation returns the relation with the key of the related database resolved.
+func (r *run) relation(conf notion.RelationConfiguration) *notion.RelationConfiguration {
+	if id, ok := r.res.IDs[string(conf.DatabaseId)]; ok {
+		conf.DatabaseId = id
+	}
+
+	if conf.Type == "" {
+		conf.Type = notion.RelationConfigurationTypeSingleProperty
+	}
+
+	if conf.Type == notion.RelationConfigurationTypeSingleProperty && conf.SingleProperty == nil {
+		conf.SingleProperty = &map[string]interface{}{}
+	}
+
+	return &conf
+}
+
+func (r *run) entries(ctx context.Context, ds DatabaseSpec) error {
+	if len(ds.Entries) == 0 {
+		return nil
+	}
+
+	schema := r.schemas[ds.Key]
+
+	titleProp := ""
+
+	for name, prop := range schema {
+		if prop.Type == notion.PropertyTypeTitle {
+			titleProp = name
+		}
+	}
+
+	existing := map[string]notion.UUID{}
+
+	if r.existing[ds.Key] {
+		entries, err := r.cli.GetAllDatabaseEntries(ctx, notion.Id(r.res.IDs[ds.Key]))
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			existing[e.Title()] = e.Id
+		}
+	}
+
+	dbID := r.res.IDs[ds.Key]
+
+	for i, entry := range ds.Entries {
+		title, ok := entry[titleProp]
+		if !ok || title == nil {
+			return fmt.Errorf("entry %d has no value for the title property %q", i, titleProp)
+		}
+
+		key := ds.Key + "/" + fmt.Sprint(title)
+
+		if id, ok := existing[fmt.Sprint(title)]; ok {
+			r.res.IDs[key] = id
+			continue
+		}
+
+		props := notion.PropertyValueMap{}
+
+		var deferred []deferredRelation
+
+		for _, name := range sortedKeys(entry) {
+			prop, ok := schema[name]
+			if !ok {
+				return fmt.Errorf("entry %q: unknown property %q", key, name)
+			}
+
+			val := entry[name]
+
+			if prop.Type == notion.PropertyTypeRelation && val != nil {
+				refs, err := notion.NewPropertyValue(prop.Type, val)
+				if err != nil {
+					return fmt.Errorf("entry %q: property %q: %w", key, name, err)
+				}
+
+				ids, unresolved := r.resolve(refs.GetRelation(), prop.GetRelation().DatabaseId)
+				if len(unresolved) > 0 {
+					deferred = append(deferred, deferredRelation{
+						entry: key, property: name, target: prop.GetRelation().DatabaseId, refs: refs.GetRelation(),
+					})
+				}
+
+				val = ids
+			}
+
+			pv, err := notion.NewPropertyValue(prop.Type, val)
+			if err != nil {
+				return fmt.Errorf("entry %q: property %q: %w", key, name, err)
+			}
+
+			props[name] = pv
+		}
+
+		created, err := r.cli.CreateNotionPage(ctx, notion.Page{
+			Parent:     &notion.Parent{Type: notion.ParentTypeDatabaseId, DatabaseId: &dbID},
+			Properties: props,
+		})
+		if err != nil {
+			return fmt.Errorf("entry %q: %w", key, err)
+		}
+
+		r.created(key, created.Id)
+
+		for _, d := range deferred {
+			d.id = created.Id
+			r.deferred = append(r.deferred, d)
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the IDs of the references, which are keys, titles of entries in the related database or IDs.
+// References that can't be resolved yet are returned as unresolved.
+func (r *run) resolve(refs notion.References, target notion.UUID) (ids []string, unresolved []string) {
+	targetKey := ""
+
+	for key, id := range r.res.IDs {
+		if id == target && !strings.Contains(key, "/") {
+			targetKey = key
+		}
+	}
+
+	ids = []string{}
+
+	for _, ref := range refs {
+		s := string(ref.Id)
+
+		if id, ok := r.res.IDs[s]; ok {
+			ids = append(ids, string(id))
+		} else if id, ok := r.res.IDs[targetKey+"/"+s]; ok && targetKey != "" {
+			ids = append(ids, string(id))
+		} else if notion.IsUUID(s) {
+			ids = append(ids, s)
+		} else {
+			unresolved = append(unresolved, s)
+		}
+	}
+
+	return ids, unresolved
+}
+
+// resolveDeferred sets the relations of new entries that refer to entries created after them.
+func (r *run) resolveDeferred(ctx context.Context) error {
+	for _, d := range r.deferred {
+		ids, unresolved := r.resolve(d.refs, d.target)
+		if len(unresolved) > 0 {
+			return fmt.Errorf("entry %q: property %q: %w: cannot resolve %s",
+				d.entry, d.property, ErrInvalidSpec, strings.Join(unresolved, ", "))
+		}
+
+		refs := make(notion.References, len(ids))
+		for i, id := range ids {
+			refs[i] = notion.Reference{Id: notion.UUID(id)}
+		}
+
+		if _, err := r.cli.UpdateNotionPage(ctx, notion.Page{
+			Id: d.id,
+			Properties: notion.PropertyValueMap{
+				d.property: {Type: notion.PropertyTypeRelation, Relation: &refs},
+			},
+		}); err != nil {
+			return fmt.Errorf("entry %q: %w", d.entry, err)
+		}
+	}
+
+	return nil
+}
+
+func isChildOf(p *notion.Parent, parent notion.UUID) bool {
+	return p != nil && p.PageId != nil && p.PageId.Equal(parent)
+}
+
+func emojiIcon(emoji string) *notion.Icon {
+	if emoji == "" {
+		return nil
+	}
+
+	return &notion.Icon{Type: notion.IconTypeEmoji, Emoji: &emoji}
+}
+
+func sortedNames(props notion.PropertyMetaMap) []string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
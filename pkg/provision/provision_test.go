@@ -0,0 +1,206 @@
+package provision_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/faetools/go-notion/pkg/provision"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const root notion.Id = "c0ffee00-0000-4000-8000-000000000000"
+
+const spec = `
+pages:
+  - key: project
+    title: Client Project
+    icon: 🚀
+databases:
+  - key: tasks
+    title: Tasks
+    parent: project
+    properties:
+      Name: {type: title}
+      Done: {type: checkbox}
+      Due: {type: date}
+      Milestone:
+        type: relation
+        relation: {database_id: milestones}
+    entries:
+      - {Name: Kick-off, Done: "yes", Due: 2024-01-31, Milestone: [Launch]}
+      - {Name: Planning, Milestone: Launch}
+  - key: milestones
+    title: Milestones
+    parent: project
+    properties:
+      Name: {type: title}
+      Tags: {type: multi_select, multi_select: {options: [{name: a}]}}
+    entries:
+      - {Name: Launch, Tags: "a, b"}
+`
+
+// memClient keeps all objects in memory.
+type memClient struct {
+	pages   map[notion.UUID]*notion.Page
+	dbs     map[notion.UUID]*notion.Database
+	updates []notion.Page
+	n       int
+}
+
+func newMemClient() *memClient {
+	return &memClient{pages: map[notion.UUID]*notion.Page{}, dbs: map[notion.UUID]*notion.Database{}}
+}
+
+func (c *memClient) newID() notion.UUID {
+	c.n++
+	return notion.UUID(fmt.Sprintf("00000000-0000-4000-8000-%012d", c.n))
+}
+
+func (c *memClient) GetNotionPagesByTitle(_ context.Context, title string) (notion.Pages, error) {
+	pages := notion.Pages{}
+
+	for _, p := range c.pages {
+		if p.Title() == title {
+			pages = append(pages, *p)
+		}
+	}
+
+	return pages, nil
+}
+
+func (c *memClient) GetNotionDatabasesByTitle(_ context.Context, title string) (notion.Databases, error) {
+	dbs := notion.Databases{}
+
+	for _, db := range c.dbs {
+		if db.Title.Content() == title {
+			dbs = append(dbs, *db)
+		}
+	}
+
+	return dbs, nil
+}
+
+func (c *memClient) GetAllDatabaseEntries(_ context.Context, id notion.Id) (notion.Pages, error) {
+	pages := notion.Pages{}
+
+	for _, p := range c.pages {
+		if p.Parent.DatabaseId != nil && *p.Parent.DatabaseId == notion.UUID(id) {
+			pages = append(pages, *p)
+		}
+	}
+
+	return pages, nil
+}
+
+func (c *memClient) CreateNotionPage(_ context.Context, p notion.Page) (*notion.Page, error) {
+	p.Id = c.newID()
+	c.pages[p.Id] = &p
+
+	return &p, nil
+}
+
+func (c *memClient) UpdateNotionPage(_ context.Context, p notion.Page) (*notion.Page, error) {
+	c.updates = append(c.updates, p)
+
+	for name, prop := range p.Properties {
+		c.pages[p.Id].Properties[name] = prop
+	}
+
+	return c.pages[p.Id], nil
+}
+
+func (c *memClient) CreateNotionDatabase(_ context.Context, db notion.Database) (*notion.Database, error) {
+	db.Id = c.newID()
+	c.dbs[db.Id] = &db
+
+	return &db, nil
+}
+
+func (c *memClient) UpdateNotionDatabaseProperties(
+	_ context.Context, id notion.Id, props map[string]*notion.PropertyMeta,
+) (*notion.Database, error) {
+	db := c.dbs[notion.UUID(id)]
+	for name, prop := range props {
+		db.Properties[name] = *prop
+	}
+
+	return db, nil
+}
+
+func TestProvision(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	s, err := provision.Parse([]byte(spec))
+	require.NoError(t, err)
+
+	cli := newMemClient()
+
+	res, err := provision.New(cli).Provision(ctx, root, *s)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"project", "tasks", "milestones",
+		"tasks/Kick-off", "tasks/Planning", "milestones/Launch",
+	}, res.Created)
+	assert.Len(t, res.IDs, 6)
+
+	project := cli.pages[res.IDs["project"]]
+	assert.Equal(t, "🚀 Client Project", project.TitleWithEmoji())
+	assert.Equal(t, notion.UUID(root), *project.Parent.PageId)
+
+	tasks := cli.dbs[res.IDs["tasks"]]
+	assert.Equal(t, res.IDs["project"], *tasks.Parent.PageId)
+	assert.NotNil(t, tasks.Properties["Name"].Title)
+	assert.NotNil(t, tasks.Properties["Done"].Checkbox)
+
+	// relations refer to the created database
+	assert.Equal(t, res.IDs["milestones"], tasks.Properties["Milestone"].GetRelation().DatabaseId)
+
+	kickoff := cli.pages[res.IDs["tasks/Kick-off"]]
+	assert.True(t, kickoff.Properties["Done"].GetCheckbox())
+	assert.Equal(t, "2024-01-31", kickoff.Properties["Due"].GetDate().String())
+
+	launch := cli.pages[res.IDs["milestones/Launch"]]
+	assert.Equal(t, notion.SelectValues{{Name: "a"}, {Name: "b"}}, launch.Properties["Tags"].GetMultiSelect())
+
+	// the milestone is created after the tasks, so their relations are set afterwards
+	require.Len(t, cli.updates, 2)
+	assert.Equal(t, notion.References{{Id: res.IDs["milestones/Launch"]}},
+		kickoff.Properties["Milestone"].GetRelation())
+
+	// provisioning again does not create anything
+	again, err := provision.New(cli).Provision(ctx, root, *s)
+	require.NoError(t, err)
+	assert.Empty(t, again.Created)
+	assert.Equal(t, res.IDs, again.IDs)
+	assert.Len(t, cli.updates, 2)
+
+	// the root may be given without dashes
+	again, err = provision.New(cli).Provision(ctx, "c0ffee00000040008000000000000000", *s)
+	require.NoError(t, err)
+	assert.Empty(t, again.Created)
+	assert.Equal(t, res.IDs, again.IDs)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct{ spec, err string }{
+		{`pages: [{title: A}, {title: A}]`, `duplicate key "A"`},
+		{`pages: [{title: A, parent: B}]`, `parent "B" of page "A" is not a declared page`},
+		{`pages: [{title: A, parent: B}, {title: B, parent: A}]`, `page "A" is its own ancestor`},
+		{`databases: [{title: A, properties: {Name: {type: rich_text}}}]`, `database "A" needs exactly one title property`},
+	} {
+		_, err := provision.Parse([]byte(tc.spec))
+		assert.ErrorIs(t, err, provision.ErrInvalidSpec)
+		assert.ErrorContains(t, err, tc.err)
+	}
+
+	_, err := provision.Parse([]byte(`pages: [{title: A, colour: red}]`))
+	assert.ErrorContains(t, err, `unknown field "colour"`)
+}
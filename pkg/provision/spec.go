@@ -0,0 +1,206 @@
+// Package provision creates pages, databases and their initial entries from a declarative specification.
+//
+// Objects in the specification refer to each other by local keys, e.g. a database lives in a declared page
+// and relates to another declared database. The provisioner creates everything in dependency order,
+// resolves the keys to the IDs of the created objects and reuses objects that already exist,
+// so that provisioning the same specification twice does not create duplicates.
+package provision
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/goccy/go-yaml"
+)
+
+// ErrInvalidSpec is returned if the specification is inconsistent.
+var ErrInvalidSpec = errors.New("invalid specification")
+
+// Spec describes the pages and databases to provision.
+type Spec struct {
+	Pages     []PageSpec     `json:"pages,omitempty"`
+	Databases []DatabaseSpec `json:"databases,omitempty"`
+}
+
+// PageSpec describes a page.
+type PageSpec struct {
+	// Key is the local name of the page other objects refer to. Defaults to the title.
+	Key string `json:"key,omitempty"`
+	// Title is the title of the page.
+	Title string `json:"title"`
+	// Icon is an optional emoji.
+	Icon string `json:"icon,omitempty"`
+	// Parent is the key of the parent page. If empty, the page is created in the root page.
+	Parent string `json:"parent,omitempty"`
+}
+
+// DatabaseSpec describes a database and its initial entries.
+type DatabaseSpec struct {
+	// Key is the local name of the database other objects refer to. Defaults to the title.
+	Key string `json:"key,omitempty"`
+	// Title is the title of the database.
+	Title string `json:"title"`
+	// Icon is an optional emoji.
+	Icon string `json:"icon,omitempty"`
+	// Parent is the key of the parent page. If empty, the database is created in the root page.
+	Parent string `json:"parent,omitempty"`
+	// Inline defines whether the database is shown inline in its parent page.
+	Inline bool `json:"is_inline,omitempty"`
+
+	// Properties is the schema of the database.
+	// The database of a relation can be given as the key of a declared database.
+	Properties notion.PropertyMetaMap `json:"properties"`
+
+	// Entries are the initial entries of the database, mapping property names to plain values.
+	// Relations can refer to keys of declared objects or to titles of entries in the related database.
+	Entries []map[string]any `json:"entries,omitempty"`
+}
+
+// Load reads the specification from a YAML or JSON file.
+func Load(path string) (*Spec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return spec, nil
+}
+
+// Parse parses the specification from YAML or JSON and validates it.
+func Parse(b []byte) (*Spec, error) {
+	// convert to JSON to make use of the JSON tags and unmarshalers of the notion types
+	b, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+
+	spec := &Spec{}
+	if err := dec.Decode(spec); err != nil {
+		return nil, err
+	}
+
+	return spec, spec.Validate()
+}
+
+// Validate sets the default keys and makes sure that all references between the objects are valid.
+func (s *Spec) Validate() error {
+	keys := map[string]bool{}
+	pages := map[string]bool{}
+
+	addKey := func(key *string, title string) error {
+		if title == "" {
+			return fmt.Errorf("%w: missing title", ErrInvalidSpec)
+		}
+
+		if *key == "" {
+			*key = title
+		}
+
+		if keys[*key] {
+			return fmt.Errorf("%w: duplicate key %q", ErrInvalidSpec, *key)
+		}
+
+		keys[*key] = true
+
+		return nil
+	}
+
+	for i := range s.Pages {
+		if err := addKey(&s.Pages[i].Key, s.Pages[i].Title); err != nil {
+			return err
+		}
+
+		pages[s.Pages[i].Key] = true
+	}
+
+	for i := range s.Databases {
+		if err := addKey(&s.Databases[i].Key, s.Databases[i].Title); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range s.Pages {
+		if p.Parent != "" && !pages[p.Parent] {
+			return fmt.Errorf("%w: parent %q of page %q is not a declared page", ErrInvalidSpec, p.Parent, p.Key)
+		}
+	}
+
+	for _, db := range s.Databases {
+		if db.Parent != "" && !pages[db.Parent] {
+			return fmt.Errorf("%w: parent %q of database %q is not a declared page", ErrInvalidSpec, db.Parent, db.Key)
+		}
+
+		titles := 0
+
+		for _, prop := range db.Properties {
+			if prop.Type == notion.PropertyTypeTitle {
+				titles++
+			}
+		}
+
+		if titles != 1 {
+			return fmt.Errorf("%w: database %q needs exactly one title property", ErrInvalidSpec, db.Key)
+		}
+	}
+
+	_, err := s.sortedPages()
+
+	return err
+}
+
+// sortedPages returns the pages so that every page comes after its parent.
+func (s *Spec) sortedPages() ([]PageSpec, error) {
+	byKey := make(map[string]PageSpec, len(s.Pages))
+	for _, p := range s.Pages {
+		byKey[p.Key] = p
+	}
+
+	sorted := make([]PageSpec, 0, len(s.Pages))
+	done := map[string]bool{}
+	visiting := map[string]bool{}
+
+	var visit func(p PageSpec) error
+
+	visit = func(p PageSpec) error {
+		if done[p.Key] {
+			return nil
+		}
+
+		if visiting[p.Key] {
+			return fmt.Errorf("%w: page %q is its own ancestor", ErrInvalidSpec, p.Key)
+		}
+
+		visiting[p.Key] = true
+
+		if p.Parent != "" {
+			if err := visit(byKey[p.Parent]); err != nil {
+				return err
+			}
+		}
+
+		done[p.Key] = true
+		sorted = append(sorted, p)
+
+		return nil
+	}
+
+	for _, p := range s.Pages {
+		if err := visit(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
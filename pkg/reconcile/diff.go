@@ -0,0 +1,290 @@
+package reconcile
+
+import (
+	"strings"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+const defaultMinSimilarity = 0.5
+
+// Diff computes the operations needed to turn the current tree into the desired tree.
+//
+// Blocks are matched by type and similarity of their content, keeping their order.
+// Matched blocks are updated if their content differs, blocks without match are archived
+// and new blocks are inserted after the preceding block.
+// Child pages, child databases and unsupported blocks are never archived or updated.
+func Diff(current, desired *notion.BlockTree, opts Options) Plan {
+	d := &differ{minSimilarity: opts.MinSimilarity}
+	if d.minSimilarity <= 0 {
+		d.minSimilarity = defaultMinSimilarity
+	}
+
+	d.diffChildren(current.ID, current.Children, desired.Children)
+
+	// archive last so that blocks to be archived can still be used to position new blocks
+	return append(d.plan, d.archives...)
+}
+
+type differ struct {
+	minSimilarity float64
+
+	plan     Plan
+	archives Plan
+}
+
+type pair struct{ cur, want int }
+
+func (d *differ) diffChildren(parent notion.UUID, cur, want []*notion.BlockNode) {
+	pairs := d.align(cur, want)
+
+	// The API can only insert blocks after another block.
+	// If new blocks need to go before the first block, we give up on keeping that block
+	// so that it can serve as the anchor for the new blocks before it is archived.
+	if len(pairs) > 0 && pairs[0].cur == 0 && pairs[0].want > 0 {
+		pairs = pairs[1:]
+	}
+
+	matched := make([]bool, len(cur))
+	for _, p := range pairs {
+		matched[p.cur] = true
+	}
+
+	wantIdx := 0
+
+	for k := 0; k <= len(pairs); k++ {
+		// the new blocks before the next matched pair
+		end := len(want)
+		if k < len(pairs) {
+			end = pairs[k].want
+		}
+
+		if wantIdx < end {
+			d.insert(parent, anchor(cur, pairs, k), want[wantIdx:end])
+		}
+
+		if k == len(pairs) {
+			break
+		}
+
+		c, w := cur[pairs[k].cur], want[pairs[k].want]
+		d.diffNode(c, w)
+
+		wantIdx = end + 1
+	}
+
+	for i, c := range cur {
+		if !matched[i] && !untouchable(c.Type) {
+			d.archives = append(d.archives, Operation{Kind: OperationKindArchive, Block: c.Block})
+		}
+	}
+}
+
+// anchor returns the ID of the block after which new blocks are inserted
+// that come before the k-th pair.
+func anchor(cur []*notion.BlockNode, pairs []pair, k int) *notion.UUID {
+	switch {
+	case k > 0:
+		return &cur[pairs[k-1].cur].Id
+	case len(pairs) > 0:
+		// any block before the first match, this block is going to be archived
+		return &cur[pairs[0].cur-1].Id
+	case len(cur) > 0:
+		// nothing is matched, all current blocks will be archived
+		return &cur[len(cur)-1].Id
+	default:
+		return nil
+	}
+}
+
+func (d *differ) insert(parent notion.UUID, after *notion.UUID, nodes []*notion.BlockNode) {
+	insertable := make([]*notion.BlockNode, 0, len(nodes))
+
+	for _, n := range nodes {
+		// these can't be created via the blocks endpoint
+		if untouchable(n.Type) {
+			continue
+		}
+
+		insertable = append(insertable, n)
+	}
+
+	if len(insertable) == 0 {
+		return
+	}
+
+	d.plan = append(d.plan, Operation{
+		Kind:   OperationKindInsert,
+		Parent: parent,
+		After:  after,
+		Nodes:  insertable,
+	})
+}
+
+func (d *differ) diffNode(cur, want *notion.BlockNode) {
+	if untouchable(cur.Type) {
+		return
+	}
+
+	if !cur.EqualContent(want.Block) {
+		b := want.Block
+		b.Id = cur.Id
+
+		d.plan = append(d.plan, Operation{Kind: OperationKindUpdate, Block: b})
+	}
+
+	d.diffChildren(cur.Id, cur.Children, childrenOf(want))
+}
+
+// align returns the pairs of matching blocks, maximising the number and similarity of matches.
+func (d *differ) align(cur, want []*notion.BlockNode) []pair {
+	n, m := len(cur), len(want)
+
+	sim := make([][]float64, n)
+	score := make([][]float64, n+1)
+	score[0] = make([]float64, m+1)
+
+	for i := 0; i < n; i++ {
+		sim[i] = make([]float64, m)
+		score[i+1] = make([]float64, m+1)
+
+		for j := 0; j < m; j++ {
+			if s := similarity(cur[i], want[j]); s >= d.minSimilarity {
+				sim[i][j] = s
+			}
+		}
+	}
+
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			score[i][j] = max(score[i-1][j], score[i][j-1])
+
+			if s := sim[i-1][j-1]; s > 0 {
+				// each match counts more than any difference in similarity
+				score[i][j] = max(score[i][j], score[i-1][j-1]+1+s)
+			}
+		}
+	}
+
+	pairs := []pair{}
+
+	for i, j := n, m; i > 0 && j > 0; {
+		switch {
+		case score[i][j] == score[i-1][j]:
+			i--
+		case score[i][j] == score[i][j-1]:
+			j--
+		default:
+			pairs = append(pairs, pair{cur: i - 1, want: j - 1})
+			i--
+			j--
+		}
+	}
+
+	// reverse
+	for l, r := 0, len(pairs)-1; l < r; l, r = l+1, r-1 {
+		pairs[l], pairs[r] = pairs[r], pairs[l]
+	}
+
+	return pairs
+}
+
+// untouchable returns true for blocks that can neither be created, updated nor safely archived.
+func untouchable(tp notion.BlockType) bool {
+	switch tp {
+	case notion.BlockTypeChildPage, notion.BlockTypeChildDatabase, notion.BlockTypeUnsupported:
+		return true
+	default:
+		return false
+	}
+}
+
+// similarity returns how similar two blocks are, from 0 (not at all) to 1 (same content).
+func similarity(a, b *notion.BlockNode) float64 {
+	if a.Type != b.Type || a.Type == notion.BlockTypeUnsupported {
+		return 0
+	}
+
+	switch a.Type {
+	case notion.BlockTypeChildPage, notion.BlockTypeChildDatabase:
+		if a.Title() == b.Title() {
+			return 1
+		}
+
+		return 0
+	case notion.BlockTypeTable:
+		// the width of a table can't be changed
+		if a.Table.TableWidth != b.Table.TableWidth {
+			return 0
+		}
+	}
+
+	if a.EqualContent(b.Block) {
+		return 1
+	}
+
+	return textSimilarity(a.Content(), b.Content())
+}
+
+// textSimilarity returns the Jaccard index of the words of both texts.
+func textSimilarity(a, b string) float64 {
+	wordsA, wordsB := words(a), words(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	intersection := 0
+
+	for w := range wordsA {
+		if wordsB[w] {
+			intersection++
+		}
+	}
+
+	return float64(intersection) / float64(len(wordsA)+len(wordsB)-intersection)
+}
+
+func words(s string) map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(strings.ToLower(s)) {
+		m[w] = true
+	}
+
+	return m
+}
+
+// childrenOf returns the children of the node.
+// Blocks created with notion.NewTable or notion.NewColumnList carry their children in their content.
+func childrenOf(n *notion.BlockNode) []*notion.BlockNode {
+	if len(n.Children) > 0 {
+		return n.Children
+	}
+
+	var inline notion.Blocks
+
+	switch n.Type {
+	case notion.BlockTypeTable:
+		inline = n.Table.Children
+	case notion.BlockTypeColumnList:
+		inline = inlineChildren(n.ColumnList)
+	case notion.BlockTypeColumn:
+		inline = inlineChildren(n.Column)
+	}
+
+	nodes := make([]*notion.BlockNode, len(inline))
+	for i, b := range inline {
+		nodes[i] = &notion.BlockNode{Block: b}
+	}
+
+	return nodes
+}
+
+func inlineChildren(content *map[string]interface{}) notion.Blocks {
+	if content == nil {
+		return nil
+	}
+
+	children, _ := (*content)["children"].(notion.Blocks)
+
+	return children
+}
@@ -0,0 +1,116 @@
+// Package reconcile keeps the blocks of a page in sync with a desired tree of blocks
+// while preserving the IDs of blocks that did not change.
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// OperationKind defines the kind of operation.
+type OperationKind string
+
+// Defines values for OperationKind.
+const (
+	OperationKindUpdate  OperationKind = "update"
+	OperationKindInsert  OperationKind = "insert"
+	OperationKindArchive OperationKind = "archive"
+)
+
+// Operation is a single change to the blocks of a page.
+type Operation struct {
+	Kind OperationKind
+
+	// Block is the new content of the block to be updated
+	// or the current content of the block to be archived.
+	Block notion.Block
+
+	// Parent is the page or block the new nodes are inserted into.
+	Parent notion.UUID
+	// After is the block after which the new nodes are inserted.
+	// If nil, the nodes are appended at the end.
+	After *notion.UUID
+	// Nodes are the new blocks (including their children) to be inserted.
+	Nodes []*notion.BlockNode
+}
+
+// Plan is the list of operations in the order in which they are applied.
+type Plan []Operation
+
+// String returns a human readable description of the operation.
+func (op Operation) String() string {
+	switch op.Kind {
+	case OperationKindUpdate, OperationKindArchive:
+		return fmt.Sprintf("%-7s %s %s %q", op.Kind, op.Block.Id, op.Block.Type, summary(op.Block))
+	case OperationKindInsert:
+		pos := "at the end"
+		if op.After != nil {
+			pos = "after " + string(*op.After)
+		}
+
+		lines := make([]string, len(op.Nodes))
+		for i, n := range op.Nodes {
+			lines[i] = fmt.Sprintf("\n  + %s %q (%d blocks)", n.Type, summary(n.Block), countNodes(n))
+		}
+
+		return fmt.Sprintf("%-7s into %s %s:%s", op.Kind, op.Parent, pos, strings.Join(lines, ""))
+	default:
+		return fmt.Sprintf("unknown operation %q", op.Kind)
+	}
+}
+
+// String returns a human readable description of the plan.
+func (p Plan) String() string {
+	if len(p) == 0 {
+		return "no changes"
+	}
+
+	lines := make([]string, len(p))
+	for i, op := range p {
+		lines[i] = op.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Print writes the plan to the writer.
+func (p Plan) Print(w io.Writer) error {
+	_, err := fmt.Fprintln(w, p.String())
+	return err
+}
+
+// Count returns the number of operations of the given kind.
+func (p Plan) Count(kind OperationKind) int {
+	count := 0
+
+	for _, op := range p {
+		if op.Kind == kind {
+			count++
+		}
+	}
+
+	return count
+}
+
+const maxSummaryLen = 40
+
+func summary(b notion.Block) string {
+	s := b.Content()
+	if len([]rune(s)) <= maxSummaryLen {
+		return s
+	}
+
+	return string([]rune(s)[:maxSummaryLen]) + "…"
+}
+
+func countNodes(n *notion.BlockNode) int {
+	count := 1
+	for _, child := range childrenOf(n) {
+		count += countNodes(child)
+	}
+
+	return count
+}
@@ -0,0 +1,185 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+var _ Client = (*notion.Client)(nil)
+
+// Client is any client that can read and change blocks.
+type Client interface {
+	// GetAllBlocks returns all blocks of a given page or block.
+	GetAllBlocks(ctx context.Context, id notion.Id) (notion.Blocks, error)
+	// AppendNotionBlocks appends blocks to the children of a page or block.
+	AppendNotionBlocks(ctx context.Context, id notion.Id, after *notion.UUID, blocks ...notion.Block) (
+		notion.Blocks, error)
+	// UpdateNotionBlock updates the content of a block.
+	UpdateNotionBlock(ctx context.Context, b notion.Block) (*notion.Block, error)
+	// DeleteNotionBlock archives the block.
+	DeleteNotionBlock(ctx context.Context, id notion.Id) (*notion.Block, error)
+}
+
+// Options define how the reconciler behaves.
+type Options struct {
+	// MinSimilarity is the minimum similarity (between 0 and 1) of the content of two blocks
+	// of the same type to be considered the same block. Defaults to 0.5.
+	MinSimilarity float64
+
+	// DryRun only prints the planned operations instead of applying them.
+	DryRun bool
+
+	// Output is where the plan is printed in a dry run. Defaults to os.Stdout.
+	Output io.Writer
+
+	// Concurrency is the maximum number of requests made in parallel to fetch the current blocks.
+	Concurrency int
+}
+
+// Reconciler syncs a desired tree of blocks onto an existing page or block.
+type Reconciler struct {
+	cli  Client
+	opts Options
+}
+
+// New returns a new reconciler.
+func New(cli Client, opts Options) *Reconciler {
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	return &Reconciler{cli: cli, opts: opts}
+}
+
+// Plan fetches the current blocks of the page or block and computes the operations
+// needed to turn them into the desired blocks.
+func (r *Reconciler) Plan(ctx context.Context, id notion.Id, desired ...*notion.BlockNode) (Plan, error) {
+	current, err := notion.NewBlockTreeBuilder(r.cli.GetAllBlocks, notion.BlockTreeOptions{
+		Concurrency: r.opts.Concurrency,
+	}).Build(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting current blocks of %s: %w", id, err)
+	}
+
+	return Diff(current, notion.NewBlockTree(notion.UUID(id), desired...), r.opts), nil
+}
+
+// Sync makes sure the page or block has the desired blocks as children.
+// In a dry run, the plan is printed instead of applied.
+func (r *Reconciler) Sync(ctx context.Context, id notion.Id, desired ...*notion.BlockNode) (Plan, error) {
+	plan, err := r.Plan(ctx, id, desired...)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.opts.DryRun {
+		return plan, plan.Print(r.opts.Output)
+	}
+
+	return plan, r.Apply(ctx, plan)
+}
+
+// Apply applies the operations of the plan in order.
+func (r *Reconciler) Apply(ctx context.Context, plan Plan) error {
+	for i, op := range plan {
+		if err := r.apply(ctx, op); err != nil {
+			return fmt.Errorf("operation %d (%s): %w", i, op.Kind, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Reconciler) apply(ctx context.Context, op Operation) error {
+	switch op.Kind {
+	case OperationKindUpdate:
+		_, err := r.cli.UpdateNotionBlock(ctx, op.Block)
+		return err
+	case OperationKindArchive:
+		_, err := r.cli.DeleteNotionBlock(ctx, notion.Id(op.Block.Id))
+		return err
+	case OperationKindInsert:
+		return r.insert(ctx, op.Parent, op.After, op.Nodes)
+	default:
+		return fmt.Errorf("unknown operation %q", op.Kind)
+	}
+}
+
+// insert appends the nodes and afterwards their children.
+func (r *Reconciler) insert(
+	ctx context.Context, parent notion.UUID, after *notion.UUID, nodes []*notion.BlockNode,
+) error {
+	for start := 0; start < len(nodes); start += notion.MaxBlockChildren {
+		chunk := nodes[start:min(start+notion.MaxBlockChildren, len(nodes))]
+
+		blocks := make(notion.Blocks, len(chunk))
+		for i, n := range chunk {
+			blocks[i] = newBlock(n)
+		}
+
+		created, err := r.cli.AppendNotionBlocks(ctx, notion.Id(parent), after, blocks...)
+		if err != nil {
+			return err
+		}
+
+		if len(created) != len(chunk) {
+			return fmt.Errorf("appended %d blocks to %s but got %d back", len(chunk), parent, len(created))
+		}
+
+		for i, n := range chunk {
+			if hasInlineChildren(n.Type) || len(n.Children) == 0 {
+				continue
+			}
+
+			if err := r.insert(ctx, created[i].Id, nil, n.Children); err != nil {
+				return fmt.Errorf("inserting children of %s: %w", created[i].Id, err)
+			}
+		}
+
+		after = &created[len(created)-1].Id
+	}
+
+	return nil
+}
+
+// hasInlineChildren returns true for blocks that need to be created together with their children.
+func hasInlineChildren(tp notion.BlockType) bool {
+	switch tp {
+	case notion.BlockTypeTable, notion.BlockTypeColumnList, notion.BlockTypeColumn:
+		return true
+	default:
+		return false
+	}
+}
+
+// newBlock returns the block of the node, ready to be created.
+func newBlock(n *notion.BlockNode) notion.Block {
+	b := n.Block
+	b.Id = ""
+
+	if !hasInlineChildren(b.Type) {
+		return b
+	}
+
+	children := make(notion.Blocks, 0, len(childrenOf(n)))
+	for _, child := range childrenOf(n) {
+		children = append(children, newBlock(child))
+	}
+
+	switch b.Type {
+	case notion.BlockTypeTable:
+		table := *b.Table
+		table.Children = children
+		b.Table = &table
+	case notion.BlockTypeColumnList:
+		b.ColumnList = &map[string]interface{}{"children": children}
+	case notion.BlockTypeColumn:
+		b.Column = &map[string]interface{}{"children": children}
+	}
+
+	return b
+}
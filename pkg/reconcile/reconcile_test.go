@@ -0,0 +1,238 @@
+package reconcile_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/faetools/go-notion/pkg/reconcile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pageID = "96245c8f-1784-44a4-82ad-1941127c3ec3"
+
+func withID(id notion.UUID, b notion.Block) *notion.BlockNode {
+	b.Id = id
+	return notion.NewBlockNode(b)
+}
+
+func heading(t *testing.T, txt string) notion.Block {
+	t.Helper()
+
+	h, err := notion.NewHeading(1, txt)
+	require.NoError(t, err)
+
+	return h
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	current := notion.NewBlockTree(pageID,
+		withID("h", heading(t, "Status")),
+		withID("p1", notion.NewParagraphBlock("Done: 3 tasks")),
+		withID("p2", notion.NewParagraphBlock("an old note")),
+		withID("d", notion.NewDivider()),
+	)
+
+	desired := notion.NewBlockTree(pageID,
+		notion.NewBlockNode(heading(t, "Status")),
+		notion.NewBlockNode(notion.NewParagraphBlock("Done: 4 tasks")),
+		notion.NewBlockNode(notion.NewDivider()),
+		notion.NewBlockNode(notion.NewParagraphBlock("something new")),
+	)
+
+	plan := reconcile.Diff(current, desired, reconcile.Options{})
+	require.Len(t, plan, 3)
+
+	assert.Equal(t, reconcile.OperationKindUpdate, plan[0].Kind)
+	assert.Equal(t, notion.UUID("p1"), plan[0].Block.Id)
+	assert.Equal(t, "Done: 4 tasks", plan[0].Block.Paragraph.RichText.Content())
+
+	assert.Equal(t, reconcile.OperationKindInsert, plan[1].Kind)
+	assert.Equal(t, notion.UUID("d"), *plan[1].After)
+	assert.Equal(t, notion.UUID(pageID), plan[1].Parent)
+	require.Len(t, plan[1].Nodes, 1)
+
+	assert.Equal(t, reconcile.OperationKindArchive, plan[2].Kind)
+	assert.Equal(t, notion.UUID("p2"), plan[2].Block.Id)
+
+	// nothing to do if we are in sync
+	assert.Empty(t, reconcile.Diff(current, current, reconcile.Options{}))
+}
+
+func TestDiff_InsertAtStart(t *testing.T) {
+	t.Parallel()
+
+	current := notion.NewBlockTree(pageID,
+		withID("p", notion.NewParagraphBlock("first paragraph")),
+	)
+
+	desired := notion.NewBlockTree(pageID,
+		notion.NewBlockNode(notion.NewParagraphBlock("a new beginning")),
+		notion.NewBlockNode(notion.NewParagraphBlock("first paragraph")),
+	)
+
+	plan := reconcile.Diff(current, desired, reconcile.Options{})
+	require.Len(t, plan, 2)
+
+	// the first block is used as anchor and archived afterwards
+	assert.Equal(t, reconcile.OperationKindInsert, plan[0].Kind)
+	assert.Equal(t, notion.UUID("p"), *plan[0].After)
+	assert.Len(t, plan[0].Nodes, 2)
+
+	assert.Equal(t, reconcile.OperationKindArchive, plan[1].Kind)
+	assert.Equal(t, notion.UUID("p"), plan[1].Block.Id)
+}
+
+func TestDiff_Children(t *testing.T) {
+	t.Parallel()
+
+	current := notion.NewBlockTree(pageID,
+		notion.NewBlockNode(withID("toggle", notion.NewToggle("details")).Block,
+			withID("c1", notion.NewToDo("write report", false)),
+		),
+	)
+
+	desired := notion.NewBlockTree(pageID,
+		notion.NewBlockNode(notion.NewToggle("details"),
+			notion.NewBlockNode(notion.NewToDo("write report", true)),
+			notion.NewBlockNode(notion.NewToDo("send report", false)),
+		),
+	)
+
+	plan := reconcile.Diff(current, desired, reconcile.Options{})
+	require.Len(t, plan, 2)
+
+	assert.Equal(t, reconcile.OperationKindUpdate, plan[0].Kind)
+	assert.Equal(t, notion.UUID("c1"), plan[0].Block.Id)
+	assert.True(t, plan[0].Block.ToDo.Checked)
+
+	assert.Equal(t, reconcile.OperationKindInsert, plan[1].Kind)
+	assert.Equal(t, notion.UUID("toggle"), plan[1].Parent)
+	assert.Equal(t, notion.UUID("c1"), *plan[1].After)
+}
+
+func TestDiff_FileUpload(t *testing.T) {
+	t.Parallel()
+
+	img, err := notion.NewImageExternal("https://example.com/old.png", "")
+	require.NoError(t, err)
+
+	upload := notion.FileUpload{Id: "upload"}.FileWithCaption()
+
+	current := notion.NewBlockTree(pageID, withID("img", img))
+	desired := notion.NewBlockTree(pageID,
+		notion.NewBlockNode(notion.Block{Object: "block", Type: notion.BlockTypeImage, Image: &upload}))
+
+	plan := reconcile.Diff(current, desired, reconcile.Options{})
+	require.Len(t, plan, 2)
+	assert.Equal(t, reconcile.OperationKindInsert, plan[0].Kind)
+	assert.Equal(t, reconcile.OperationKindArchive, plan[1].Kind)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, plan.Print(buf))
+	assert.Contains(t, buf.String(), "file_upload:upload")
+}
+
+func TestDiff_TableHeader(t *testing.T) {
+	t.Parallel()
+
+	rows := [][]string{{"a", "b"}, {"1", "2"}}
+
+	cur, err := notion.NewTable(rows, false)
+	require.NoError(t, err)
+
+	curRows := []*notion.BlockNode{}
+	for i, row := range cur.Table.Children {
+		curRows = append(curRows, withID(notion.UUID(fmt.Sprintf("row-%d", i)), row))
+	}
+
+	// fetched tables have their rows as children of the node
+	cur.Table.Children = nil
+
+	want, err := notion.NewTable(rows, true)
+	require.NoError(t, err)
+
+	plan := reconcile.Diff(
+		notion.NewBlockTree(pageID, notion.NewBlockNode(withID("table", cur).Block, curRows...)),
+		notion.NewBlockTree(pageID, notion.NewBlockNode(want)),
+		reconcile.Options{})
+	require.Len(t, plan, 1)
+	assert.Equal(t, reconcile.OperationKindUpdate, plan[0].Kind)
+	assert.Equal(t, notion.UUID("table"), plan[0].Block.Id)
+	assert.True(t, plan[0].Block.Table.HasColumnHeader)
+}
+
+type fakeClient struct {
+	blocks map[notion.Id]notion.Blocks
+	calls  []string
+}
+
+func (c *fakeClient) GetAllBlocks(_ context.Context, id notion.Id) (notion.Blocks, error) {
+	return c.blocks[id], nil
+}
+
+func (c *fakeClient) AppendNotionBlocks(
+	_ context.Context, id notion.Id, after *notion.UUID, blocks ...notion.Block,
+) (notion.Blocks, error) {
+	c.calls = append(c.calls, fmt.Sprintf("append %d to %s after %s", len(blocks), id, *after))
+
+	created := make(notion.Blocks, len(blocks))
+	for i, b := range blocks {
+		b.Id = notion.UUID(fmt.Sprintf("new-%d", i))
+		created[i] = b
+	}
+
+	return created, nil
+}
+
+func (c *fakeClient) UpdateNotionBlock(_ context.Context, b notion.Block) (*notion.Block, error) {
+	c.calls = append(c.calls, "update "+string(b.Id))
+	return &b, nil
+}
+
+func (c *fakeClient) DeleteNotionBlock(_ context.Context, id notion.Id) (*notion.Block, error) {
+	c.calls = append(c.calls, "archive "+string(id))
+	return &notion.Block{Id: notion.UUID(id)}, nil
+}
+
+func TestReconciler_Sync(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newClient := func() *fakeClient {
+		p := notion.NewParagraphBlock("Done: 3 tasks")
+		p.Id = "p1"
+
+		return &fakeClient{blocks: map[notion.Id]notion.Blocks{pageID: {p}}}
+	}
+
+	desired := []*notion.BlockNode{
+		notion.NewBlockNode(notion.NewParagraphBlock("Done: 4 tasks")),
+		notion.NewBlockNode(notion.NewDivider()),
+	}
+
+	out := &bytes.Buffer{}
+	cli := newClient()
+
+	plan, err := reconcile.New(cli, reconcile.Options{DryRun: true, Output: out}).
+		Sync(ctx, pageID, desired...)
+	require.NoError(t, err)
+
+	assert.Empty(t, cli.calls)
+	assert.Equal(t, plan.String()+"\n", out.String())
+	assert.Equal(t, 1, plan.Count(reconcile.OperationKindUpdate))
+	assert.Equal(t, 1, plan.Count(reconcile.OperationKindInsert))
+
+	cli = newClient()
+
+	_, err = reconcile.New(cli, reconcile.Options{}).Sync(ctx, pageID, desired...)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"update p1", "append 1 to " + pageID + " after p1"}, cli.calls)
+}
@@ -0,0 +1,94 @@
+package relations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// Edge is a relation from one page to another.
+type Edge struct {
+	From     notion.UUID
+	To       notion.UUID
+	Property string
+}
+
+// Graph contains pages and the relations between them.
+type Graph struct {
+	Pages map[notion.UUID]notion.Page
+	Edges []Edge
+}
+
+// Graph fetches all entries of the databases and returns the relations among them.
+// Relations to pages outside of the databases are left out.
+// Synced relations result in an edge in each direction.
+func (r *Resolver) Graph(ctx context.Context, ids ...notion.Id) (*Graph, error) {
+	g := &Graph{Pages: map[notion.UUID]notion.Page{}, Edges: []Edge{}}
+	all := notion.Pages{}
+
+	for _, id := range ids {
+		entries, err := r.cli.GetDatabaseEntries(ctx, id, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("getting entries of database %s: %w", id, err)
+		}
+
+		r.Add(entries...)
+
+		for _, p := range entries {
+			g.Pages[p.Id] = p
+		}
+
+		all = append(all, entries...)
+	}
+
+	for _, p := range all {
+		for _, name := range sortedRelations(p.Properties) {
+			for _, ref := range p.Properties[name].GetRelation() {
+				if _, ok := g.Pages[ref.Id]; ok {
+					g.Edges = append(g.Edges, Edge{From: p.Id, To: ref.Id, Property: name})
+				}
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// sortedRelations returns the names of all relation properties, sorted.
+func sortedRelations(props notion.PropertyValueMap) []string {
+	names := []string{}
+
+	for name, v := range props {
+		if v.Type == notion.PropertyTypeRelation {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Outgoing returns all relations from the page.
+func (g *Graph) Outgoing(id notion.UUID) []Edge {
+	return g.filter(func(e Edge) bool { return e.From == id })
+}
+
+// Incoming returns all relations to the page.
+func (g *Graph) Incoming(id notion.UUID) []Edge {
+	return g.filter(func(e Edge) bool { return e.To == id })
+}
+
+func (g *Graph) filter(keep func(Edge) bool) []Edge {
+	edges := []Edge{}
+
+	for _, e := range g.Edges {
+		if keep(e) {
+			edges = append(edges, e)
+		}
+	}
+
+	return edges
+}
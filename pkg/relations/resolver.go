@@ -0,0 +1,238 @@
+// Package relations resolves relations between pages, also across databases.
+package relations
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// maxFilters is the maximum number of filters in a compound filter.
+const maxFilters = 100
+
+var _ Client = (*notion.Client)(nil)
+
+// Client is any client that can read pages and databases.
+type Client interface {
+	// GetNotionPage returns the page.
+	GetNotionPage(ctx context.Context, id notion.Id) (*notion.Page, error)
+	// GetNotionDatabase returns the database.
+	GetNotionDatabase(ctx context.Context, id notion.Id) (*notion.Database, error)
+	// GetDatabaseEntries returns the filtered and sorted entries of a database.
+	GetDatabaseEntries(ctx context.Context, id notion.Id, filter *notion.Filter, sorts *notion.Sorts) (
+		notion.Pages, error)
+}
+
+// Resolver fetches related pages and caches them.
+// It is safe for concurrent use.
+type Resolver struct {
+	cli Client
+
+	mu    sync.Mutex
+	pages map[notion.UUID]*notion.Page
+	dbs   map[notion.UUID]*notion.Database
+}
+
+// NewResolver returns a new resolver.
+func NewResolver(cli Client) *Resolver {
+	return &Resolver{
+		cli:   cli,
+		pages: map[notion.UUID]*notion.Page{},
+		dbs:   map[notion.UUID]*notion.Database{},
+	}
+}
+
+// Add adds pages that were already fetched to the cache.
+func (r *Resolver) Add(pages ...notion.Page) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range pages {
+		r.pages[pages[i].Id] = &pages[i]
+	}
+}
+
+func (r *Resolver) cached(id notion.UUID) (*notion.Page, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pages[id]
+
+	return p, ok
+}
+
+// Page returns the page from the cache or fetches it.
+func (r *Resolver) Page(ctx context.Context, id notion.UUID) (*notion.Page, error) {
+	if p, ok := r.cached(id); ok {
+		return p, nil
+	}
+
+	p, err := r.cli.GetNotionPage(ctx, notion.Id(id))
+	if err != nil {
+		return nil, fmt.Errorf("getting page %s: %w", id, err)
+	}
+
+	r.Add(*p)
+
+	return p, nil
+}
+
+// database returns the database from the cache or fetches it.
+func (r *Resolver) database(ctx context.Context, id notion.UUID) (*notion.Database, error) {
+	r.mu.Lock()
+	db, ok := r.dbs[id]
+	r.mu.Unlock()
+
+	if ok {
+		return db, nil
+	}
+
+	db, err := r.cli.GetNotionDatabase(ctx, notion.Id(id))
+	if err != nil {
+		return nil, fmt.Errorf("getting database %s: %w", id, err)
+	}
+
+	r.mu.Lock()
+	r.dbs[id] = db
+	r.mu.Unlock()
+
+	return db, nil
+}
+
+// Resolve follows the path of relation properties, starting at the page,
+// and returns the pages at the end of the path, e.g. the clients of the project of a task
+// for the path "Project", "Client".
+// Each page is returned once, in the order it is first referenced.
+func (r *Resolver) Resolve(ctx context.Context, p notion.Page, path ...string) (notion.Pages, error) {
+	pages := notion.Pages{p}
+
+	for _, name := range path {
+		next, err := r.step(ctx, pages, name)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %q: %w", name, err)
+		}
+
+		pages = next
+	}
+
+	return pages, nil
+}
+
+// step returns all pages related to the pages via the relation property.
+func (r *Resolver) step(ctx context.Context, pages notion.Pages, name string) (notion.Pages, error) {
+	ids := notion.UUIDs{}
+	seen := map[notion.UUID]bool{}
+	incomplete := notion.Pages{}
+
+	for _, p := range pages {
+		prop, ok := p.Properties[name]
+		if !ok {
+			return nil, fmt.Errorf("page %s has no property %q", p.Id, name)
+		}
+
+		if prop.Type != notion.PropertyTypeRelation {
+			return nil, fmt.Errorf("property %q is of type %s, not a relation", name, prop.Type)
+		}
+
+		missing := false
+
+		for _, ref := range prop.GetRelation() {
+			if !seen[ref.Id] {
+				seen[ref.Id] = true
+				ids = append(ids, ref.Id)
+			}
+
+			if _, ok := r.cached(ref.Id); !ok {
+				missing = true
+			}
+		}
+
+		if missing {
+			incomplete = append(incomplete, p)
+		}
+	}
+
+	if err := r.prefetch(ctx, incomplete, name); err != nil {
+		return nil, err
+	}
+
+	related := make(notion.Pages, 0, len(ids))
+
+	for _, id := range ids {
+		p, err := r.Page(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		related = append(related, *p)
+	}
+
+	return related, nil
+}
+
+// prefetch fetches the related pages of database entries in batches.
+// For synced relations, the related database is queried for all entries that point back to the pages.
+// All other related pages are fetched one by one later on.
+func (r *Resolver) prefetch(ctx context.Context, pages notion.Pages, name string) error {
+	byDatabase := map[notion.UUID]notion.UUIDs{}
+	order := notion.UUIDs{}
+
+	for _, p := range pages {
+		if p.Parent == nil || p.Parent.DatabaseId == nil {
+			continue
+		}
+
+		dbID := *p.Parent.DatabaseId
+		if _, ok := byDatabase[dbID]; !ok {
+			order = append(order, dbID)
+		}
+
+		byDatabase[dbID] = append(byDatabase[dbID], p.Id)
+	}
+
+	for _, dbID := range order {
+		db, err := r.database(ctx, dbID)
+		if err != nil {
+			return err
+		}
+
+		conf := db.Properties[name].GetRelation()
+		if conf.DualProperty == nil {
+			continue
+		}
+
+		if err := r.fetchRelated(ctx, conf, byDatabase[dbID]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchRelated queries the related database for all entries whose synced property contains any of the IDs.
+func (r *Resolver) fetchRelated(ctx context.Context, conf notion.RelationConfiguration, ids notion.UUIDs) error {
+	synced := conf.DualProperty.SyncedPropertyName
+
+	for start := 0; start < len(ids); start += maxFilters {
+		filters := notion.Filters{}
+
+		for _, id := range ids[start:min(start+maxFilters, len(ids))] {
+			id := id
+			filters = append(filters, notion.Filter{
+				Property: &synced,
+				Relation: &notion.RelationFilter{Contains: &id},
+			})
+		}
+
+		entries, err := r.cli.GetDatabaseEntries(ctx, notion.Id(conf.DatabaseId), &notion.Filter{Or: &filters}, nil)
+		if err != nil {
+			return fmt.Errorf("querying database %s: %w", conf.DatabaseId, err)
+		}
+
+		r.Add(entries...)
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package relations_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/faetools/go-notion/pkg/relations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tasksDB    notion.UUID = "00000000-0000-4000-8000-000000000001"
+	projectsDB notion.UUID = "00000000-0000-4000-8000-000000000002"
+	clientsDB  notion.UUID = "00000000-0000-4000-8000-000000000003"
+)
+
+// memClient keeps all objects in memory and counts the requests.
+type memClient struct {
+	pages   notion.Pages
+	dbs     map[notion.UUID]*notion.Database
+	gets    int
+	queries int
+}
+
+func (c *memClient) GetNotionPage(_ context.Context, id notion.Id) (*notion.Page, error) {
+	c.gets++
+
+	for _, p := range c.pages {
+		if p.Id == notion.UUID(id) {
+			return &p, nil
+		}
+	}
+
+	return nil, notion.ErrPageNotFound
+}
+
+func (c *memClient) GetNotionDatabase(_ context.Context, id notion.Id) (*notion.Database, error) {
+	return c.dbs[notion.UUID(id)], nil
+}
+
+func (c *memClient) GetDatabaseEntries(
+	_ context.Context, id notion.Id, filter *notion.Filter, _ *notion.Sorts,
+) (notion.Pages, error) {
+	c.queries++
+
+	entries := notion.Pages{}
+
+	for _, p := range c.pages {
+		if *p.Parent.DatabaseId == notion.UUID(id) && matches(p, filter) {
+			entries = append(entries, p)
+		}
+	}
+
+	return entries, nil
+}
+
+// matches supports "or" filters of relations containing a page.
+func matches(p notion.Page, filter *notion.Filter) bool {
+	if filter == nil {
+		return true
+	}
+
+	for _, f := range *filter.Or {
+		for _, ref := range p.Properties[*f.Property].GetRelation() {
+			if ref.Id == *f.Relation.Contains {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func relation(ids ...notion.UUID) notion.PropertyValue {
+	refs := notion.References{}
+	for _, id := range ids {
+		refs = append(refs, notion.Reference{Id: id})
+	}
+
+	return notion.PropertyValue{Type: notion.PropertyTypeRelation, Relation: &refs}
+}
+
+func entry(db, id notion.UUID, title string, props notion.PropertyValueMap) notion.Page {
+	props["Name"] = notion.PropertyValue{Type: notion.PropertyTypeTitle, Title: notion.NewRichTextsP(title)}
+	return notion.Page{Id: id, Parent: &notion.Parent{Type: "database_id", DatabaseId: &db}, Properties: props}
+}
+
+func newMemClient() *memClient {
+	return &memClient{
+		pages: notion.Pages{
+			entry(tasksDB, "t1", "Write docs", notion.PropertyValueMap{"Project": relation("p1")}),
+			entry(tasksDB, "t2", "Ship it", notion.PropertyValueMap{"Project": relation("p1", "p2")}),
+			entry(projectsDB, "p1", "Website", notion.PropertyValueMap{
+				"Tasks": relation("t1", "t2"), "Client": relation("c1"),
+			}),
+			entry(projectsDB, "p2", "App", notion.PropertyValueMap{
+				"Tasks": relation("t2"), "Client": relation("c1", "c2"),
+			}),
+			entry(clientsDB, "c1", "ACME", notion.PropertyValueMap{}),
+			entry(clientsDB, "c2", "Globex", notion.PropertyValueMap{}),
+		},
+		dbs: map[notion.UUID]*notion.Database{
+			tasksDB: {Id: tasksDB, Properties: notion.PropertyMetaMap{
+				"Project": {Type: notion.PropertyTypeRelation, Relation: &notion.RelationConfiguration{
+					DatabaseId:   projectsDB,
+					DualProperty: &notion.DualProperty{SyncedPropertyName: "Tasks"},
+				}},
+			}},
+			projectsDB: {Id: projectsDB, Properties: notion.PropertyMetaMap{
+				"Client": {Type: notion.PropertyTypeRelation, Relation: &notion.RelationConfiguration{
+					DatabaseId: clientsDB,
+				}},
+			}},
+		},
+	}
+}
+
+func titles(pages notion.Pages) []string {
+	ts := []string{}
+	for _, p := range pages {
+		ts = append(ts, p.Title())
+	}
+
+	return ts
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cli := newMemClient()
+	r := relations.NewResolver(cli)
+
+	task := cli.pages[1]
+
+	pages, err := r.Resolve(ctx, task, "Project", "Client")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ACME", "Globex"}, titles(pages))
+
+	// projects are fetched in one query, clients one by one
+	assert.Equal(t, 1, cli.queries)
+	assert.Equal(t, 2, cli.gets)
+
+	// everything is cached now
+	pages, err = r.Resolve(ctx, cli.pages[0], "Project", "Client")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ACME"}, titles(pages))
+	assert.Equal(t, 1, cli.queries)
+	assert.Equal(t, 2, cli.gets)
+
+	_, err = r.Resolve(ctx, task, "Project", "Name")
+	assert.EqualError(t, err, `resolving "Name": property "Name" is of type title, not a relation`)
+
+	_, err = r.Resolve(ctx, task, "Owner")
+	assert.EqualError(t, err, `resolving "Owner": page t2 has no property "Owner"`)
+}
+
+func TestResolver_Graph(t *testing.T) {
+	t.Parallel()
+
+	cli := newMemClient()
+
+	g, err := relations.NewResolver(cli).Graph(context.Background(),
+		notion.Id(tasksDB), notion.Id(projectsDB))
+	require.NoError(t, err)
+
+	assert.Len(t, g.Pages, 4)
+	assert.Equal(t, []relations.Edge{
+		{From: "t2", To: "p1", Property: "Project"},
+		{From: "t2", To: "p2", Property: "Project"},
+	}, g.Outgoing("t2"))
+	assert.Equal(t, []relations.Edge{
+		{From: "t1", To: "p1", Property: "Project"},
+		{From: "t2", To: "p1", Property: "Project"},
+	}, g.Incoming("p1"))
+
+	// clients are not part of the graph
+	assert.Equal(t, []relations.Edge{
+		{From: "p2", To: "t2", Property: "Tasks"},
+	}, g.Outgoing("p2"))
+}
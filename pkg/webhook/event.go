@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// EventType is the type of an event.
+type EventType string
+
+// Defines values for EventType.
+const (
+	EventPageCreated           EventType = "page.created"
+	EventPageContentUpdated    EventType = "page.content_updated"
+	EventPagePropertiesUpdated EventType = "page.properties_updated"
+	EventPageMoved             EventType = "page.moved"
+	EventPageDeleted           EventType = "page.deleted"
+	EventPageUndeleted         EventType = "page.undeleted"
+	EventPageLocked            EventType = "page.locked"
+	EventPageUnlocked          EventType = "page.unlocked"
+
+	EventDatabaseCreated        EventType = "database.created"
+	EventDatabaseContentUpdated EventType = "database.content_updated"
+	EventDatabaseMoved          EventType = "database.moved"
+	EventDatabaseDeleted        EventType = "database.deleted"
+	EventDatabaseUndeleted      EventType = "database.undeleted"
+	EventDatabaseSchemaUpdated  EventType = "database.schema_updated"
+
+	EventCommentCreated EventType = "comment.created"
+	EventCommentUpdated EventType = "comment.updated"
+	EventCommentDeleted EventType = "comment.deleted"
+)
+
+// Object references a page, database, block, comment, user or bot.
+type Object struct {
+	Id   notion.UUID `json:"id"`
+	Type string      `json:"type"`
+}
+
+// Event is a change that was sent to the webhook.
+type Event struct {
+	Id             notion.UUID `json:"id"`
+	Timestamp      time.Time   `json:"timestamp"`
+	WorkspaceId    notion.UUID `json:"workspace_id"`
+	WorkspaceName  string      `json:"workspace_name"`
+	SubscriptionId notion.UUID `json:"subscription_id"`
+	IntegrationId  notion.UUID `json:"integration_id"`
+	Type           EventType   `json:"type"`
+
+	// Authors are the users or bots that made the change.
+	Authors []Object `json:"authors"`
+	// AccessibleBy are the users and bots that can access the entity, only set for public integrations.
+	AccessibleBy []Object `json:"accessible_by,omitempty"`
+	// AttemptNumber counts the deliveries of the event, starting at 1.
+	AttemptNumber int `json:"attempt_number"`
+
+	// Entity is the page, database or comment that changed.
+	Entity Object `json:"entity"`
+	Data   Data   `json:"data"`
+
+	// Page is the page that changed or was commented on, if the handler fetches it.
+	Page *notion.Page `json:"-"`
+	// Database is the database that changed, if the handler fetches it.
+	Database *notion.Database `json:"-"`
+}
+
+// Data contains the details of an event, which depend on its type.
+type Data struct {
+	// Parent is the parent of the entity.
+	Parent *Object `json:"parent,omitempty"`
+	// PageId is the page a comment belongs to.
+	PageId *notion.UUID `json:"page_id,omitempty"`
+	// UpdatedBlocks are the blocks that changed in a page.
+	UpdatedBlocks []Object `json:"updated_blocks,omitempty"`
+	// UpdatedProperties are the IDs of the properties of a page that changed.
+	UpdatedProperties []string `json:"-"`
+	// SchemaChanges are the changes of the properties of a database.
+	SchemaChanges []PropertyChange `json:"-"`
+}
+
+// PropertyChange is a change of a property of a database.
+type PropertyChange struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	// Action is either "created", "updated" or "deleted".
+	Action string `json:"action"`
+}
+
+type data Data
+
+// UnmarshalJSON fulfils json.Unmarshaler.
+// Updated properties are either IDs for pages or changes for databases.
+func (d *Data) UnmarshalJSON(b []byte) error {
+	tmp := struct {
+		data
+		UpdatedProperties json.RawMessage `json:"updated_properties"`
+	}{}
+
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+
+	*d = Data(tmp.data)
+
+	if len(tmp.UpdatedProperties) == 0 {
+		return nil
+	}
+
+	var ids []string
+	if err := json.Unmarshal(tmp.UpdatedProperties, &ids); err == nil {
+		d.UpdatedProperties = ids
+		return nil
+	}
+
+	return json.Unmarshal(tmp.UpdatedProperties, &d.SchemaChanges)
+}
+
+// String returns the type of the event and the entity, e.g. for logging.
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s %s", e.Type, e.Entity.Type, e.Entity.Id)
+}
+
+// IsPageEvent reports whether the event is about a page.
+func (e Event) IsPageEvent() bool { return e.Entity.Type == "page" }
+
+// IsDatabaseEvent reports whether the event is about a database.
+func (e Event) IsDatabaseEvent() bool { return e.Entity.Type == "database" }
+
+// IsCommentEvent reports whether the event is about a comment.
+func (e Event) IsCommentEvent() bool { return e.Entity.Type == "comment" }
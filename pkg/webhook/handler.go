@@ -0,0 +1,175 @@
+// Package webhook receives the events of integration webhooks.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/faetools/go-notion/pkg/notion"
+)
+
+// SignatureHeader is the header with the signature of the request body.
+const SignatureHeader = "X-Notion-Signature"
+
+// defaultMaxBodySize is the default maximum size of a request body.
+const defaultMaxBodySize = 1 << 20
+
+// HandlerFunc handles an event. If it returns an error, the webhook responds with
+// an internal server error and the event is delivered again later.
+type HandlerFunc func(ctx context.Context, e Event) error
+
+// Options define how the webhook handles requests.
+type Options struct {
+	// VerificationToken is the token sent by the verification request when the subscription was created.
+	// It is used to verify the signatures of events. If empty, events are rejected.
+	VerificationToken string
+
+	// OnVerification is called with the token of a verification request.
+	// The token needs to be entered in the settings of the integration and set as VerificationToken.
+	// Once VerificationToken is set, verification requests are rejected like unsigned events.
+	OnVerification func(token string)
+
+	// Getter fetches the affected page or database before the handlers are called.
+	Getter notion.Getter
+
+	// MaxBodySize is the maximum size of a request body in bytes. Defaults to 1 MiB.
+	MaxBodySize int64
+}
+
+// Handler is an http.Handler that receives webhook events and dispatches them to the registered handlers.
+type Handler struct {
+	opts     Options
+	handlers map[EventType][]HandlerFunc
+	any      []HandlerFunc
+}
+
+// NewHandler returns a new handler.
+func NewHandler(opts Options) *Handler {
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = defaultMaxBodySize
+	}
+
+	return &Handler{opts: opts, handlers: map[EventType][]HandlerFunc{}}
+}
+
+// On registers a handler for events of the type.
+func (h *Handler) On(tp EventType, fn HandlerFunc) {
+	h.handlers[tp] = append(h.handlers[tp], fn)
+}
+
+// OnAny registers a handler for all events.
+func (h *Handler) OnAny(fn HandlerFunc) {
+	h.any = append(h.any, fn)
+}
+
+// Sign returns the signature of the body as sent in the signature header.
+func Sign(body []byte, token string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature of the body is valid.
+func Verify(body []byte, signature, token string) bool {
+	return token != "" && hmac.Equal([]byte(signature), []byte(Sign(body, token)))
+}
+
+// ServeHTTP fulfils http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.opts.MaxBodySize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// only accept verification requests before the subscription is verified,
+	// otherwise anyone could send them
+	if token, ok := verificationToken(body); ok && h.opts.VerificationToken == "" {
+		if h.opts.OnVerification != nil {
+			h.opts.OnVerification(token)
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	if !Verify(body, r.Header.Get(SignatureHeader), h.opts.VerificationToken) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		http.Error(w, fmt.Sprintf("decoding event: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Handle(r.Context(), e); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// verificationToken returns the token of a verification request.
+func verificationToken(body []byte) (string, bool) {
+	var req struct {
+		VerificationToken string `json:"verification_token"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil || req.VerificationToken == "" {
+		return "", false
+	}
+
+	return req.VerificationToken, true
+}
+
+// Handle fetches the affected page or database if configured
+// and calls all handlers registered for the event.
+func (h *Handler) Handle(ctx context.Context, e Event) error {
+	if h.opts.Getter != nil {
+		if err := h.fetch(ctx, &e); err != nil {
+			return fmt.Errorf("fetching %s %s: %w", e.Entity.Type, e.Entity.Id, err)
+		}
+	}
+
+	handlers := append(append([]HandlerFunc{}, h.handlers[e.Type]...), h.any...)
+
+	for _, fn := range handlers {
+		if err := fn(ctx, e); err != nil {
+			return fmt.Errorf("handling %s event %s: %w", e.Type, e.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// fetch sets the page or database the event is about.
+func (h *Handler) fetch(ctx context.Context, e *Event) (err error) {
+	switch {
+	case e.IsPageEvent():
+		e.Page, err = h.opts.Getter.GetNotionPage(ctx, notion.Id(e.Entity.Id))
+	case e.IsDatabaseEvent():
+		e.Database, err = h.opts.Getter.GetNotionDatabase(ctx, notion.Id(e.Entity.Id))
+	case e.IsCommentEvent() && e.Data.PageId != nil:
+		e.Page, err = h.opts.Getter.GetNotionPage(ctx, notion.Id(*e.Data.PageId))
+	}
+
+	return err
+}
@@ -0,0 +1,154 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/faetools/go-notion-example/fake"
+	"github.com/faetools/go-notion/pkg/notion"
+	"github.com/faetools/go-notion/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const token = "secret_tMrlL1qK5vuQAh1b6cZGhFChZTSYJlce98V0pYn7yBl"
+
+const propertiesUpdated = `{
+  "id": "367cba44-b6f3-4c92-81e7-6a2e9659efd4",
+  "timestamp": "2024-12-05T23:55:34.285Z",
+  "workspace_id": "13950b26-c203-4f3b-b97d-93ec06319565",
+  "workspace_name": "Quantify Labs",
+  "subscription_id": "29d75c0d-5546-4414-8459-7b7a92f1fc4b",
+  "integration_id": "0ef2e755-4912-8096-91c1-00376a88a5ca",
+  "type": "page.properties_updated",
+  "authors": [{"id": "c7c11cca-1d73-471d-9b6e-bdef51470190", "type": "person"}],
+  "attempt_number": 1,
+  "entity": {"id": "20c5eed2-59ea-476e-af69-da0ae68a084d", "type": "page"},
+  "data": {
+    "parent": {"id": "7a3c647e-4c1e-4c27-bf1d-cfb0105e55ce", "type": "database"},
+    "updated_properties": ["XGe%40", "bDf%5B"]
+  }
+}`
+
+const schemaUpdated = `{
+  "id": "4a7b8f3e-0000-4000-8000-000000000001",
+  "type": "database.schema_updated",
+  "entity": {"id": "7a3c647e-4c1e-4c27-bf1d-cfb0105e55ce", "type": "database"},
+  "data": {
+    "parent": {"id": "13950b26-c203-4f3b-b97d-93ec06319565", "type": "space"},
+    "updated_properties": [{"id": "KNxj", "name": "Estimate", "action": "created"}]
+  }
+}`
+
+func post(h http.Handler, body, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/notion", strings.NewReader(body))
+	if signature != "" {
+		req.Header.Set(webhook.SignatureHeader, signature)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	var verified string
+
+	h := webhook.NewHandler(webhook.Options{
+		VerificationToken: token,
+		OnVerification:    func(tok string) { verified = tok },
+		Getter:            fake.NotionClient,
+	})
+
+	events := []webhook.Event{}
+
+	h.On(webhook.EventPagePropertiesUpdated, func(_ context.Context, e webhook.Event) error {
+		events = append(events, e)
+		return nil
+	})
+
+	all := 0
+
+	h.OnAny(func(context.Context, webhook.Event) error {
+		all++
+		return nil
+	})
+
+	// verification handshake before the token is configured
+	rec := post(webhook.NewHandler(webhook.Options{
+		OnVerification: func(tok string) { verified = tok },
+	}), `{"verification_token": "`+token+`"}`, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, token, verified)
+
+	// and rejected afterwards
+	verified = ""
+	rec = post(h, `{"verification_token": "forged"}`, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, verified)
+
+	rec = post(h, propertiesUpdated, webhook.Sign([]byte(propertiesUpdated), token))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Len(t, events, 1)
+
+	e := events[0]
+	assert.Equal(t, "page.properties_updated page 20c5eed2-59ea-476e-af69-da0ae68a084d", e.String())
+	assert.Equal(t, []string{"XGe%40", "bDf%5B"}, e.Data.UpdatedProperties)
+	assert.Equal(t, notion.UUID("7a3c647e-4c1e-4c27-bf1d-cfb0105e55ce"), e.Data.Parent.Id)
+	assert.Equal(t, []webhook.Object{{Id: "c7c11cca-1d73-471d-9b6e-bdef51470190", Type: "person"}}, e.Authors)
+	require.NotNil(t, e.Page)
+	assert.Equal(t, "entry 1", e.Page.Title())
+
+	rec = post(h, schemaUpdated, webhook.Sign([]byte(schemaUpdated), token))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 2, all)
+	assert.Len(t, events, 1)
+
+	// invalid requests
+	rec = post(h, propertiesUpdated, webhook.Sign([]byte(propertiesUpdated), "other token"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = post(h, propertiesUpdated, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = post(h, "{", webhook.Sign([]byte("{"), token))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/notion", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestHandler_Handle(t *testing.T) {
+	t.Parallel()
+
+	var e webhook.Event
+	require.NoError(t, json.Unmarshal([]byte(schemaUpdated), &e))
+	assert.Equal(t, []webhook.PropertyChange{{Id: "KNxj", Name: "Estimate", Action: "created"}},
+		e.Data.SchemaChanges)
+	assert.Empty(t, e.Data.UpdatedProperties)
+
+	h := webhook.NewHandler(webhook.Options{Getter: fake.NotionClient})
+	h.On(webhook.EventDatabaseSchemaUpdated, func(_ context.Context, e webhook.Event) error {
+		assert.Equal(t, "My Child Database", e.Database.Title.Content())
+		return errors.New("oops")
+	})
+
+	err := h.Handle(context.Background(), e)
+	assert.EqualError(t, err, "handling database.schema_updated event 4a7b8f3e-0000-4000-8000-000000000001: oops")
+
+	// failing handlers make notion deliver the event again
+	h = webhook.NewHandler(webhook.Options{VerificationToken: token})
+	h.OnAny(func(context.Context, webhook.Event) error { return errors.New("oops") })
+
+	rec := post(h, schemaUpdated, webhook.Sign([]byte(schemaUpdated), token))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}